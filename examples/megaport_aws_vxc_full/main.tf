@@ -46,4 +46,8 @@ resource "megaport_aws_vxc" "foo" {
     customer_ip_address = "{{ .customer_ip_address }}"
     type                = "{{ .type }}"
   }
+
+  timeouts {
+    create = "45m"
+  }
 }
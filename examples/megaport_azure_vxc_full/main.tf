@@ -0,0 +1,43 @@
+data "megaport_partner_port" "azure" {
+  name_regex = "{{ .nameRegex }}"
+
+  azure {
+    service_key = "{{ .serviceKey }}"
+    type        = "{{ .portType }}"
+  }
+}
+
+data "megaport_location" "foo" {
+  name_regex = "Telehouse North$"
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+resource "megaport_azure_vxc" "foo" {
+  name              = "terraform_acctest_{{ .uid }}"
+  rate_limit        = {{ .rateLimit }}
+  invoice_reference = "{{ .uid }}"
+
+  a_end {
+    product_uid = megaport_port.foo.id
+    vlan        = {{ .vlan }}
+  }
+
+  b_end {
+    product_uid = data.megaport_partner_port.azure.id
+    service_key = "{{ .serviceKey }}"
+
+    private_peering {
+      peer_asn         = {{ .peerAsn }}
+      primary_subnet   = "{{ .primarySubnet }}"
+      secondary_subnet = "{{ .secondarySubnet }}"
+      shared_key       = "{{ .sharedKey }}"
+      vlan             = {{ .peeringVlan }}
+    }
+  }
+}
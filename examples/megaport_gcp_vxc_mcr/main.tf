@@ -0,0 +1,52 @@
+data "megaport_partner_port" "gcp" {
+  name_regex = "{{ .nameRegex }}"
+
+  gcp {
+    pairing_key = "{{ .pairingKey }}"
+  }
+}
+
+data "megaport_location" "foo" {
+  name_regex = "Global Switch London East"
+}
+
+resource "megaport_mcr" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  rate_limit  = 1000
+}
+
+resource "megaport_gcp_vxc" "foo" {
+  name       = "terraform_acctest_{{ .uid }}"
+  rate_limit = data.megaport_partner_port.gcp.bandwidths[0]
+
+  a_end {
+    product_uid = megaport_mcr.foo.id
+{{- if .ipAddress }}
+
+    mcr_config {
+      ip_addresses = ["{{ .ipAddress }}/30"]
+
+      bfd {
+        tx_interval = {{ .bfdInterval }}
+        rx_interval = {{ .bfdInterval }}
+        multiplier  = 3
+      }
+
+      bgp_connection {
+        peer_asn         = {{ .peerAsn }}
+        local_ip_address = "{{ .ipAddress }}"
+        peer_ip_address  = "{{ .peerIpAddress }}"
+        password         = "{{ .password }}"
+        bfd_enabled      = true
+        med_in           = {{ .medIn }}
+      }
+    }
+{{- end }}
+  }
+
+  b_end {
+    product_uid = data.megaport_partner_port.gcp.id
+    pairing_key = "{{ .pairingKey }}"
+  }
+}
@@ -0,0 +1,26 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+data "megaport_internet_exchange" "foo" {
+  location_id = data.megaport_location.foo.id
+  name_regex  = "{{ .internetExchange }}"
+}
+
+resource "megaport_ix" "foo" {
+  name              = "terraform_acctest_{{ .uid }}"
+  port_id           = megaport_port.foo.id
+  internet_exchange = data.megaport_internet_exchange.foo.name
+  vlan              = {{ .vlan }}
+  asn               = {{ .asn }}
+  mac_address       = "{{ .macAddress }}"
+  rate_limit        = 1000
+  invoice_reference = "{{ .uid }}"
+}
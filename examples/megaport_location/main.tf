@@ -0,0 +1,6 @@
+data "megaport_location" "foo" {
+  name_regex    = "{{ .location }}"
+  metro         = "London"
+  mcr_available = {{ .mcrAvailable }}
+  port_speed    = 10000
+}
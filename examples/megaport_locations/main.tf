@@ -0,0 +1,7 @@
+data "megaport_locations" "foo" {
+  metro = "{{ .metro }}"
+}
+
+data "megaport_locations" "no_mcr" {
+  mcr_available = false
+}
@@ -0,0 +1,27 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_mcr" "foo" {
+  name              = "terraform_acctest_foo_{{ .uid }}"
+  location_id       = data.megaport_location.foo.id
+  rate_limit        = 1000
+  asn               = {{ .asn }}
+  invoice_reference = "{{ .uid }}"
+}
+
+resource "megaport_mcr" "bar" {
+  name        = "terraform_acctest_bar_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  rate_limit  = 2500
+}
+
+data "megaport_mcr" "foo" {
+  name_regex  = "{{ .nameRegex }}"
+  location_id = data.megaport_location.foo.id
+  {{- if .asnFilter }}
+  asn         = {{ .asnFilter }}
+  {{- end }}
+
+  depends_on = [megaport_mcr.foo, megaport_mcr.bar]
+}
@@ -0,0 +1,21 @@
+data "megaport_location" "foo" {
+  name_regex = "Global Switch London East"
+}
+
+resource "megaport_mcr" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  rate_limit  = 1000
+}
+
+resource "megaport_mcr_prefix_filter_list" "foo" {
+  mcr_id         = megaport_mcr.foo.id
+  name           = "terraform_acctest_{{ .uid }}"
+  address_family = "{{ .addressFamily }}"
+
+  entry {
+    action = "permit"
+    prefix = "{{ .prefix }}"
+    le     = {{ .le }}
+  }
+}
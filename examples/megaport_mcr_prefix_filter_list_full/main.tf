@@ -0,0 +1,61 @@
+data "megaport_partner_port" "gcp" {
+  name_regex = "London"
+
+  gcp {
+    pairing_key = "{{ .pairingKey }}"
+  }
+}
+
+data "megaport_location" "foo" {
+  name_regex = "Global Switch London East"
+}
+
+resource "megaport_mcr" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  rate_limit  = 1000
+}
+
+resource "megaport_mcr_prefix_filter_list" "foo" {
+  mcr_id         = megaport_mcr.foo.id
+  name           = "terraform_acctest_{{ .uid }}_updated"
+  address_family = "{{ .addressFamily }}"
+
+  entry {
+    action = "permit"
+    prefix = "{{ .prefix }}"
+    ge     = {{ .ge }}
+    le     = {{ .le }}
+  }
+
+  entry {
+    action = "deny"
+    prefix = "0.0.0.0/0"
+    le     = 32
+  }
+}
+
+resource "megaport_gcp_vxc" "foo" {
+  name       = "terraform_acctest_{{ .uid }}"
+  rate_limit = data.megaport_partner_port.gcp.bandwidths[0]
+
+  a_end {
+    product_uid = megaport_mcr.foo.id
+
+    mcr_config {
+      ip_addresses = ["10.0.0.1/30"]
+
+      bgp_connection {
+        peer_asn         = {{ .peerAsn }}
+        local_ip_address = "10.0.0.1"
+        peer_ip_address  = "10.0.0.2"
+        import_whitelist = megaport_mcr_prefix_filter_list.foo.id
+      }
+    }
+  }
+
+  b_end {
+    product_uid = data.megaport_partner_port.gcp.id
+    pairing_key = "{{ .pairingKey }}"
+  }
+}
@@ -0,0 +1,19 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_mve" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  term        = 1
+  image_id    = {{ .imageId }}
+  size        = "SMALL"
+{{- if .invoiceReference }}
+  invoice_reference = "{{ .invoiceReference }}"
+{{- end }}
+
+  cisco {
+    admin_ssh_public_key = "{{ .sshPublicKey }}"
+    ssh_public_key       = "{{ .sshPublicKey }}"
+  }
+}
@@ -0,0 +1,47 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_mve" "foo" {
+  name              = "terraform_acctest_{{ .uid }}"
+  location_id       = data.megaport_location.foo.id
+  term              = 12
+  image_id          = {{ .imageId }}
+  size              = "MEDIUM"
+  invoice_reference = "{{ .uid }}"
+
+  vnic {
+    description = "inside"
+  }
+
+  vnic {
+    description = "outside"
+  }
+
+  cisco {
+    admin_ssh_public_key = "{{ .sshPublicKey }}"
+    ssh_public_key       = "{{ .sshPublicKey }}"
+    manage_locally       = true
+  }
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+resource "megaport_private_vxc" "foo" {
+  name       = "terraform_acctest_{{ .uid }}"
+  rate_limit = 100
+
+  a_end {
+    product_uid = megaport_mve.foo.id
+    vnic_index  = 1
+  }
+
+  b_end {
+    product_uid = megaport_port.foo.id
+  }
+}
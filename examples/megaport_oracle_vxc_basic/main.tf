@@ -0,0 +1,34 @@
+data "megaport_partner_port" "oracle" {
+  name_regex = "{{ .nameRegex }}"
+
+  oracle {
+    virtual_circuit_id = "{{ .virtualCircuitId }}"
+    type               = "{{ .portType }}"
+  }
+}
+
+data "megaport_location" "foo" {
+  name_regex = "Telehouse North$"
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 10000
+  term        = 1
+}
+
+resource "megaport_oracle_vxc" "foo" {
+  name       = "terraform_acctest_{{ .uid }}"
+  rate_limit = {{ .rateLimit }}
+
+  a_end {
+    product_uid = megaport_port.foo.id
+    vlan        = {{ .vlan }}
+  }
+
+  b_end {
+    product_uid        = data.megaport_partner_port.oracle.id
+    virtual_circuit_id = "{{ .virtualCircuitId }}"
+  }
+}
@@ -0,0 +1,4 @@
+data "megaport_partner_ports" "aws" {
+  connect_type = "AWS"
+  metro        = "{{ .metro }}"
+}
@@ -0,0 +1,23 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+{{- if .cancellationMode }}
+
+resource "megaport_port" "foo" {
+  name              = "terraform_acctest_{{ .uid }}"
+  location_id       = data.megaport_location.foo.id
+  speed             = 1000
+  term              = 12
+  cancellation_mode = "{{ .cancellationMode }}"
+  {{- if .restore }}
+
+  restore_product_uid = var.restore_product_uid
+  {{- end }}
+}
+{{- if .restore }}
+
+variable "restore_product_uid" {
+  type = string
+}
+{{- end }}
+{{- end }}
@@ -0,0 +1,33 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+{{- if .foo }}
+
+resource "megaport_port" "foo" {
+  name              = "terraform_acctest_a_{{ .uid }}"
+  location_id       = data.megaport_location.foo.id
+  speed             = 1000
+  term              = 12
+  cancellation_mode = "end_of_term"
+}
+
+resource "megaport_private_vxc" "foobar" {
+  name       = "terraform_acctest_{{ .uid }}"
+  rate_limit = 100
+
+  a_end {
+    product_uid = megaport_port.foo.id
+  }
+
+  b_end {
+    product_uid = megaport_port.bar.id
+  }
+}
+{{- end }}
+
+resource "megaport_port" "bar" {
+  name        = "terraform_acctest_b_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
@@ -0,0 +1,11 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_port" "foo" {
+  name           = "terraform_acctest_{{ .uid }}"
+  location_id    = data.megaport_location.foo.id
+  speed          = 10000
+  term           = 1
+  lag_port_count = {{ .lagPortCount }}
+}
@@ -0,0 +1,23 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+data "megaport_ports" "foo" {
+  name_regex = "^terraform_acctest_{{ .uid }}$"
+
+  depends_on = [megaport_port.foo]
+}
+
+data "megaport_ports" "speed" {
+  name_regex = "^terraform_acctest_{{ .uid }}$"
+  speed      = 10000
+
+  depends_on = [megaport_port.foo]
+}
@@ -0,0 +1,60 @@
+data "megaport_location" "foo" {
+  name_regex = "{{ .location }}"
+}
+
+resource "megaport_port" "foo" {
+  name        = "terraform_acctest_a_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+resource "megaport_port" "bar" {
+  name        = "terraform_acctest_b_{{ .uid }}"
+  location_id = data.megaport_location.foo.id
+  speed       = 1000
+  term        = 1
+}
+
+resource "megaport_private_vxc" "foo" {
+  name              = "terraform_acctest_foo_{{ .uid }}"
+  rate_limit        = 100
+  invoice_reference = "{{ .uid }}"
+
+  a_end {
+    product_uid = megaport_port.foo.id
+  }
+
+  b_end {
+    product_uid = megaport_port.bar.id
+  }
+}
+
+resource "megaport_private_vxc" "bar" {
+  name       = "terraform_acctest_bar_{{ .uid }}"
+  rate_limit = 200
+
+  a_end {
+    product_uid = megaport_port.foo.id
+  }
+
+  b_end {
+    product_uid = megaport_port.bar.id
+  }
+}
+
+data "megaport_vxc" "id" {
+  product_uid = megaport_private_vxc.foo.id
+}
+
+data "megaport_vxc" "name" {
+  name_regex = "{{ .nameRegex }}"
+
+  depends_on = [megaport_private_vxc.foo, megaport_private_vxc.bar]
+}
+{{- if .productUid }}
+
+data "megaport_vxc" "missing" {
+  product_uid = "{{ .productUid }}"
+}
+{{- end }}
@@ -7,7 +7,9 @@ require (
 	github.com/fatih/color v1.9.0 // indirect
 	github.com/google/go-cmp v0.5.0
 	github.com/google/uuid v1.1.1
+	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
 	github.com/hashicorp/go-hclog v0.12.0 // indirect
+	github.com/hashicorp/go-multierror v1.0.0
 	github.com/hashicorp/go-uuid v1.0.2
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.0.4
 	github.com/hashicorp/yamux v0.0.0-20190923154419-df201c70410d // indirect
@@ -15,5 +17,6 @@ require (
 	github.com/oklog/run v1.1.0 // indirect
 	github.com/pquerna/otp v1.2.0
 	github.com/vmihailenco/msgpack v4.0.4+incompatible // indirect
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 	gopkg.in/yaml.v2 v2.2.7 // indirect
 )
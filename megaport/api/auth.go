@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	TokenEndpointProduction = "https://auth-m2m.megaport.com/oauth2/token"
+	TokenEndpointStaging    = "https://auth-m2m-staging.megaport.com/oauth2/token"
+
+	// tokenRefreshWindow is how long before their expiry tokens are renewed,
+	// so that they do not expire while a request is in flight
+	tokenRefreshWindow = 1 * time.Minute
+
+	// totpPeriod is how long a one-time password is valid for, in seconds
+	totpPeriod = 30
+)
+
+// timeNow is replaced in tests
+var timeNow = time.Now
+
+// Authenticator adds credentials to the requests made by a Client.
+type Authenticator interface {
+	// Authenticate adds credentials to an outgoing request, obtaining them
+	// first if necessary.
+	Authenticate(ctx context.Context, c *Client, req *http.Request) error
+	// Invalidate is called when a request is rejected with 401 Unauthorized
+	// and discards the credentials it was sent with. It reports whether new
+	// credentials can be obtained, in which case the request is sent again.
+	Invalidate(req *http.Request) bool
+}
+
+// StaticTokenAuthenticator authenticates requests with a pre-issued token.
+type StaticTokenAuthenticator struct {
+	Token string
+}
+
+func (a *StaticTokenAuthenticator) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	req.Header.Set("X-Auth-Token", a.Token)
+	return nil
+}
+
+func (a *StaticTokenAuthenticator) Invalidate(req *http.Request) bool {
+	return false
+}
+
+// LoginAuthenticator logs in with the credentials of a Megaport user and
+// authenticates requests with the resulting token. It logs in again when the
+// token is rejected.
+type LoginAuthenticator struct {
+	Username   string
+	Password   string
+	TOTPSecret string
+
+	cache tokenCache
+	// otpStep is the time step of the last one-time password sent, which
+	// Megaport does not accept twice
+	otpStep int64
+}
+
+// Token returns the current token, logging in first if necessary.
+func (a *LoginAuthenticator) Token(ctx context.Context, c *Client) (string, error) {
+	return a.cache.get(func() (string, time.Time, error) {
+		otp := ""
+		if a.TOTPSecret != "" {
+			v, err := a.nextOTP(ctx)
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			otp = v
+		}
+		token, err := c.login(ctx, a.Username, a.Password, otp)
+		return token, time.Time{}, err
+	})
+}
+
+// nextOTP generates a one-time password that has not been sent yet, waiting
+// for the next time step if the current one has already been used.
+func (a *LoginAuthenticator) nextOTP(ctx context.Context) (string, error) {
+	step := timeNow().Unix() / totpPeriod
+	if step <= a.otpStep {
+		step = a.otpStep + 1
+		wait := time.Unix(step*totpPeriod, 0).Sub(timeNow())
+		log.Printf("[INFO] Waiting %s for a new one-time password before logging in again", wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("could not log in again, as the one-time password has already been used: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+	otp, err := totp.GenerateCode(a.TOTPSecret, time.Unix(step*totpPeriod, 0))
+	if err != nil {
+		return "", fmt.Errorf("could not generate a one-time password: %w", err)
+	}
+	a.otpStep = step
+	return otp, nil
+}
+
+func (a *LoginAuthenticator) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	token, err := a.Token(ctx, c)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Auth-Token", token)
+	return nil
+}
+
+func (a *LoginAuthenticator) Invalidate(req *http.Request) bool {
+	a.cache.invalidate(req.Header.Get("X-Auth-Token"))
+	return true
+}
+
+// ClientCredentialsAuthenticator exchanges the id and secret of an API key
+// for short-lived bearer tokens, using the OAuth2 client credentials grant.
+// Tokens are renewed shortly before they expire.
+type ClientCredentialsAuthenticator struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+
+	cache tokenCache
+}
+
+// Token returns the current access token, requesting a new one first if
+// necessary.
+func (a *ClientCredentialsAuthenticator) Token(ctx context.Context, c *Client) (string, error) {
+	return a.cache.get(func() (string, time.Time, error) {
+		return a.exchange(ctx, c)
+	})
+}
+
+func (a *ClientCredentialsAuthenticator) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	token, err := a.Token(ctx, c)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *ClientCredentialsAuthenticator) Invalidate(req *http.Request) bool {
+	a.cache.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	return true
+}
+
+func (a *ClientCredentialsAuthenticator) exchange(ctx context.Context, c *Client) (string, time.Time, error) {
+	v := url.Values{}
+	v.Set("grant_type", "client_credentials")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.UserAgent)
+	// Obtaining a token has no side effects, so it can be retried like any
+	// read
+	resp, err := c.doWithRetries(ctx, req, true)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer drainResponseBody(resp)
+	data := struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return "", time.Time{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := data.ErrorDescription
+		if msg == "" {
+			msg = data.Error
+		}
+		return "", time.Time{}, &Error{StatusCode: resp.StatusCode, Message: msg, Method: req.Method, Path: req.URL.Path}
+	}
+	if data.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("megaport-api: no access token received from %s", a.TokenURL)
+	}
+	var expires time.Time
+	if data.ExpiresIn > 0 {
+		expires = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+	return data.AccessToken, expires, nil
+}
+
+// tokenCache holds a token that is shared by concurrent requests, so that it
+// is only obtained once instead of by every request.
+type tokenCache struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time // The zero value means that the token does not expire
+}
+
+func (t *tokenCache) get(fetch func() (string, time.Time, error)) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && (t.expires.IsZero() || time.Until(t.expires) > tokenRefreshWindow) {
+		return t.token, nil
+	}
+	token, expires, err := fetch()
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expires = expires
+	return token, nil
+}
+
+// invalidate discards the cached token, unless it has already been replaced
+// since it was rejected.
+func (t *tokenCache) invalidate(rejected string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token == rejected {
+		t.token = ""
+	}
+}
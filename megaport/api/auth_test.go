@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	token := uuid.New().String()
+	requests := 0
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if ft := r.Header.Get("X-Auth-Token"); ft != token {
+			t.Errorf("TestStaticTokenAuthenticator: unexpected token: got '%s', expected '%s'", ft, token)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"Invalid token"}`)
+	})
+	defer s.Close()
+	c.Authenticator = &StaticTokenAuthenticator{Token: token}
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("TestStaticTokenAuthenticator: %v", err)
+	}
+	if err := c.do(context.Background(), req, nil); err == nil {
+		t.Errorf("TestStaticTokenAuthenticator: expected an error but did not get one")
+	}
+	if requests != 1 {
+		t.Errorf("TestStaticTokenAuthenticator: unexpected number of requests: got %d, expected 1", requests)
+	}
+}
+
+func TestLoginAuthenticator(t *testing.T) {
+	// Log in again within the same time step, so that a new one-time password
+	// must be waited for
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	now := time.Unix(1000*totpPeriod-1, int64(900*time.Millisecond))
+	timeNow = func() time.Time { return now }
+	tokens := []string{uuid.New().String(), uuid.New().String()}
+	otps := []string{}
+	logins := 0
+	valid := ""
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/login" {
+			if ft := r.Header.Get("X-Auth-Token"); ft != "" {
+				t.Errorf("TestLoginAuthenticator: unexpected token sent when logging in: %s", ft)
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("TestLoginAuthenticator: %v", err)
+			}
+			if otp := r.Form.Get("oneTimePassword"); len(otp) != 6 {
+				t.Errorf("TestLoginAuthenticator: unexpected one-time password: %q", otp)
+			}
+			otps = append(otps, r.Form.Get("oneTimePassword"))
+			valid = tokens[logins]
+			logins++
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"data":{"token":"%s"}}`, valid)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("TestLoginAuthenticator: %v", err)
+		}
+		if string(body) != "{}" {
+			t.Errorf("TestLoginAuthenticator: unexpected body: got '%s', expected '{}'", body)
+		}
+		if r.Header.Get("X-Auth-Token") != valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"Invalid token"}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{}`)
+	})
+	defer s.Close()
+	auth := &LoginAuthenticator{Username: "foo", Password: "bar", TOTPSecret: "JBSWY3DPEHPK3PXP"}
+	c.Authenticator = auth
+	send := func() {
+		req, err := http.NewRequest(http.MethodPut, s.URL, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("TestLoginAuthenticator: %v", err)
+		}
+		if err := c.do(context.Background(), req, nil); err != nil {
+			t.Errorf("TestLoginAuthenticator: %v", err)
+		}
+	}
+	send()
+	send()
+	if logins != 1 {
+		t.Errorf("TestLoginAuthenticator: unexpected number of logins: got %d, expected 1", logins)
+	}
+	// Expire the token on the server side, the next request must log in again
+	valid = ""
+	send()
+	if logins != 2 {
+		t.Errorf("TestLoginAuthenticator: unexpected number of logins: got %d, expected 2", logins)
+	}
+	if token, err := auth.Token(context.Background(), c); err != nil || token != tokens[1] {
+		t.Errorf("TestLoginAuthenticator: unexpected token: got '%s' (%v), expected '%s'", token, err, tokens[1])
+	}
+	if len(otps) != 2 || otps[0] == otps[1] {
+		t.Errorf("TestLoginAuthenticator: one-time password sent twice: %v", otps)
+	}
+}
+
+func TestLoginAuthenticator_otpAlreadyUsed(t *testing.T) {
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	timeNow = func() time.Time { return time.Unix(1000*totpPeriod, 0) }
+	auth := &LoginAuthenticator{Username: "foo", Password: "bar", TOTPSecret: "JBSWY3DPEHPK3PXP"}
+	if _, err := auth.nextOTP(context.Background()); err != nil {
+		t.Fatalf("TestLoginAuthenticator_otpAlreadyUsed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := auth.nextOTP(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("TestLoginAuthenticator_otpAlreadyUsed: unexpected error: got %v, expected %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("TestLoginAuthenticator_otpAlreadyUsed: waited for %s after the context was done", d)
+	}
+}
+
+func TestClientCredentialsAuthenticator(t *testing.T) {
+	expiresIn := 3600
+	exchanges := 0
+	valid := ""
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "foo" || secret != "bar" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error":"invalid_client"}`)
+				return
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("TestClientCredentialsAuthenticator: %v", err)
+			}
+			if gt := r.Form.Get("grant_type"); gt != "client_credentials" {
+				t.Errorf("TestClientCredentialsAuthenticator: unexpected grant type: %q", gt)
+			}
+			exchanges++
+			valid = uuid.New().String()
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"access_token":"%s","expires_in":%d,"token_type":"Bearer"}`, valid, expiresIn)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"Invalid token"}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{}`)
+	})
+	defer s.Close()
+	c.Authenticator = &ClientCredentialsAuthenticator{TokenURL: s.URL + "/oauth2/token", ClientID: "foo", ClientSecret: "bar"}
+	send := func() error {
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		if err != nil {
+			t.Fatalf("TestClientCredentialsAuthenticator: %v", err)
+		}
+		return c.do(context.Background(), req, nil)
+	}
+	for i := 0; i < 3; i++ {
+		if err := send(); err != nil {
+			t.Errorf("TestClientCredentialsAuthenticator: %v", err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("TestClientCredentialsAuthenticator: unexpected number of token exchanges: got %d, expected 1", exchanges)
+	}
+	// Revoke the token on the server side, the next request must obtain a new one
+	valid = ""
+	if err := send(); err != nil {
+		t.Errorf("TestClientCredentialsAuthenticator: %v", err)
+	}
+	if exchanges != 2 {
+		t.Errorf("TestClientCredentialsAuthenticator: unexpected number of token exchanges: got %d, expected 2", exchanges)
+	}
+	// Tokens that are about to expire are renewed before they are used
+	expiresIn = 30
+	c.Authenticator = &ClientCredentialsAuthenticator{TokenURL: s.URL + "/oauth2/token", ClientID: "foo", ClientSecret: "bar"}
+	for i := 0; i < 2; i++ {
+		if err := send(); err != nil {
+			t.Errorf("TestClientCredentialsAuthenticator: %v", err)
+		}
+	}
+	if exchanges != 4 {
+		t.Errorf("TestClientCredentialsAuthenticator: unexpected number of token exchanges: got %d, expected 4", exchanges)
+	}
+	c.Authenticator = &ClientCredentialsAuthenticator{TokenURL: s.URL + "/oauth2/token", ClientID: "foo", ClientSecret: "baz"}
+	if err := send(); !strings.Contains(fmt.Sprint(err), "invalid_client") {
+		t.Errorf("TestClientCredentialsAuthenticator: unexpected error: got '%v', expected 'invalid_client'", err)
+	}
+}
+
+func TestClientCredentialsAuthenticator_retriesAndRateLimits(t *testing.T) {
+	exchanges := 0
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			exchanges++
+			if exchanges == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, `{"access_token":"foo","expires_in":3600,"token_type":"Bearer"}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{}`)
+	})
+	defer s.Close()
+	c.RetryMinWait = time.Millisecond
+	c.RetryMaxWait = 10 * time.Millisecond
+	c.RateLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 1)
+	c.Authenticator = &ClientCredentialsAuthenticator{TokenURL: s.URL + "/oauth2/token", ClientID: "foo", ClientSecret: "bar"}
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("TestClientCredentialsAuthenticator_retriesAndRateLimits: %v", err)
+	}
+	start := time.Now()
+	if err := c.do(context.Background(), req, nil); err != nil {
+		t.Errorf("TestClientCredentialsAuthenticator_retriesAndRateLimits: %v", err)
+	}
+	if exchanges != 2 {
+		t.Errorf("TestClientCredentialsAuthenticator_retriesAndRateLimits: expected the failed token exchange to be retried, got %d exchanges", exchanges)
+	}
+	// Two exchanges and the request itself draw from the same limiter
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("TestClientCredentialsAuthenticator_retriesAndRateLimits: token exchanges were not rate limited, took %s", d)
+	}
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -24,21 +29,39 @@ const (
 	ProductStatusDecommissioned  = "DECOMMISSIONED"
 	ProductStatusDeployable      = "DEPLOYABLE"
 	ProductStatusLive            = "LIVE"
-)
 
-var (
-	ErrNotFound = fmt.Errorf("megaport-api: not found")
+	productActionCancel    = "CANCEL"
+	productActionCancelNow = "CANCEL_NOW"
+	productActionUncancel  = "UN_CANCEL"
 )
 
 type Client struct {
-	c         *http.Client
-	BaseURL   string
-	Token     string
-	UserAgent string
+	// HTTPClient sends the requests to the API. It can be replaced, eg to
+	// trust a test server or to record the traffic.
+	HTTPClient   *http.Client
+	BaseURL      string
+	Token        string
+	UserAgent    string
+	MaxRetries   int
+	RetryMinWait time.Duration
+	RetryMaxWait time.Duration
+	// RateLimiter is shared by every request made through the client, so
+	// that concurrent callers draw from a single budget. A nil RateLimiter
+	// does not limit requests at all.
+	RateLimiter *rate.Limiter
+	// Authenticator, when set, adds credentials to every request. Otherwise
+	// Token is sent as is, if it is set.
+	Authenticator Authenticator
 }
 
 func NewClient(baseURL string) *Client {
-	c := &Client{c: &http.Client{}, BaseURL: baseURL}
+	c := &Client{
+		HTTPClient:   &http.Client{},
+		BaseURL:      baseURL,
+		MaxRetries:   DefaultMaxRetries,
+		RetryMinWait: DefaultRetryMinWait,
+		RetryMaxWait: DefaultRetryMaxWait,
+	}
 	return c
 }
 
@@ -48,7 +71,16 @@ func (c *Client) IsResourceDeleted(provisioningStatus string) bool {
 		provisioningStatus == ProductStatusDecommissioned
 }
 
-func (c *Client) Login(username, password, otp string) error {
+func (c *Client) Login(ctx context.Context, username, password, otp string) error {
+	token, err := c.login(ctx, username, password, otp)
+	if err != nil {
+		return err
+	}
+	c.Token = token
+	return nil
+}
+
+func (c *Client) login(ctx context.Context, username, password, otp string) (string, error) {
 	v := url.Values{}
 	v.Set("username", username)
 	v.Set("password", password)
@@ -57,49 +89,48 @@ func (c *Client) Login(username, password, otp string) error {
 	}
 	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/login?%s", c.BaseURL, v.Encode()), nil)
 	if err != nil {
-		return err
+		return "", err
 	}
 	data := responseLoginData{}
-	if err := c.do(req, &data); err != nil {
-		return err
+	if err := c.doUnauthenticated(ctx, req, &data); err != nil {
+		return "", err
 	}
-	c.Token = data.Token
-	return nil
+	return data.Token, nil
 }
 
-func (c *Client) Logout() error {
+func (c *Client) Logout(ctx context.Context) error {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/logout", c.BaseURL), nil)
 	if err != nil {
 		return err
 	}
-	return c.do(req, nil)
+	return c.do(ctx, req, nil)
 }
 
-func (c *Client) GetLocations() ([]*Location, error) {
+func (c *Client) GetLocations(ctx context.Context) ([]*Location, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/locations", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
 	data := []*Location{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (c *Client) GetMegaports() ([]*Megaport, error) {
+func (c *Client) GetMegaports(ctx context.Context) ([]*Megaport, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/dropdowns/partner/megaports", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
 	data := []*Megaport{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (c *Client) GetMegaportsForGcpPairingKey(pairingKey string) ([]*MegaportCloud, []uint64, error) {
+func (c *Client) GetMegaportsForGcpPairingKey(ctx context.Context, pairingKey string) ([]*MegaportCloud, []uint64, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/secure/google/%s", c.BaseURL, pairingKey), nil)
 	if err != nil {
 		return nil, nil, err
@@ -109,13 +140,58 @@ func (c *Client) GetMegaportsForGcpPairingKey(pairingKey string) ([]*MegaportClo
 		Megaports    []*MegaportCloud
 		ResourceType string `json:"resource_type"`
 	}{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, nil, err
 	}
 	return data.Megaports, data.Bandwidths, nil
 }
 
-func (c *Client) GetInternetExchanges(locationId uint64) ([]*InternetExchange, error) {
+// GetMegaportsForOracleVirtualCircuit returns the FastConnect ports that can be
+// used for VXCs to the OCI virtual circuit with the given OCID, along with the
+// bandwidths available to them.
+func (c *Client) GetMegaportsForOracleVirtualCircuit(ctx context.Context, virtualCircuitId string) ([]*MegaportCloud, []uint64, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/secure/oracle/%s", c.BaseURL, virtualCircuitId), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := struct {
+		Bandwidths   []uint64
+		Megaports    []*MegaportCloud
+		ResourceType string `json:"resource_type"`
+	}{}
+	if err := c.do(ctx, req, &data); err != nil {
+		return nil, nil, err
+	}
+	return data.Megaports, data.Bandwidths, nil
+}
+
+// GetMegaportsForAzureServiceKey returns the ExpressRoute ports that can be
+// used for VXCs to the circuit with the given service key, along with the
+// bandwidths available to them.
+func (c *Client) GetMegaportsForAzureServiceKey(ctx context.Context, serviceKey string) ([]*MegaportCloud, []uint64, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/secure/azure/%s", c.BaseURL, serviceKey), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := struct {
+		Bandwidth    uint64
+		Bandwidths   []uint64
+		Megaports    []*MegaportCloud
+		ResourceType string `json:"resource_type"`
+		ServiceKey   string `json:"service_key"`
+		Vlan         uint64
+	}{}
+	if err := c.do(ctx, req, &data); err != nil {
+		return nil, nil, err
+	}
+	// The bandwidth of the circuit is the only option, unless told otherwise
+	if len(data.Bandwidths) == 0 && data.Bandwidth > 0 {
+		data.Bandwidths = []uint64{data.Bandwidth}
+	}
+	return data.Megaports, data.Bandwidths, nil
+}
+
+func (c *Client) GetInternetExchanges(ctx context.Context, locationId uint64) ([]*InternetExchange, error) {
 	v := url.Values{}
 	v.Set("locationId", strconv.FormatUint(locationId, 10))
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/product/ix/types?%s", c.BaseURL, v.Encode()), nil)
@@ -123,13 +199,13 @@ func (c *Client) GetInternetExchanges(locationId uint64) ([]*InternetExchange, e
 		return nil, err
 	}
 	data := []*InternetExchange{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (c *Client) GetMegaportPrice(locationId, speed, term uint64, productUid string, buyoutPort bool) (*MegaportCharges, error) {
+func (c *Client) GetMegaportPrice(ctx context.Context, locationId, speed, term uint64, productUid string, buyoutPort bool) (*MegaportCharges, error) {
 	v := url.Values{}
 	v.Set("locationId", strconv.FormatUint(locationId, 10))
 	v.Set("speed", strconv.FormatUint(speed, 10))
@@ -138,84 +214,148 @@ func (c *Client) GetMegaportPrice(locationId, speed, term uint64, productUid str
 	if productUid != "" {
 		v.Set("productUid", productUid) // TODO: can we just set to empty?
 	}
-	return c.getMegaportCharges("megaport", v)
+	return c.getMegaportCharges(ctx, "megaport", v)
 }
 
-func (c *Client) GetMcr1Price(locationId, speed uint64, productUid string) (*MegaportCharges, error) {
+func (c *Client) GetMcr1Price(ctx context.Context, locationId, speed uint64, productUid string) (*MegaportCharges, error) {
 	v := url.Values{}
 	v.Set("locationId", strconv.FormatUint(locationId, 10))
 	v.Set("speed", strconv.FormatUint(speed, 10))
 	if productUid != "" {
 		v.Set("productUid", productUid) // TODO: can we just set to empty?
 	}
-	return c.getMegaportCharges("mcr", v)
+	return c.getMegaportCharges(ctx, "mcr", v)
 }
 
-func (c *Client) GetMcr2Price(locationId, speed uint64, productUid string) (*MegaportCharges, error) {
+func (c *Client) GetMcr2Price(ctx context.Context, locationId, speed uint64, productUid string) (*MegaportCharges, error) {
 	v := url.Values{}
 	v.Set("locationId", strconv.FormatUint(locationId, 10))
 	v.Set("speed", strconv.FormatUint(speed, 10))
 	if productUid != "" {
 		v.Set("productUid", productUid) // TODO: can we just set to empty?
 	}
-	return c.getMegaportCharges("mcr2", v)
+	return c.getMegaportCharges(ctx, "mcr2", v)
 }
 
-func (c *Client) GetVxcPrice(aLocationId, bLocationId, speed uint64) (*MegaportCharges, error) {
+func (c *Client) GetVxcPrice(ctx context.Context, aLocationId, bLocationId, speed uint64) (*MegaportCharges, error) {
 	v := url.Values{}
 	v.Set("aLocationId", strconv.FormatUint(aLocationId, 10))
 	v.Set("bLocationId", strconv.FormatUint(bLocationId, 10))
 	v.Set("speed", strconv.FormatUint(speed, 10))
-	return c.getMegaportCharges("vxc", v)
+	return c.getMegaportCharges(ctx, "vxc", v)
 }
 
-func (c *Client) GetIxPrice(ixType string, locationId, speed uint64) (*MegaportCharges, error) {
+func (c *Client) GetIxPrice(ctx context.Context, ixType string, locationId, speed uint64) (*MegaportCharges, error) {
 	v := url.Values{}
 	v.Set("ixType", ixType)
 	v.Set("portLocationId", strconv.FormatUint(locationId, 10))
 	v.Set("speed", strconv.FormatUint(speed, 10))
-	return c.getMegaportCharges("ix", v)
+	return c.getMegaportCharges(ctx, "ix", v)
 }
 
-func (c *Client) getMegaportCharges(product string, v url.Values) (*MegaportCharges, error) {
+func (c *Client) getMegaportCharges(ctx context.Context, product string, v url.Values) (*MegaportCharges, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/pricebook/%s?%s", c.BaseURL, product, v.Encode()), nil)
 	if err != nil {
 		return nil, err
 	}
 	data := &MegaportCharges{}
-	if err := c.do(req, data); err != nil {
+	if err := c.do(ctx, req, data); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (c *Client) do(req *http.Request, data interface{}) error {
+func (c *Client) do(ctx context.Context, req *http.Request, data interface{}) error {
+	return c.send(ctx, req, data, true)
+}
+
+func (c *Client) doUnauthenticated(ctx context.Context, req *http.Request, data interface{}) error {
+	return c.send(ctx, req, data, false)
+}
+
+func (c *Client) send(ctx context.Context, req *http.Request, data interface{}, authenticate bool) error {
+	req = req.WithContext(ctx)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.UserAgent)
-	if c.Token != "" {
-		req.Header.Set("X-Auth-Token", c.Token)
+	if authenticate {
+		if err := c.authenticate(ctx, req); err != nil {
+			return err
+		}
 	}
-	resp, err := c.c.Do(req)
+	resp, err := c.doWithRetries(ctx, req, isRetryableRequest(req))
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode == http.StatusNotFound {
-		return ErrNotFound
+	if resp.StatusCode == http.StatusUnauthorized && authenticate && c.Authenticator != nil && c.Authenticator.Invalidate(req) {
+		log.Printf("[DEBUG] Credentials were rejected by the Megaport API, renewing them")
+		drainResponseBody(resp)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			req.Body = body
+		}
+		if err := c.authenticate(ctx, req); err != nil {
+			return err
+		}
+		resp, err = c.doWithRetries(ctx, req, isRetryableRequest(req))
+		if err != nil {
+			return err
+		}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		r := megaportResponse{}
-		if err := parseResponseBody(resp, &r); err != nil {
-			return err
+		return newError(req, resp)
+	}
+	return parseResponseBody(resp, &megaportResponse{Data: data})
+}
+
+func (c *Client) authenticate(ctx context.Context, req *http.Request) error {
+	if c.Authenticator != nil {
+		return c.Authenticator.Authenticate(ctx, c, req)
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Auth-Token", c.Token)
+	}
+	return nil
+}
+
+// doWithRetries sends req through the rate limiter, sending it again after
+// transient failures when it is retryable.
+func (c *Client) doWithRetries(ctx context.Context, req *http.Request, retryable bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
 		}
-		err := responseDataToError(r.Data)
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if !retryable || attempt >= c.MaxRetries || !isRetryableResponse(ctx, resp, err) {
+			return resp, err
+		}
+		wait := c.retryWait(attempt, resp)
 		if err != nil {
-			return fmt.Errorf("megaport-api (%d): %s: %w", resp.StatusCode, r.Message, err)
+			log.Printf("[DEBUG] %s %s failed, retrying in %s: %v", req.Method, req.URL.Path, wait, err)
 		} else {
-			return fmt.Errorf("megaport-api (%d): %s", resp.StatusCode, r.Message)
+			log.Printf("[DEBUG] %s %s returned %d, retrying in %s", req.Method, req.URL.Path, resp.StatusCode, wait)
+		}
+		drainResponseBody(resp)
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
 		}
 	}
-	return parseResponseBody(resp, &megaportResponse{Data: data})
 }
 
 func responseDataToError(d interface{}) error {
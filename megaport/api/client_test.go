@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -8,9 +10,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"golang.org/x/time/rate"
 )
 
 func testClientServer(handler func(w http.ResponseWriter, r *http.Request)) (*Client, *httptest.Server) {
@@ -51,7 +55,7 @@ func TestClient_Login(t *testing.T) {
 		fmt.Fprintf(w, `{"data":{"token":"%s"}}`, token)
 	})
 	defer s.Close()
-	if err := c.Login(username, password, totp); err != nil {
+	if err := c.Login(context.Background(), username, password, totp); err != nil {
 		t.Errorf("TestClient_Login: %v", err)
 	}
 	if c.Token != token {
@@ -78,7 +82,7 @@ func TestClient_Logout(t *testing.T) {
 	})
 	c.Token = token
 	defer s.Close()
-	if err := c.Logout(); err != nil {
+	if err := c.Logout(context.Background()); err != nil {
 		t.Errorf("TestClient_Logout: %v", err)
 	}
 }
@@ -123,12 +127,13 @@ func TestClient_responseDataToError(t *testing.T) {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, testCases[tc].p)
 	})
+	c.MaxRetries = 0
 	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
 	if err != nil {
 		t.Errorf("TestClient_responseDataToError: %v", err)
 	}
-	e := `megaport-api: not found`
-	if err := c.do(req, nil); err.Error() != e {
+	e := `megaport-api (404): not found`
+	if err := c.do(context.Background(), req, nil); err.Error() != e {
 		t.Errorf("TestClient_responseDataToError: unexpected error:\n\tgot     : %v\n\texpected: %s", err, e)
 	}
 	for i, tc := range testCases {
@@ -136,8 +141,60 @@ func TestClient_responseDataToError(t *testing.T) {
 		if err != nil {
 			t.Errorf("TestClient_responseDataToError: %v", err)
 		}
-		if err := c.do(req, nil); err.Error() != tc.e {
+		if err := c.do(context.Background(), req, nil); err.Error() != tc.e {
 			t.Errorf("TestClient_responseDataToError: unexpected error in test case #%d:\n\tgot     : %v\n\texpected: %s", i, err, tc.e)
 		}
 	}
 }
+
+func TestClient_doContextCancelled(t *testing.T) {
+	done := make(chan struct{})
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	})
+	defer s.Close()
+	defer close(done)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("TestClient_doContextCancelled: %v", err)
+	}
+	if err := c.do(ctx, req, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("TestClient_doContextCancelled: unexpected error: got '%v', expected '%v'", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_doRateLimited(t *testing.T) {
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{}`)
+	})
+	defer s.Close()
+	c.RateLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		if err != nil {
+			t.Fatalf("TestClient_doRateLimited: %v", err)
+		}
+		if err := c.do(context.Background(), req, nil); err != nil {
+			t.Errorf("TestClient_doRateLimited: %v", err)
+		}
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("TestClient_doRateLimited: requests were not rate limited, took %s", d)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("TestClient_doRateLimited: %v", err)
+	}
+	if err := c.do(ctx, req, nil); err == nil {
+		t.Errorf("TestClient_doRateLimited: expected an error but did not get one")
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("megaport-api: not found")
+)
+
+// Error is returned by the client for any non-2xx response from the Megaport
+// API. It can be inspected with errors.As, or more conveniently with the
+// IsNotFound, IsValidation and IsConflict helpers.
+type Error struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the top-level message returned by the API
+	Message string
+	// FieldErrors holds the per-field errors returned by the API, most
+	// notably when validating a network design
+	FieldErrors []FieldError
+	// Method and Path identify the request that failed
+	Method string
+	Path   string
+	// Data is the raw error payload returned by the API
+	Data interface{}
+}
+
+// FieldError is a single validation error. Field is empty for errors that do
+// not refer to a specific field of the request.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = strings.ToLower(http.StatusText(e.StatusCode))
+	}
+	if err := responseDataToError(e.Data); err != nil {
+		return fmt.Sprintf("megaport-api (%d): %s: %v", e.StatusCode, msg, err)
+	}
+	return fmt.Sprintf("megaport-api (%d): %s", e.StatusCode, msg)
+}
+
+// Is allows errors.Is(err, ErrNotFound) to keep working for 404 responses.
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+func newError(req *http.Request, resp *http.Response) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+	}
+	r := megaportResponse{}
+	if err := parseResponseBody(resp, &r); err != nil {
+		// The body is not always JSON, eg when a proxy in front of the API
+		// fails, so fall back to the status code alone
+		return e
+	}
+	e.Message = r.Message
+	e.Data = r.Data
+	e.FieldErrors = responseDataToFieldErrors(r.Data)
+	return e
+}
+
+func responseDataToFieldErrors(d interface{}) []FieldError {
+	switch e := d.(type) {
+	case string:
+		return []FieldError{{Message: e}}
+	case map[string]interface{}:
+		field, fok := e["field"].(string)
+		message, mok := e["message"].(string)
+		if fok && mok {
+			return []FieldError{{Field: field, Message: message}}
+		}
+		errs := []FieldError{}
+		for k, v := range e {
+			errs = append(errs, FieldError{Field: k, Message: fmt.Sprint(v)})
+		}
+		return errs
+	case []interface{}:
+		errs := []FieldError{}
+		for _, v := range e {
+			errs = append(errs, responseDataToFieldErrors(v)...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+func asError(err error) (*Error, bool) {
+	e := &Error{}
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err was caused by the API responding with 404.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsValidation reports whether err was caused by the API rejecting the
+// contents of a request.
+func IsValidation(err error) bool {
+	e, ok := asError(err)
+	return ok && (e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// IsConflict reports whether err was caused by the request conflicting with
+// the current state of a resource, eg a VLAN that is already in use.
+func IsConflict(err error) bool {
+	e, ok := asError(err)
+	return ok && e.StatusCode == http.StatusConflict
+}
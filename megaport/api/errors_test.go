@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestError(t *testing.T) {
+	testCases := []struct {
+		status     int
+		body       string
+		err        *Error
+		notFound   bool
+		validation bool
+		conflict   bool
+	}{
+		{ // 0
+			status:   http.StatusNotFound,
+			body:     `{}`,
+			err:      &Error{StatusCode: http.StatusNotFound},
+			notFound: true,
+		},
+		{ // 1
+			status: http.StatusBadRequest,
+			body:   `{"message":"Validation failed","data":[{"field":"productName","message":"must not be empty"},"port is locked"]}`,
+			err: &Error{
+				StatusCode: http.StatusBadRequest,
+				Message:    "Validation failed",
+				FieldErrors: []FieldError{
+					{Field: "productName", Message: "must not be empty"},
+					{Message: "port is locked"},
+				},
+			},
+			validation: true,
+		},
+		{ // 2
+			status: http.StatusConflict,
+			body:   `{"message":"Conflict","data":{"vlan":"already in use"}}`,
+			err: &Error{
+				StatusCode:  http.StatusConflict,
+				Message:     "Conflict",
+				FieldErrors: []FieldError{{Field: "vlan", Message: "already in use"}},
+			},
+			conflict: true,
+		},
+		{ // 3
+			status: http.StatusInternalServerError,
+			body:   `<html>oops</html>`,
+			err:    &Error{StatusCode: http.StatusInternalServerError},
+		},
+	}
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		var tc int
+		if _, err := fmt.Sscanf(r.URL.Path, "/v2/test/%d", &tc); err != nil {
+			t.Errorf("TestError: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(testCases[tc].status)
+		fmt.Fprint(w, testCases[tc].body)
+	})
+	defer s.Close()
+	c.MaxRetries = 0
+	for i, tc := range testCases {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/test/%d", s.URL, i), nil)
+		if err != nil {
+			t.Fatalf("TestError: %v", err)
+		}
+		err = c.do(context.Background(), req, nil)
+		e := &Error{}
+		if !errors.As(err, &e) {
+			t.Fatalf("TestError (#%d): unexpected error type %T", i, err)
+		}
+		if e.Method != http.MethodGet || e.Path != fmt.Sprintf("/v2/test/%d", i) {
+			t.Errorf("TestError (#%d): unexpected request: got '%s %s'", i, e.Method, e.Path)
+		}
+		if diff := cmp.Diff(tc.err, e, cmpopts.IgnoreFields(Error{}, "Method", "Path", "Data")); diff != "" {
+			t.Errorf("TestError (#%d): unexpected result:\n%s", i, diff)
+		}
+		if IsNotFound(err) != tc.notFound {
+			t.Errorf("TestError (#%d): IsNotFound returned %t", i, !tc.notFound)
+		}
+		if IsValidation(err) != tc.validation {
+			t.Errorf("TestError (#%d): IsValidation returned %t", i, !tc.validation)
+		}
+		if IsConflict(err) != tc.conflict {
+			t.Errorf("TestError (#%d): IsConflict returned %t", i, !tc.conflict)
+		}
+	}
+	if IsNotFound(fmt.Errorf("wrapped: %w", &Error{StatusCode: http.StatusNotFound})) != true {
+		t.Errorf("TestError: IsNotFound did not unwrap the error")
+	}
+}
@@ -0,0 +1,283 @@
+package fake
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+const (
+	AwsCompanyUid     = "3e2d1c5f-8a47-4b0e-9f6d-2c7a8b9e0f14"
+	AzureCompanyUid   = "4c7e2a9d-5b31-4f06-8d2e-1a9b6c3f5e87"
+	GoogleCompanyUid  = "5a8f0d3b-1c6e-4e27-8b9a-0d4f6c2e7a53"
+	OracleCompanyUid  = "6d9b3e1a-4f72-4c05-9e8b-5a0c2d7f1b46"
+	PartnerCompanyUid = "7b1e4f9c-2d5a-4c38-a6e0-8f3b9d1c4e62"
+)
+
+var (
+	azureServiceKeyRegexp = regexp.MustCompile(`^[[:xdigit:]]{8}-([[:xdigit:]]{4}-){3}[[:xdigit:]]{12}$`)
+	gcpPairingKeyRegexp   = regexp.MustCompile(`^[[:xdigit:]]{8}-([[:xdigit:]]{4}-){3}[[:xdigit:]]{12}/[\w]+-[\w]+\d/\d$`)
+	oracleOcidRegexp      = regexp.MustCompile(`^ocid1\.virtualcircuit\.[\w-]+\.[\w-]*\.\w+$`)
+)
+
+func defaultLocations() []*api.Location {
+	products := func() api.LocationProducts {
+		return api.LocationProducts{
+			Mcr:        true,
+			McrVersion: 2,
+			Mcr2:       []uint64{1000, 2500, 5000, 10000},
+			Megaport:   []uint64{1, 10, 100},
+		}
+	}
+	return []*api.Location{
+		{
+			Id:               1,
+			Name:             "Telehouse North",
+			Address:          api.LocationAddress{City: "London", Country: "United Kingdom", Postcode: "E14 2AA", Street: "Coriander Avenue"},
+			Country:          "United Kingdom",
+			Market:           "UK",
+			Metro:            "London",
+			NetworkRegion:    "MP1",
+			SiteCode:         "lon-thn",
+			Status:           "Active",
+			VRouterAvailable: true,
+			Products:         products(),
+		},
+		{
+			Id:               2,
+			Name:             "Equinix LD5",
+			Address:          api.LocationAddress{City: "Slough", Country: "United Kingdom", Postcode: "SL1 4AX", Street: "8 Buckingham Avenue"},
+			Country:          "United Kingdom",
+			Market:           "UK",
+			Metro:            "London",
+			NetworkRegion:    "MP1",
+			SiteCode:         "lon-ld5",
+			Status:           "Active",
+			VRouterAvailable: true,
+			Products:         products(),
+		},
+		{
+			Id:               3,
+			Name:             "Global Switch London East",
+			Address:          api.LocationAddress{City: "London", Country: "United Kingdom", Postcode: "E14 9YY", Street: "3 Nutmeg Lane"},
+			Country:          "United Kingdom",
+			Market:           "UK",
+			Metro:            "London",
+			NetworkRegion:    "MP1",
+			SiteCode:         "lon-gse",
+			Status:           "Active",
+			VRouterAvailable: true,
+			Products:         products(),
+		},
+		{
+			Id:               4,
+			Name:             "Interxion DUB2",
+			Address:          api.LocationAddress{City: "Dublin", Country: "Ireland", Postcode: "D12", Street: "Unit 4 Cookstown Industrial Estate"},
+			Country:          "Ireland",
+			Market:           "IE",
+			Metro:            "Dublin",
+			NetworkRegion:    "MP1",
+			SiteCode:         "dub-ix2",
+			Status:           "Active",
+			VRouterAvailable: true,
+			Products:         products(),
+		},
+		{
+			Id:               5,
+			Name:             "Equinix AM3",
+			Address:          api.LocationAddress{City: "Amsterdam", Country: "Netherlands", Postcode: "1098 XH", Street: "Science Park 610"},
+			Country:          "Netherlands",
+			Market:           "NL",
+			Metro:            "Amsterdam",
+			NetworkRegion:    "MP1",
+			SiteCode:         "ams-am3",
+			Status:           "Active",
+			VRouterAvailable: false,
+			Products:         products(),
+		},
+	}
+}
+
+func defaultPartnerPorts() []*api.Megaport {
+	return []*api.Megaport{
+		{
+			CompanyName:  "AWS",
+			CompanyUid:   AwsCompanyUid,
+			ConnectType:  api.VxcConnectTypeAws,
+			LocationId:   2,
+			ProductUid:   "a1b2c3d4-0001-4aa0-8000-000000000001",
+			Speed:        10000,
+			Title:        "Europe (Ireland) (eu-west-1)",
+			VxcPermitted: true,
+		},
+		{
+			CompanyName:  "AWS",
+			CompanyUid:   AwsCompanyUid,
+			ConnectType:  api.VxcConnectTypeAws,
+			LocationId:   2,
+			ProductUid:   "a1b2c3d4-0001-4aa0-8000-000000000002",
+			Speed:        10000,
+			Title:        "Europe (London) (eu-west-2)",
+			VxcPermitted: true,
+		},
+		{
+			CompanyName:  "AWS",
+			CompanyUid:   AwsCompanyUid,
+			ConnectType:  api.VxcConnectTypeAws,
+			LocationId:   4,
+			ProductUid:   "a1b2c3d4-0001-4aa0-8000-000000000003",
+			Speed:        10000,
+			Title:        "Europe (Ireland) (eu-west-1)",
+			VxcPermitted: true,
+		},
+		{
+			CompanyName:  "AWS",
+			CompanyUid:   AwsCompanyUid,
+			ConnectType:  api.VxcConnectTypeAws,
+			LocationId:   4,
+			ProductUid:   "a1b2c3d4-0001-4aa0-8000-000000000004",
+			Speed:        10000,
+			Title:        "Europe (Ireland) (eu-west-1) [full]",
+			VxcPermitted: false,
+		},
+		{
+			CompanyName:  "Fake Partner",
+			CompanyUid:   PartnerCompanyUid,
+			ConnectType:  "DEFAULT",
+			LocationId:   1,
+			ProductUid:   "a1b2c3d4-0001-4aa0-8000-000000000005",
+			Speed:        10000,
+			Title:        "Fake Partner London",
+			VxcPermitted: true,
+		},
+	}
+}
+
+func defaultGcpPorts() []*api.MegaportCloud {
+	return []*api.MegaportCloud{
+		{
+			CompanyName: "Google Inc",
+			CompanyUid:  GoogleCompanyUid,
+			Country:     "United Kingdom",
+			Description: "Google Cloud Interconnect",
+			LocationId:  1,
+			Name:        "London (lon-zone1-000)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0002-4aa0-8000-000000000001",
+			State:       "London",
+		},
+		{
+			CompanyName: "Google Inc",
+			CompanyUid:  GoogleCompanyUid,
+			Country:     "Netherlands",
+			Description: "Google Cloud Interconnect",
+			LocationId:  5,
+			Name:        "Amsterdam (ams-zone1-000)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0002-4aa0-8000-000000000002",
+			State:       "North Holland",
+		},
+	}
+}
+
+func defaultGcpBandwidths() []uint64 {
+	return []uint64{50, 100, 200, 300, 400, 500, 1000, 2000, 5000, 10000}
+}
+
+func defaultAzurePorts() []*api.MegaportCloud {
+	return []*api.MegaportCloud{
+		{
+			CompanyName: "Microsoft Azure",
+			CompanyUid:  AzureCompanyUid,
+			Country:     "United Kingdom",
+			Description: "Azure ExpressRoute",
+			LocationId:  2,
+			Name:        "Azure ExpressRoute London (Primary)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0003-4aa0-8000-000000000001",
+			State:       "London",
+			Type:        "primary",
+		},
+		{
+			CompanyName: "Microsoft Azure",
+			CompanyUid:  AzureCompanyUid,
+			Country:     "United Kingdom",
+			Description: "Azure ExpressRoute",
+			LocationId:  3,
+			Name:        "Azure ExpressRoute London (Secondary)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0003-4aa0-8000-000000000002",
+			State:       "London",
+			Type:        "secondary",
+		},
+	}
+}
+
+func defaultOraclePorts() []*api.MegaportCloud {
+	return []*api.MegaportCloud{
+		{
+			CompanyName: "Oracle",
+			CompanyUid:  OracleCompanyUid,
+			Country:     "United Kingdom",
+			Description: "Oracle Cloud Infrastructure FastConnect",
+			LocationId:  1,
+			Name:        "OCI FastConnect London (Primary)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0004-4aa0-8000-000000000001",
+			State:       "London",
+			Type:        "primary",
+		},
+		{
+			CompanyName: "Oracle",
+			CompanyUid:  OracleCompanyUid,
+			Country:     "United Kingdom",
+			Description: "Oracle Cloud Infrastructure FastConnect",
+			LocationId:  3,
+			Name:        "OCI FastConnect London (Secondary)",
+			PortSpeed:   10000,
+			ProductUid:  "a1b2c3d4-0004-4aa0-8000-000000000002",
+			State:       "London",
+			Type:        "secondary",
+		},
+	}
+}
+
+func defaultOracleBandwidths() []uint64 {
+	return []uint64{1000, 2000, 5000, 10000}
+}
+
+func defaultInternetExchanges() map[uint64][]*api.InternetExchange {
+	linx := func(lan uint64) *api.InternetExchange {
+		return &api.InternetExchange{
+			ASN:           8714,
+			Description:   fmt.Sprintf("LINX LON%d", lan),
+			GroupMetro:    "London",
+			Name:          fmt.Sprintf("London IX LON%d", lan),
+			NetworkRegion: "MP1",
+			PrimaryIPv4:   api.InternetExchangeIPAddress{Type: "A", Value: fmt.Sprintf("192.0.2.%d", 250+lan)},
+			PrimaryIPv6:   api.InternetExchangeIPAddress{Type: "AAAA", Value: fmt.Sprintf("2001:db8::%d:1", lan)},
+			SecondaryIPv4: api.InternetExchangeIPAddress{Type: "A", Value: fmt.Sprintf("192.0.2.%d", 252+lan)},
+			SecondaryIPv6: api.InternetExchangeIPAddress{Type: "AAAA", Value: fmt.Sprintf("2001:db8::%d:2", lan)},
+			State:         "UK",
+		}
+	}
+	amsix := &api.InternetExchange{
+		ASN:           6777,
+		Description:   "AMS-IX",
+		GroupMetro:    "Amsterdam",
+		Name:          "Amsterdam IX",
+		NetworkRegion: "MP1",
+		PrimaryIPv4:   api.InternetExchangeIPAddress{Type: "A", Value: "198.51.100.1"},
+		PrimaryIPv6:   api.InternetExchangeIPAddress{Type: "AAAA", Value: "2001:db8:1::1"},
+		SecondaryIPv4: api.InternetExchangeIPAddress{Type: "A", Value: "198.51.100.2"},
+		SecondaryIPv6: api.InternetExchangeIPAddress{Type: "AAAA", Value: "2001:db8:1::2"},
+		State:         "NL",
+	}
+	return map[uint64][]*api.InternetExchange{
+		1: {linx(1), linx(2)},
+		2: {linx(1), linx(2)},
+		3: {linx(1)},
+		5: {amsix},
+	}
+}
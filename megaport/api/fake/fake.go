@@ -0,0 +1,260 @@
+// Package fake implements an in-memory stand-in for the parts of the Megaport
+// API that are used by the provider, so that the acceptance tests can run
+// locally without network access or a Megaport account.
+//
+// The server models locations, partner ports, Internet Exchanges, products
+// (ports, LAGs, MCRs and MVEs), VXCs, IXs, their VLANs and the prefix filter
+// lists of MCRs. Products move through the provisioning lifecycle one step
+// each time they are retrieved: DEPLOYABLE, CONFIGURED and LIVE after being
+// ordered, and CANCELLED (or CANCELLED_PARENT for the VXCs and IXs of a
+// cancelled product) and then DECOMMISSIONED after being cancelled.
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+const (
+	// CompanyUid and CompanyName identify the company that owns every
+	// product ordered through the server.
+	CompanyUid  = "9c5b4e2a-6a1f-4d8e-9a8c-6f0a3e1b7d21"
+	CompanyName = "Fake Company"
+)
+
+// Server is a fake Megaport API. The exported catalogue fields are read by the
+// server while handling requests, so they must only be modified before any
+// request is made.
+type Server struct {
+	*httptest.Server
+
+	// Token is the only access token accepted by the server. It is also the
+	// token handed out by the login and OAuth token endpoints, regardless of
+	// the credentials used.
+	Token string
+
+	Locations     []*api.Location
+	PartnerPorts  []*api.Megaport
+	GcpPorts      []*api.MegaportCloud
+	GcpBandwidths []uint64
+	AzurePorts    []*api.MegaportCloud
+	// AzureBandwidth is the bandwidth of every ExpressRoute circuit, which
+	// limits the rate of the VXCs to it
+	AzureBandwidth   uint64
+	OraclePorts      []*api.MegaportCloud
+	OracleBandwidths []uint64
+	// InternetExchanges lists the Internet Exchanges that can be reached
+	// from each location, by location id
+	InternetExchanges map[uint64][]*api.InternetExchange
+
+	mu          sync.Mutex
+	products    map[string]*product
+	vxcs        map[string]*vxc
+	ixs         map[string]*ix
+	prefixLists map[uint64]*prefixList
+	nextId      uint64
+}
+
+// NewServer starts a fake Megaport API, served over TLS, seeded with a small
+// catalogue of locations and partner ports. The caller should call Close when
+// finished.
+func NewServer() *Server {
+	s := &Server{
+		Token:             uuid.New().String(),
+		Locations:         defaultLocations(),
+		PartnerPorts:      defaultPartnerPorts(),
+		GcpPorts:          defaultGcpPorts(),
+		GcpBandwidths:     defaultGcpBandwidths(),
+		AzurePorts:        defaultAzurePorts(),
+		AzureBandwidth:    1000,
+		OraclePorts:       defaultOraclePorts(),
+		OracleBandwidths:  defaultOracleBandwidths(),
+		InternetExchanges: defaultInternetExchanges(),
+		products:          map[string]*product{},
+		vxcs:              map[string]*vxc{},
+		ixs:               map[string]*ix{},
+		prefixLists:       map[uint64]*prefixList{},
+		nextId:            1,
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// NewClient returns an API client that trusts the certificate of the server
+// and is authenticated against it.
+func (s *Server) NewClient() *api.Client {
+	c := api.NewClient(s.URL)
+	c.HTTPClient = s.Client()
+	c.Token = s.Token
+	return c
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/oauth2/token":
+		s.handleOAuthToken(w, r)
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/login":
+		s.handleLogin(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeResponse(w, http.StatusUnauthorized, "Invalid or expired token", nil)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/logout":
+		writeResponse(w, http.StatusOK, "Logged out", nil)
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/locations":
+		writeResponse(w, http.StatusOK, "", s.Locations)
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/dropdowns/partner/megaports":
+		writeResponse(w, http.StatusOK, "", s.PartnerPorts)
+	case r.Method == http.MethodGet && len(p) == 6 && p[1] == "secure" && p[2] == "google":
+		s.handleGcpPairingKey(w, strings.Join(p[3:], "/"))
+	case r.Method == http.MethodGet && len(p) == 4 && p[1] == "secure" && p[2] == "azure":
+		s.handleAzureServiceKey(w, p[3])
+	case r.Method == http.MethodGet && len(p) == 4 && p[1] == "secure" && p[2] == "oracle":
+		s.handleOracleVirtualCircuit(w, p[3])
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/product/ix/types":
+		s.handleInternetExchanges(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/networkdesign/validate":
+		s.handleNetworkDesign(w, r, false)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/networkdesign/buy":
+		s.handleNetworkDesign(w, r, true)
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/products":
+		s.handleListProducts(w)
+	case r.Method == http.MethodPost && len(p) == 5 && p[1] == "product" && p[2] == "mcr2" && p[4] == "prefixList":
+		s.handleCreatePrefixList(w, r, p[3])
+	case r.Method == http.MethodGet && len(p) == 5 && p[1] == "product" && p[2] == "mcr2" && p[4] == "prefixLists":
+		s.handleListPrefixLists(w, p[3])
+	case r.Method == http.MethodGet && len(p) == 6 && p[1] == "product" && p[2] == "mcr2" && p[4] == "prefixList":
+		s.handleGetPrefixList(w, p[3], p[5])
+	case r.Method == http.MethodPut && len(p) == 6 && p[1] == "product" && p[2] == "mcr2" && p[4] == "prefixList":
+		s.handleUpdatePrefixList(w, r, p[3], p[5])
+	case r.Method == http.MethodDelete && len(p) == 6 && p[1] == "product" && p[2] == "mcr2" && p[4] == "prefixList":
+		s.handleDeletePrefixList(w, p[3], p[5])
+	case r.Method == http.MethodGet && len(p) == 5 && p[1] == "product" && p[2] == "port" && p[4] == "vlan":
+		s.handlePortVlan(w, r, p[3])
+	case r.Method == http.MethodPost && len(p) == 5 && p[1] == "product" && p[3] == "action":
+		s.handleProductAction(w, p[2], p[4])
+	case r.Method == http.MethodGet && len(p) == 3 && p[1] == "product":
+		s.handleGetProduct(w, p[2])
+	case r.Method == http.MethodPut && len(p) == 4 && p[1] == "product":
+		s.handleUpdateProduct(w, r, p[2], p[3])
+	default:
+		writeResponse(w, http.StatusNotFound, "Not Found", nil)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	return r.Header.Get("X-Auth-Token") == s.Token || r.Header.Get("Authorization") == "Bearer "+s.Token
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if r.Form.Get("username") == "" || r.Form.Get("password") == "" {
+		writeResponse(w, http.StatusUnauthorized, "Invalid username or password", nil)
+		return
+	}
+	writeResponse(w, http.StatusOK, "Login successful", map[string]interface{}{"token": s.Token})
+}
+
+func (s *Server) handleOAuthToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, secret, ok := r.BasicAuth()
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"}) // nolint: errcheck
+		return
+	}
+	if !ok || id == "" || secret == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"}) // nolint: errcheck
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{ // nolint: errcheck
+		"access_token": s.Token,
+		"token_type":   "Bearer",
+		"expires_in":   86400,
+	})
+}
+
+func (s *Server) handleGcpPairingKey(w http.ResponseWriter, pairingKey string) {
+	if !gcpPairingKeyRegexp.MatchString(pairingKey) {
+		writeResponse(w, http.StatusBadRequest, "Invalid pairing key", nil)
+		return
+	}
+	writeResponse(w, http.StatusOK, "", map[string]interface{}{
+		"bandwidths":    s.GcpBandwidths,
+		"megaports":     s.GcpPorts,
+		"resource_type": "csp_partner_ports",
+	})
+}
+
+func (s *Server) handleAzureServiceKey(w http.ResponseWriter, serviceKey string) {
+	if !azureServiceKeyRegexp.MatchString(serviceKey) {
+		writeResponse(w, http.StatusBadRequest, "Invalid service key", nil)
+		return
+	}
+	writeResponse(w, http.StatusOK, "", map[string]interface{}{
+		"bandwidth":     s.AzureBandwidth,
+		"megaports":     s.AzurePorts,
+		"peers":         []interface{}{},
+		"resource_type": "csp_partner_ports",
+		"service_key":   serviceKey,
+		"vlan":          0,
+	})
+}
+
+func (s *Server) handleOracleVirtualCircuit(w http.ResponseWriter, virtualCircuitId string) {
+	if !oracleOcidRegexp.MatchString(virtualCircuitId) {
+		writeResponse(w, http.StatusBadRequest, "Invalid virtual circuit id", nil)
+		return
+	}
+	writeResponse(w, http.StatusOK, "", map[string]interface{}{
+		"bandwidths":    s.OracleBandwidths,
+		"megaports":     s.OraclePorts,
+		"resource_type": "csp_partner_ports",
+	})
+}
+
+func (s *Server) newUid() string {
+	return uuid.New().String()
+}
+
+func (s *Server) newId() uint64 {
+	id := s.nextId
+	s.nextId++
+	return id
+}
+
+type fieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs []fieldError) {
+	writeResponse(w, http.StatusBadRequest, "Validation failed", errs)
+}
+
+func writeResponse(w http.ResponseWriter, status int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{ // nolint: errcheck
+		"message": message,
+		"data":    data,
+	})
+}
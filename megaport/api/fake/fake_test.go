@@ -0,0 +1,729 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func TestServer_unauthorized(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := api.NewClient(s.URL)
+	c.HTTPClient = s.Client()
+	c.MaxRetries = 0
+	if _, err := c.GetLocations(context.Background()); err == nil {
+		t.Errorf("TestServer_unauthorized: expected an error without a token")
+	}
+	if err := c.Login(context.Background(), "foo", "bar", ""); err != nil {
+		t.Fatalf("TestServer_unauthorized: %v", err)
+	}
+	if c.Token != s.Token {
+		t.Errorf("TestServer_unauthorized: unexpected token: got '%s', expected '%s'", c.Token, s.Token)
+	}
+	if _, err := c.GetLocations(context.Background()); err != nil {
+		t.Errorf("TestServer_unauthorized: %v", err)
+	}
+}
+
+func TestServer_portLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	uid, err := c.CreatePort(ctx, &api.PortCreateInput{
+		LocationId: api.Uint64(uint64(1)),
+		Name:       api.String("foo"),
+		Speed:      api.Uint64(uint64(1000)),
+		Term:       api.Uint64(uint64(12)),
+	})
+	if err != nil {
+		t.Fatalf("TestServer_portLifecycle: %v", err)
+	}
+	for _, status := range []string{api.ProductStatusDeployable, api.ProductStatusConfigured, api.ProductStatusLive, api.ProductStatusLive} {
+		p, err := c.GetPort(ctx, *uid)
+		if err != nil {
+			t.Fatalf("TestServer_portLifecycle: %v", err)
+		}
+		if p.ProvisioningStatus != status {
+			t.Errorf("TestServer_portLifecycle: unexpected status: got '%s', expected '%s'", p.ProvisioningStatus, status)
+		}
+	}
+	if err := c.UpdatePort(ctx, &api.PortUpdateInput{ProductUid: uid, Name: api.String("bar"), MarketplaceVisibility: api.Bool(true)}); err != nil {
+		t.Fatalf("TestServer_portLifecycle: %v", err)
+	}
+	p, err := c.GetPort(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_portLifecycle: %v", err)
+	}
+	if p.ProductName != "bar" || !p.MarketplaceVisibility || p.PortSpeed != 1000 || p.ContractTermMonths != 12 || p.LocationId != 1 {
+		t.Errorf("TestServer_portLifecycle: unexpected port: %#v", p)
+	}
+	if err := c.DeletePort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_portLifecycle: %v", err)
+	}
+	for _, status := range []string{api.ProductStatusCancelled, api.ProductStatusDecommissioned} {
+		p, err := c.GetPort(ctx, *uid)
+		if err != nil {
+			t.Fatalf("TestServer_portLifecycle: %v", err)
+		}
+		if p.ProvisioningStatus != status {
+			t.Errorf("TestServer_portLifecycle: unexpected status: got '%s', expected '%s'", p.ProvisioningStatus, status)
+		}
+	}
+	if err := c.DeletePort(ctx, *uid); !api.IsValidation(err) {
+		t.Errorf("TestServer_portLifecycle: expected a validation error when cancelling twice, got %v", err)
+	}
+	if _, err := c.GetPort(ctx, "foo"); !api.IsNotFound(err) {
+		t.Errorf("TestServer_portLifecycle: expected a not found error, got %v", err)
+	}
+}
+
+func TestServer_cancelAtEndOfTerm(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	uid, err := c.CreatePort(ctx, &api.PortCreateInput{
+		LocationId: api.Uint64(uint64(1)),
+		Name:       api.String("foo"),
+		Speed:      api.Uint64(uint64(1000)),
+		Term:       api.Uint64(uint64(12)),
+	})
+	if err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	if err := c.RestorePort(ctx, *uid); !api.IsValidation(err) {
+		t.Errorf("TestServer_cancelAtEndOfTerm: expected a validation error when restoring a live port, got %v", err)
+	}
+	if err := c.CancelPort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		p, err := c.GetPort(ctx, *uid)
+		if err != nil {
+			t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+		}
+		if !p.CancellationPending() {
+			t.Errorf("TestServer_cancelAtEndOfTerm: expected the port to be pending cancellation, got %s (%d)", p.ProvisioningStatus, p.TerminateDate)
+		}
+	}
+	if err := c.RestorePort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	p, err := c.GetPort(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	if p.ProvisioningStatus != api.ProductStatusLive || p.TerminateDate != 0 {
+		t.Errorf("TestServer_cancelAtEndOfTerm: expected the port to be restored, got %s (%d)", p.ProvisioningStatus, p.TerminateDate)
+	}
+	if err := c.CancelPort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	if err := c.DeletePort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+	}
+	for _, status := range []string{api.ProductStatusCancelled, api.ProductStatusDecommissioned} {
+		p, err := c.GetPort(ctx, *uid)
+		if err != nil {
+			t.Fatalf("TestServer_cancelAtEndOfTerm: %v", err)
+		}
+		if p.ProvisioningStatus != status || p.CancellationPending() {
+			t.Errorf("TestServer_cancelAtEndOfTerm: unexpected status: got '%s', expected '%s'", p.ProvisioningStatus, status)
+		}
+	}
+}
+
+func TestServer_lag(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	input := &api.PortCreateInput{
+		LagPortCount: api.Uint64(uint64(2)),
+		LocationId:   api.Uint64(uint64(1)),
+		Name:         api.String("foo"),
+		Speed:        api.Uint64(uint64(1000)),
+		Term:         api.Uint64(uint64(12)),
+	}
+	if _, err := c.CreatePort(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_lag: expected a validation error for a 1G LAG, got %v", err)
+	}
+	input.Speed = api.Uint64(uint64(10000))
+	uid, err := c.CreatePort(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_lag: %v", err)
+	}
+	p, err := c.GetPort(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_lag: %v", err)
+	}
+	if p.LagId == 0 || !p.LagPrimary {
+		t.Fatalf("TestServer_lag: expected the primary port of a LAG, got %#v", p)
+	}
+	input.LagId = api.Uint64(p.LagId)
+	input.LagPortCount = api.Uint64(uint64(7))
+	if _, err := c.CreatePort(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_lag: expected a validation error for a LAG of 9 ports, got %v", err)
+	}
+	input.LagPortCount = api.Uint64(uint64(1))
+	if _, err := c.CreatePort(ctx, input); err != nil {
+		t.Fatalf("TestServer_lag: %v", err)
+	}
+	lag, err := c.GetLagPorts(ctx, p.LagId)
+	if err != nil {
+		t.Fatalf("TestServer_lag: %v", err)
+	}
+	if len(lag) != 3 || lag[0].ProductUid != *uid || lag[1].LagPrimary || lag[2].LagPrimary {
+		t.Errorf("TestServer_lag: unexpected LAG ports: %#v", lag)
+	}
+	if err := c.DeletePort(ctx, *uid); err != nil {
+		t.Fatalf("TestServer_lag: %v", err)
+	}
+	if lag, err := c.GetLagPorts(ctx, p.LagId); err != nil || len(lag) != 0 {
+		t.Errorf("TestServer_lag: expected the LAG ports to be cancelled, got %#v (%v)", lag, err)
+	}
+}
+
+func TestServer_validation(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	_, err := c.CreatePort(ctx, &api.PortCreateInput{
+		LocationId: api.Uint64(uint64(1)),
+		Name:       api.String("foo"),
+		Speed:      api.Uint64(uint64(2000)),
+		Term:       api.Uint64(uint64(2)),
+	})
+	if !api.IsValidation(err) {
+		t.Fatalf("TestServer_validation: expected a validation error, got %v", err)
+	}
+	e := err.(*api.Error)
+	if len(e.FieldErrors) != 2 || e.FieldErrors[0].Field != "portSpeed" || e.FieldErrors[1].Field != "term" {
+		t.Errorf("TestServer_validation: unexpected field errors: %#v", e.FieldErrors)
+	}
+	if _, err := c.CreateMcr(ctx, &api.Mcr2CreateInput{
+		LocationId: api.Uint64(uint64(99)),
+		Name:       api.String("foo"),
+		RateLimit:  api.Uint64(uint64(1000)),
+	}); !api.IsValidation(err) {
+		t.Errorf("TestServer_validation: expected a validation error, got %v", err)
+	}
+}
+
+func TestServer_vxcs(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	portA, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	mcr, err := c.CreateMcr(ctx, &api.Mcr2CreateInput{LocationId: api.Uint64(uint64(2)), Name: api.String("b"), RateLimit: api.Uint64(uint64(1000))})
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	m, err := c.GetMcr(ctx, *mcr)
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	if m.Resources.VirtualRouter.McrASN != DefaultMcrAsn {
+		t.Errorf("TestServer_vxcs: unexpected MCR ASN: got %d, expected %d", m.Resources.VirtualRouter.McrASN, DefaultMcrAsn)
+	}
+
+	private, err := c.CreatePrivateVxc(ctx, &api.PrivateVxcCreateInput{ProductUidA: portA, ProductUidB: mcr, Name: api.String("private"), RateLimit: api.Uint64(uint64(100))})
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	v, err := c.GetVxc(ctx, *private)
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	if v.Type() != api.VxcTypePrivate || v.AEnd.Vlan != 2 || v.BEnd.Vlan != 2 || v.RateLimit != 100 {
+		t.Errorf("TestServer_vxcs: unexpected private VXC: %#v", v)
+	}
+	if v.Resources.GetCspConnection(api.VxcConnectTypeVRouter) == nil {
+		t.Errorf("TestServer_vxcs: expected a VROUTER connection for a VXC to an MCR")
+	}
+	if ok, err := c.GetPortVlanIdAvailable(ctx, *portA, 2); err != nil || ok {
+		t.Errorf("TestServer_vxcs: expected VLAN 2 to be in use (%v)", err)
+	}
+	if err := c.UpdatePrivateVxc(ctx, &api.PrivateVxcUpdateInput{ProductUid: private, VlanA: api.Uint64(uint64(100))}); err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	if ok, err := c.GetPortVlanIdAvailable(ctx, *portA, 2); err != nil || !ok {
+		t.Errorf("TestServer_vxcs: expected VLAN 2 to be available after the update (%v)", err)
+	}
+
+	aws, err := c.CreateCloudVxc(ctx, &api.CloudVxcCreateInput{
+		ProductUidA: portA,
+		ProductUidB: api.String(s.PartnerPorts[0].ProductUid),
+		Name:        api.String("aws"),
+		RateLimit:   api.Uint64(uint64(100)),
+		VlanA:       api.Uint64(uint64(100)),
+		PartnerConfig: &api.PartnerConfigAws{
+			AwsAccountId: api.String("123456789012"),
+			CustomerASN:  api.Uint64(uint64(65000)),
+			Type:         api.String("private"),
+		},
+	})
+	if err == nil || !api.IsValidation(err) {
+		t.Fatalf("TestServer_vxcs: expected a validation error for a VLAN in use, got %v (%v)", aws, err)
+	}
+	aws, err = c.CreateCloudVxc(ctx, &api.CloudVxcCreateInput{
+		ProductUidA: portA,
+		ProductUidB: api.String(s.PartnerPorts[0].ProductUid),
+		Name:        api.String("aws"),
+		RateLimit:   api.Uint64(uint64(100)),
+		PartnerConfig: &api.PartnerConfigAws{
+			AwsAccountId: api.String("123456789012"),
+			CustomerASN:  api.Uint64(uint64(65000)),
+			Type:         api.String("private"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	v, err = c.GetVxc(ctx, *aws)
+	if err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAws).(*api.ProductAssociatedVxcResourcesCspConnectionAws)
+	if v.Type() != api.VxcTypeAws || !ok {
+		t.Fatalf("TestServer_vxcs: unexpected AWS VXC: %#v", v)
+	}
+	if cc.OwnerAccount != "123456789012" || cc.Asn != 65000 || cc.Name != "aws" || cc.AuthKey == "" || cc.AmazonIpAddress == "" || cc.CustomerIpAddress == "" {
+		t.Errorf("TestServer_vxcs: unexpected AWS connection: %#v", cc)
+	}
+
+	if err := c.DeletePort(ctx, *portA); err != nil {
+		t.Fatalf("TestServer_vxcs: %v", err)
+	}
+	for _, uid := range []string{*private, *aws} {
+		v, err := c.GetVxc(ctx, uid)
+		if err != nil {
+			t.Fatalf("TestServer_vxcs: %v", err)
+		}
+		if v.ProvisioningStatus != api.ProductStatusCancelledParent {
+			t.Errorf("TestServer_vxcs: unexpected status: got '%s', expected '%s'", v.ProvisioningStatus, api.ProductStatusCancelledParent)
+		}
+	}
+	if ok, err := c.GetPortVlanIdAvailable(ctx, *mcr, 2); err != nil || !ok {
+		t.Errorf("TestServer_vxcs: expected VLAN 2 to be released when the parent port is cancelled (%v)", err)
+	}
+}
+
+func TestServer_gcp(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	pk := "7e51371e-72a3-40b5-b844-2e3efefaee59/europe-west1/2"
+	ports, bandwidths, err := c.GetMegaportsForGcpPairingKey(ctx, pk)
+	if err != nil {
+		t.Fatalf("TestServer_gcp: %v", err)
+	}
+	if len(ports) != len(s.GcpPorts) || len(bandwidths) != len(s.GcpBandwidths) {
+		t.Errorf("TestServer_gcp: unexpected ports (%d) and bandwidths (%d)", len(ports), len(bandwidths))
+	}
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_gcp: %v", err)
+	}
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:   port,
+		ProductUidB:   api.String(ports[0].ProductUid),
+		Name:          api.String("gcp"),
+		RateLimit:     api.Uint64(bandwidths[0]),
+		PartnerConfig: &api.PartnerConfigGcp{PairingKey: api.String(pk)},
+	}
+	uid, err := c.CreateCloudVxc(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_gcp: %v", err)
+	}
+	if _, err := c.CreateCloudVxc(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_gcp: expected a validation error when reusing a pairing key, got %v", err)
+	}
+	v, err := c.GetVxc(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_gcp: %v", err)
+	}
+	cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeGoogle).(*api.ProductAssociatedVxcResourcesCspConnectionGcp)
+	if v.Type() != api.VxcTypeGcp || !ok || cc.PairingKey != pk || cc.Bandwidth != bandwidths[0] {
+		t.Errorf("TestServer_gcp: unexpected GCP VXC: %#v", v)
+	}
+}
+
+func TestServer_azure(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	sk := "1b2c3d4e-5f60-4718-9a2b-3c4d5e6f7081"
+	ports, bandwidths, err := c.GetMegaportsForAzureServiceKey(ctx, sk)
+	if err != nil {
+		t.Fatalf("TestServer_azure: %v", err)
+	}
+	if len(ports) != len(s.AzurePorts) || len(bandwidths) != 1 || bandwidths[0] != s.AzureBandwidth {
+		t.Errorf("TestServer_azure: unexpected ports (%d) and bandwidths (%v)", len(ports), bandwidths)
+	}
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(2)), Name: api.String("a"), Speed: api.Uint64(uint64(10000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_azure: %v", err)
+	}
+	input := &api.CloudVxcCreateInput{
+		ProductUidA: port,
+		ProductUidB: api.String(ports[0].ProductUid),
+		Name:        api.String("azure"),
+		RateLimit:   api.Uint64(uint64(500)),
+		PartnerConfig: &api.PartnerConfigAzure{
+			ServiceKey: api.String(sk),
+			Peers: []*api.PartnerConfigAzurePeer{{
+				PeerASN:         api.Uint64(uint64(64512)),
+				PrimarySubnet:   api.String("10.0.0.0/30"),
+				SecondarySubnet: api.String("10.0.0.4/30"),
+				SharedKey:       api.String("secret"),
+				Type:            api.String("private"),
+				Vlan:            api.Uint64(uint64(100)),
+			}},
+		},
+	}
+	uid, err := c.CreateCloudVxc(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_azure: %v", err)
+	}
+	v, err := c.GetVxc(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_azure: %v", err)
+	}
+	cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAzure).(*api.ProductAssociatedVxcResourcesCspConnectionAzure)
+	if v.Type() != api.VxcTypeAzure || !ok || cc.ServiceKey != sk || len(cc.Peers) != 1 || cc.Peers[0].PeerAsn != "64512" || cc.Peers[0].SharedKey != "" {
+		t.Errorf("TestServer_azure: unexpected Azure VXC: %#v", v)
+	}
+	input.ProductUidB = api.String(ports[1].ProductUid)
+	if _, err := c.CreateCloudVxc(ctx, input); err != nil {
+		t.Errorf("TestServer_azure: expected the secondary VXC to be created: %v", err)
+	}
+	if _, err := c.CreateCloudVxc(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_azure: expected a validation error when reusing a service key a third time, got %v", err)
+	}
+	input.PartnerConfig = &api.PartnerConfigAzure{ServiceKey: api.String("1b2c3d4e-5f60-4718-9a2b-3c4d5e6f7082")}
+	input.RateLimit = api.Uint64(s.AzureBandwidth + 1)
+	if _, err := c.CreateCloudVxc(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_azure: expected a validation error for a rate limit above the bandwidth of the circuit, got %v", err)
+	}
+}
+
+func TestServer_oracle(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	vc := "ocid1.virtualcircuit.oc1.uk-london-1.aaaabbbbccccdddd"
+	if _, _, err := c.GetMegaportsForOracleVirtualCircuit(ctx, "foo"); err == nil {
+		t.Errorf("TestServer_oracle: expected an error for an invalid virtual circuit id")
+	}
+	ports, bandwidths, err := c.GetMegaportsForOracleVirtualCircuit(ctx, vc)
+	if err != nil {
+		t.Fatalf("TestServer_oracle: %v", err)
+	}
+	if len(ports) != len(s.OraclePorts) || len(bandwidths) != len(s.OracleBandwidths) {
+		t.Errorf("TestServer_oracle: unexpected ports (%d) and bandwidths (%d)", len(ports), len(bandwidths))
+	}
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(10000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_oracle: %v", err)
+	}
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:   port,
+		ProductUidB:   api.String(ports[0].ProductUid),
+		Name:          api.String("oracle"),
+		RateLimit:     api.Uint64(bandwidths[0]),
+		PartnerConfig: &api.PartnerConfigOracle{VirtualCircuitId: api.String(vc)},
+	}
+	uid, err := c.CreateCloudVxc(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_oracle: %v", err)
+	}
+	if _, err := c.CreateCloudVxc(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_oracle: expected a validation error when reusing a virtual circuit, got %v", err)
+	}
+	v, err := c.GetVxc(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_oracle: %v", err)
+	}
+	cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeOracle).(*api.ProductAssociatedVxcResourcesCspConnectionOracle)
+	if v.Type() != api.VxcTypeOracle || !ok || cc.VirtualCircuitId != vc || cc.Bandwidth != bandwidths[0] {
+		t.Errorf("TestServer_oracle: unexpected Oracle VXC: %#v", v)
+	}
+}
+
+func TestServer_mcrConfig(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	mcr, err := c.CreateMcr(ctx, &api.Mcr2CreateInput{LocationId: api.Uint64(uint64(2)), Name: api.String("b"), RateLimit: api.Uint64(uint64(1000))})
+	if err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	pc := &api.PartnerConfigVRouter{Interfaces: []*api.PartnerConfigVRouterInterface{{
+		IpAddresses: []string{"10.0.0.1/30"},
+		BgpConnections: []*api.PartnerConfigVRouterBgpConnection{{
+			LocalIpAddress: api.String("10.0.0.1"),
+			Password:       api.String("secret"),
+			PeerAsn:        api.Uint64(uint64(64512)),
+			PeerIpAddress:  api.String("10.0.0.2"),
+		}},
+	}}}
+	if _, err := c.CreatePrivateVxc(ctx, &api.PrivateVxcCreateInput{PartnerConfigA: pc, ProductUidA: port, ProductUidB: mcr, Name: api.String("port"), RateLimit: api.Uint64(uint64(100))}); !api.IsValidation(err) {
+		t.Errorf("TestServer_mcrConfig: expected a validation error for an A-End that is not an MCR, got %v", err)
+	}
+	uid, err := c.CreatePrivateVxc(ctx, &api.PrivateVxcCreateInput{PartnerConfigA: pc, ProductUidA: mcr, ProductUidB: port, Name: api.String("mcr"), RateLimit: api.Uint64(uint64(100))})
+	if err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	v, err := c.GetVxc(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	cc := v.Resources.GetVRouterCspConnection("a_csp_connection")
+	if cc == nil || len(cc.Interfaces) != 1 || len(cc.Interfaces[0].BgpConnections) != 1 {
+		t.Fatalf("TestServer_mcrConfig: unexpected VROUTER connection: %#v", cc)
+	}
+	if bgp := cc.Interfaces[0].BgpConnections[0]; bgp.PeerAsn != 64512 || bgp.PeerIpAddress != "10.0.0.2" || bgp.Password != "" {
+		t.Errorf("TestServer_mcrConfig: unexpected BGP connection: %#v", bgp)
+	}
+	pc.Interfaces[0].BgpConnections[0].PeerIpAddress = api.String("10.0.1.2")
+	if err := c.UpdatePrivateVxc(ctx, &api.PrivateVxcUpdateInput{PartnerConfigA: pc, ProductUid: uid}); !api.IsValidation(err) {
+		t.Errorf("TestServer_mcrConfig: expected a validation error for a peer outside of the interface subnet, got %v", err)
+	}
+	if err := c.UpdatePrivateVxc(ctx, &api.PrivateVxcUpdateInput{PartnerConfigA: &api.PartnerConfigVRouter{}, ProductUid: uid}); err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	v, err = c.GetVxc(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_mcrConfig: %v", err)
+	}
+	if cc := v.Resources.GetVRouterCspConnection("a_csp_connection"); cc == nil || len(cc.Interfaces) != 0 {
+		t.Errorf("TestServer_mcrConfig: expected the configuration to be removed: %#v", cc)
+	}
+}
+
+func TestServer_prefixLists(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	mcr, err := c.CreateMcr(ctx, &api.Mcr2CreateInput{LocationId: api.Uint64(uint64(2)), Name: api.String("b"), RateLimit: api.Uint64(uint64(1000))})
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	input := &api.McrPrefixFilterListCreateInput{
+		AddressFamily: api.String(api.PrefixFilterListAddressFamilyIPv4),
+		Description:   api.String("foo"),
+		Entries: []*api.McrPrefixFilterListEntryInput{
+			{Action: api.String(api.PrefixFilterListActionPermit), Prefix: api.String("10.0.0.0/8"), Ge: api.Uint64(uint64(16)), Le: api.Uint64(uint64(24))},
+			{Action: api.String(api.PrefixFilterListActionDeny), Prefix: api.String("0.0.0.0/0")},
+		},
+		McrUid: port,
+	}
+	if _, err := c.CreateMcrPrefixFilterList(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_prefixLists: expected a validation error for a port, got %v", err)
+	}
+	input.McrUid = mcr
+	input.Entries[0].Ge = api.Uint64(uint64(4))
+	if _, err := c.CreateMcrPrefixFilterList(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_prefixLists: expected a validation error for ge shorter than the prefix, got %v", err)
+	}
+	input.Entries[0].Ge = api.Uint64(uint64(16))
+	id, err := c.CreateMcrPrefixFilterList(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	l, err := c.GetMcrPrefixFilterList(ctx, *mcr, *id)
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	if l.Description != "foo" || len(l.Entries) != 2 || l.Entries[0].Le != 24 || l.Entries[1].Action != api.PrefixFilterListActionDeny {
+		t.Errorf("TestServer_prefixLists: unexpected prefix filter list: %#v", l)
+	}
+	pc := &api.PartnerConfigVRouter{Interfaces: []*api.PartnerConfigVRouterInterface{{
+		IpAddresses: []string{"10.0.0.1/30"},
+		BgpConnections: []*api.PartnerConfigVRouterBgpConnection{{
+			ImportWhitelist: api.Uint64(*id + 100),
+			LocalIpAddress:  api.String("10.0.0.1"),
+			PeerAsn:         api.Uint64(uint64(64512)),
+			PeerIpAddress:   api.String("10.0.0.2"),
+		}},
+	}}}
+	if _, err := c.CreatePrivateVxc(ctx, &api.PrivateVxcCreateInput{PartnerConfigA: pc, ProductUidA: mcr, ProductUidB: port, Name: api.String("c"), RateLimit: api.Uint64(uint64(100))}); !api.IsValidation(err) {
+		t.Errorf("TestServer_prefixLists: expected a validation error for an unknown prefix filter list, got %v", err)
+	}
+	pc.Interfaces[0].BgpConnections[0].ImportWhitelist = id
+	vxc, err := c.CreatePrivateVxc(ctx, &api.PrivateVxcCreateInput{PartnerConfigA: pc, ProductUidA: mcr, ProductUidB: port, Name: api.String("c"), RateLimit: api.Uint64(uint64(100))})
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	if err := c.UpdateMcrPrefixFilterList(ctx, &api.McrPrefixFilterListUpdateInput{AddressFamily: api.String(api.PrefixFilterListAddressFamilyIPv4), Description: api.String("bar"), Id: id, McrUid: mcr}); err != nil {
+		t.Errorf("TestServer_prefixLists: %v", err)
+	}
+	ls, err := c.ListMcrPrefixFilterLists(ctx, *mcr)
+	if err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	if len(ls) != 1 || ls[0].Id != *id || ls[0].Description != "bar" || len(ls[0].Entries) != 0 {
+		t.Errorf("TestServer_prefixLists: unexpected prefix filter lists: %#v", ls)
+	}
+	if err := c.DeleteMcrPrefixFilterList(ctx, *mcr, *id); !api.IsValidation(err) {
+		t.Errorf("TestServer_prefixLists: expected a validation error for a prefix filter list in use, got %v", err)
+	}
+	if err := c.DeleteVxc(ctx, *vxc); err != nil {
+		t.Fatalf("TestServer_prefixLists: %v", err)
+	}
+	if err := c.DeleteMcrPrefixFilterList(ctx, *mcr, *id); err != nil {
+		t.Errorf("TestServer_prefixLists: %v", err)
+	}
+	if _, err := c.GetMcrPrefixFilterList(ctx, *mcr, *id); !api.IsNotFound(err) {
+		t.Errorf("TestServer_prefixLists: expected a not found error, got %v", err)
+	}
+}
+
+func TestServer_ix(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	exchanges, err := c.GetInternetExchanges(ctx, 1)
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	if len(exchanges) != 2 || exchanges[0].Name != "London IX LON1" || exchanges[0].ASN != 8714 {
+		t.Errorf("TestServer_ix: unexpected Internet Exchanges: %#v", exchanges)
+	}
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("a"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	input := &api.IxCreateInput{
+		Asn:              api.Uint64(uint64(65000)),
+		InternetExchange: api.String("Amsterdam IX"),
+		MacAddress:       api.String("00:11:22:33:44:55"),
+		Name:             api.String("ix"),
+		ProductUid:       port,
+		RateLimit:        api.Uint64(uint64(500)),
+	}
+	if _, err := c.CreateIx(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_ix: expected a validation error for an Internet Exchange at another location, got %v", err)
+	}
+	input.InternetExchange = api.String("London IX LON1")
+	uid, err := c.CreateIx(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	x, err := c.GetIx(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	if x.ProductType != api.ProductTypeIx || x.NetworkServiceType != "London IX LON1" || x.Vlan != 2 || x.Asn != 65000 || x.RateLimit != 500 || x.LocationId != 1 {
+		t.Errorf("TestServer_ix: unexpected IX: %#v", x)
+	}
+	if len(x.IpAddresses(4)) != 1 || len(x.IpAddresses(6)) != 1 {
+		t.Errorf("TestServer_ix: unexpected IP addresses: %#v", x.Resources)
+	}
+	p, err := c.GetPort(ctx, *port)
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	if len(p.AssociatedIxs) != 1 || p.AssociatedIxs[0].ProductUid != *uid {
+		t.Errorf("TestServer_ix: unexpected associated IXs: %#v", p.AssociatedIxs)
+	}
+	if err := c.UpdateIx(ctx, &api.IxUpdateInput{ProductUid: uid, MacAddress: api.String("foo")}); !api.IsValidation(err) {
+		t.Errorf("TestServer_ix: expected a validation error for an invalid MAC address, got %v", err)
+	}
+	if err := c.UpdateIx(ctx, &api.IxUpdateInput{ProductUid: uid, Name: api.String("bar"), Vlan: api.Uint64(uint64(100))}); err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	if ok, err := c.GetPortVlanIdAvailable(ctx, *port, 2); err != nil || !ok {
+		t.Errorf("TestServer_ix: expected VLAN 2 to be available after the update (%v)", err)
+	}
+	if err := c.DeletePort(ctx, *port); err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	x, err = c.GetIx(ctx, *uid)
+	if err != nil {
+		t.Fatalf("TestServer_ix: %v", err)
+	}
+	if x.ProductName != "bar" || x.Vlan != 100 || x.ProvisioningStatus != api.ProductStatusCancelledParent {
+		t.Errorf("TestServer_ix: unexpected IX: %#v", x)
+	}
+}
+
+func TestServer_mve(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	input := &api.MveCreateInput{
+		LocationId:   api.Uint64(uint64(1)),
+		Name:         api.String("mve"),
+		Term:         api.Uint64(uint64(12)),
+		VendorConfig: &api.MveVendorConfigVersa{ImageId: api.Uint64(uint64(1)), ProductSize: api.String(api.MveSizeSmall)},
+	}
+	if _, err := c.CreateMve(ctx, input); !api.IsValidation(err) {
+		t.Errorf("TestServer_mve: expected a validation error for a missing bootstrap configuration, got %v", err)
+	}
+	input.VendorConfig = &api.MveVendorConfigCisco{
+		AdminSshPublicKey: api.String("ssh-rsa AAAA"),
+		ImageId:           api.Uint64(uint64(23)),
+		ProductSize:       api.String(api.MveSizeMedium),
+		SshPublicKey:      api.String("ssh-rsa BBBB"),
+	}
+	input.VnicDescriptions = []string{"inside", "outside"}
+	mve, err := c.CreateMve(ctx, input)
+	if err != nil {
+		t.Fatalf("TestServer_mve: %v", err)
+	}
+	p, err := c.GetMve(ctx, *mve)
+	if err != nil {
+		t.Fatalf("TestServer_mve: %v", err)
+	}
+	if p.ProductType != api.ProductTypeMve || p.Vendor != api.MveVendorCisco || p.MveSize != api.MveSizeMedium || len(p.Vnics) != 2 || p.Vnics[1].Description != "outside" {
+		t.Errorf("TestServer_mve: unexpected MVE: %#v", p)
+	}
+	if len(p.Resources.VirtualMachine) != 1 || p.Resources.VirtualMachine[0].Image.Id != 23 {
+		t.Errorf("TestServer_mve: unexpected virtual machine: %#v", p.Resources.VirtualMachine)
+	}
+	port, err := c.CreatePort(ctx, &api.PortCreateInput{LocationId: api.Uint64(uint64(1)), Name: api.String("port"), Speed: api.Uint64(uint64(1000)), Term: api.Uint64(uint64(1))})
+	if err != nil {
+		t.Fatalf("TestServer_mve: %v", err)
+	}
+	vxcInput := &api.PrivateVxcCreateInput{ProductUidA: mve, ProductUidB: port, Name: api.String("vxc"), RateLimit: api.Uint64(uint64(100)), VnicIndexA: api.Uint64(uint64(2))}
+	if _, err := c.CreatePrivateVxc(ctx, vxcInput); !api.IsValidation(err) {
+		t.Errorf("TestServer_mve: expected a validation error for a missing vNIC, got %v", err)
+	}
+	vxcInput.VnicIndexA = api.Uint64(uint64(1))
+	vxc, err := c.CreatePrivateVxc(ctx, vxcInput)
+	if err != nil {
+		t.Fatalf("TestServer_mve: %v", err)
+	}
+	v, err := c.GetVxc(ctx, *vxc)
+	if err != nil {
+		t.Fatalf("TestServer_mve: %v", err)
+	}
+	if v.AEnd.ProductUid != *mve || v.AEnd.VnicIndex != 1 {
+		t.Errorf("TestServer_mve: unexpected A-End: %#v", v.AEnd)
+	}
+}
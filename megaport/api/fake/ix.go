@@ -0,0 +1,209 @@
+package fake
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+// ix is a connection from a port to an Internet Exchange, which is assigned a
+// pair of peering addresses when it is ordered.
+type ix struct {
+	id                 uint64
+	uid                string
+	name               string
+	productUid         string
+	networkServiceType string
+	asn                uint64
+	macAddress         string
+	rateLimit          uint64
+	vlan               uint64
+	costCentre         string
+	ipv4Address        string
+	ipv6Address        string
+	status             string
+	createDate         uint64
+	liveDate           uint64
+}
+
+type ixOrder struct {
+	Asn                uint64
+	CostCentre         string
+	MacAddress         string
+	NetworkServiceType string
+	ProductName        string
+	RateLimit          uint64
+	Vlan               uint64
+}
+
+func (s *Server) handleInternetExchanges(w http.ResponseWriter, r *http.Request) {
+	locationId, err := strconv.ParseUint(r.URL.Query().Get("locationId"), 10, 64)
+	if err != nil || s.location(locationId) == nil {
+		writeValidationErrors(w, []fieldError{{Field: "locationId", Message: "Invalid location"}})
+		return
+	}
+	l := s.InternetExchanges[locationId]
+	if l == nil {
+		l = []*api.InternetExchange{}
+	}
+	writeResponse(w, http.StatusOK, "", l)
+}
+
+func (s *Server) internetExchange(locationId uint64, name string) *api.InternetExchange {
+	for _, e := range s.InternetExchanges[locationId] {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Server) validateIxOrder(portUid string, o *ixOrder) []fieldError {
+	p := s.liveProduct(portUid)
+	if p == nil || p.productType != api.ProductTypePort {
+		return []fieldError{{Field: "productUid", Message: fmt.Sprintf("Port %s does not exist", portUid)}}
+	}
+	errs := []fieldError{}
+	if o.ProductName == "" {
+		errs = append(errs, fieldError{Field: "productName", Message: "A product name is required"})
+	}
+	if s.internetExchange(p.locationId, o.NetworkServiceType) == nil {
+		errs = append(errs, fieldError{Field: "networkServiceType", Message: fmt.Sprintf("Internet Exchange %q is not available at the location of %s", o.NetworkServiceType, portUid)})
+	}
+	errs = append(errs, validateIxPeering(o.Asn, o.MacAddress)...)
+	if o.RateLimit == 0 || o.RateLimit > p.speed {
+		errs = append(errs, fieldError{Field: "rateLimit", Message: fmt.Sprintf("The rate limit must be between 1 and %d Mbps", p.speed)})
+	}
+	if o.Vlan != 0 {
+		if msg := p.checkVlan(o.Vlan, ""); msg != "" {
+			errs = append(errs, fieldError{Field: "vlan", Message: msg})
+		}
+	}
+	return errs
+}
+
+func validateIxPeering(asn uint64, macAddress string) []fieldError {
+	errs := []fieldError{}
+	if asn == 0 || asn > 4294967295 {
+		errs = append(errs, fieldError{Field: "asn", Message: fmt.Sprintf("Invalid ASN %d", asn)})
+	}
+	if _, err := net.ParseMAC(macAddress); err != nil {
+		errs = append(errs, fieldError{Field: "macAddress", Message: fmt.Sprintf("Invalid MAC address %q", macAddress)})
+	}
+	return errs
+}
+
+func (s *Server) createIx(portUid string, o *ixOrder) *ix {
+	p := s.products[portUid]
+	x := &ix{
+		id:                 s.newId(),
+		uid:                s.newUid(),
+		name:               o.ProductName,
+		productUid:         portUid,
+		networkServiceType: o.NetworkServiceType,
+		asn:                o.Asn,
+		macAddress:         o.MacAddress,
+		rateLimit:          o.RateLimit,
+		vlan:               o.Vlan,
+		costCentre:         o.CostCentre,
+		status:             api.ProductStatusDeployable,
+		createDate:         now(),
+	}
+	if x.vlan == 0 {
+		x.vlan = p.freeVlan()
+	}
+	p.vlans[x.vlan] = x.uid
+	x.ipv4Address = fmt.Sprintf("192.0.2.%d/24", x.id%253+1)
+	x.ipv6Address = fmt.Sprintf("2001:db8::%x/64", x.id)
+	s.ixs[x.uid] = x
+	return x
+}
+
+func (s *Server) updateIx(w http.ResponseWriter, x *ix, u *productUpdate) {
+	p := s.products[x.productUid]
+	if u.RateLimit != nil && (*u.RateLimit == 0 || *u.RateLimit > p.speed) {
+		writeValidationErrors(w, []fieldError{{Field: "rateLimit", Message: fmt.Sprintf("The rate limit must be between 1 and %d Mbps", p.speed)}})
+		return
+	}
+	asn, macAddress := x.asn, x.macAddress
+	if u.Asn != nil {
+		asn = *u.Asn
+	}
+	if u.MacAddress != nil {
+		macAddress = *u.MacAddress
+	}
+	if errs := validateIxPeering(asn, macAddress); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if u.Vlan != nil {
+		if msg := p.checkVlan(*u.Vlan, x.uid); msg != "" {
+			writeResponse(w, http.StatusConflict, msg, nil)
+			return
+		}
+		delete(p.vlans, x.vlan)
+		x.vlan = *u.Vlan
+		p.vlans[x.vlan] = x.uid
+	}
+	if u.Name != nil {
+		x.name = *u.Name
+	}
+	if u.CostCentre != nil {
+		x.costCentre = *u.CostCentre
+	}
+	if u.RateLimit != nil {
+		x.rateLimit = *u.RateLimit
+	}
+	x.asn, x.macAddress = asn, macAddress
+	writeResponse(w, http.StatusOK, "IX updated", s.ixJSON(x))
+}
+
+// cancelIx cancels an IX with the given status and releases its VLAN.
+func (s *Server) cancelIx(x *ix, status string) {
+	x.status = status
+	if p, ok := s.products[x.productUid]; ok && p.vlans[x.vlan] == x.uid {
+		delete(p.vlans, x.vlan)
+	}
+}
+
+func (s *Server) sortedIxs() []*ix {
+	l := make([]*ix, 0, len(s.ixs))
+	for _, x := range s.ixs {
+		l = append(l, x)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].id < l[j].id })
+	return l
+}
+
+func (s *Server) ixJSON(x *ix) map[string]interface{} {
+	var locationId uint64
+	if p, ok := s.products[x.productUid]; ok {
+		locationId = p.locationId
+	}
+	return map[string]interface{}{
+		"productId":          x.id,
+		"productUid":         x.uid,
+		"productName":        x.name,
+		"productType":        api.ProductTypeIx,
+		"provisioningStatus": x.status,
+		"networkServiceType": x.networkServiceType,
+		"asn":                x.asn,
+		"macAddress":         x.macAddress,
+		"rateLimit":          x.rateLimit,
+		"vlan":               x.vlan,
+		"costCentre":         x.costCentre,
+		"locationId":         locationId,
+		"createDate":         x.createDate,
+		"liveDate":           x.liveDate,
+		"resources": map[string]interface{}{
+			"ip_address": []map[string]interface{}{
+				{"address": x.ipv4Address, "resource_name": "ip_address", "resource_type": "ip_address", "version": 4},
+				{"address": x.ipv6Address, "resource_name": "ip_address", "resource_type": "ip_address", "version": 6},
+			},
+		},
+	}
+}
@@ -0,0 +1,244 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+// prefixList is a prefix filter list of an MCR. Its entries are kept in the
+// order they were submitted in, since that is the order they are evaluated in.
+type prefixList struct {
+	id            uint64
+	mcrUid        string
+	description   string
+	addressFamily string
+	entries       []*prefixListEntry
+}
+
+type prefixListEntry struct {
+	Action string `json:"action"`
+	Prefix string `json:"prefix"`
+	Ge     uint64 `json:"ge,omitempty"`
+	Le     uint64 `json:"le,omitempty"`
+}
+
+type prefixListOrder struct {
+	AddressFamily string
+	Description   string
+	Entries       []*prefixListEntry
+}
+
+// prefixListMcr returns the MCR with the given uid, which owns prefix filter
+// lists, or writes an error and returns nil.
+func (s *Server) prefixListMcr(w http.ResponseWriter, uid string) *product {
+	p, ok := s.products[uid]
+	if !ok || isDeleted(p.status) {
+		writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+		return nil
+	}
+	if p.productType != api.ProductTypeMcr2 {
+		writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s is not an MCR", uid)}})
+		return nil
+	}
+	return p
+}
+
+// prefixList returns the prefix filter list of the MCR with the given id, or
+// writes an error and returns nil.
+func (s *Server) prefixList(w http.ResponseWriter, mcrUid, id string) *prefixList {
+	if s.prefixListMcr(w, mcrUid) == nil {
+		return nil
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if l, ok := s.prefixLists[n]; err == nil && ok && l.mcrUid == mcrUid {
+		return l
+	}
+	writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find prefix filter list %s", id), nil)
+	return nil
+}
+
+func validatePrefixListOrder(o *prefixListOrder) []fieldError {
+	errs := []fieldError{}
+	if o.Description == "" {
+		errs = append(errs, fieldError{Field: "description", Message: "A description is required"})
+	}
+	bits := 0
+	switch o.AddressFamily {
+	case api.PrefixFilterListAddressFamilyIPv4:
+		bits = net.IPv4len * 8
+	case api.PrefixFilterListAddressFamilyIPv6:
+		bits = net.IPv6len * 8
+	default:
+		return append(errs, fieldError{Field: "addressFamily", Message: fmt.Sprintf("Invalid address family %q", o.AddressFamily)})
+	}
+	for _, e := range o.Entries {
+		if e.Action != api.PrefixFilterListActionPermit && e.Action != api.PrefixFilterListActionDeny {
+			errs = append(errs, fieldError{Field: "entries.action", Message: fmt.Sprintf("Invalid action %q", e.Action)})
+		}
+		ip, n, err := net.ParseCIDR(e.Prefix)
+		if err != nil || (ip.To4() != nil) != (bits == net.IPv4len*8) {
+			errs = append(errs, fieldError{Field: "entries.prefix", Message: fmt.Sprintf("Invalid %s prefix %q", o.AddressFamily, e.Prefix)})
+			continue
+		}
+		length, _ := n.Mask.Size()
+		ge, le := e.Ge, e.Le
+		if ge == 0 {
+			ge = uint64(length)
+		}
+		if le == 0 {
+			le = uint64(bits)
+		}
+		if ge < uint64(length) || ge > le || le > uint64(bits) {
+			errs = append(errs, fieldError{Field: "entries", Message: fmt.Sprintf("The range of %q must satisfy %d <= ge <= le <= %d", e.Prefix, length, bits)})
+		}
+		// Megaport stores the ranges with their defaults filled in
+		e.Ge, e.Le = ge, le
+	}
+	return errs
+}
+
+// prefixListInUse returns the name of a VXC with a BGP connection that uses
+// the prefix filter list, if there is one.
+func (s *Server) prefixListInUse(l *prefixList) string {
+	for _, v := range s.sortedVxcs() {
+		if isDeleted(v.status) || v.aEnd.productUid != l.mcrUid {
+			continue
+		}
+		for _, id := range prefixListIds(v.aEnd.partnerConfig) {
+			if id == l.id {
+				return v.name
+			}
+		}
+	}
+	return ""
+}
+
+// prefixListIds returns the ids of the prefix filter lists referred to by the
+// BGP connections of a VROUTER partner configuration.
+func prefixListIds(pc map[string]interface{}) []uint64 {
+	ids := []uint64{}
+	interfaces, _ := pc["interfaces"].([]interface{})
+	for _, i := range interfaces {
+		iface, _ := i.(map[string]interface{})
+		connections, _ := iface["bgpConnections"].([]interface{})
+		for _, c := range connections {
+			bgp, _ := c.(map[string]interface{})
+			ids = append(ids, bgpPrefixListIds(bgp)...)
+		}
+	}
+	return ids
+}
+
+// bgpPrefixListIds returns the ids of the prefix filter lists referred to by
+// a BGP connection.
+func bgpPrefixListIds(bgp map[string]interface{}) []uint64 {
+	ids := []uint64{}
+	for _, k := range []string{"importWhitelist", "importBlacklist", "exportWhitelist", "exportBlacklist"} {
+		if v, ok := bgp[k].(float64); ok && v > 0 {
+			ids = append(ids, uint64(v))
+		}
+	}
+	return ids
+}
+
+func (s *Server) handleCreatePrefixList(w http.ResponseWriter, r *http.Request, mcrUid string) {
+	if s.prefixListMcr(w, mcrUid) == nil {
+		return
+	}
+	o := &prefixListOrder{}
+	if err := json.NewDecoder(r.Body).Decode(o); err != nil {
+		writeResponse(w, http.StatusBadRequest, fmt.Sprintf("Could not parse prefix filter list: %v", err), nil)
+		return
+	}
+	if errs := validatePrefixListOrder(o); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	l := &prefixList{
+		id:            s.newId(),
+		mcrUid:        mcrUid,
+		description:   o.Description,
+		addressFamily: o.AddressFamily,
+		entries:       append([]*prefixListEntry{}, o.Entries...),
+	}
+	s.prefixLists[l.id] = l
+	writeResponse(w, http.StatusOK, "Prefix filter list created", prefixListJSON(l, false))
+}
+
+func (s *Server) handleGetPrefixList(w http.ResponseWriter, mcrUid, id string) {
+	if l := s.prefixList(w, mcrUid, id); l != nil {
+		writeResponse(w, http.StatusOK, "", prefixListJSON(l, true))
+	}
+}
+
+func (s *Server) handleListPrefixLists(w http.ResponseWriter, mcrUid string) {
+	if s.prefixListMcr(w, mcrUid) == nil {
+		return
+	}
+	l := []*prefixList{}
+	for _, pl := range s.prefixLists {
+		if pl.mcrUid == mcrUid {
+			l = append(l, pl)
+		}
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].id < l[j].id })
+	data := make([]map[string]interface{}, len(l))
+	for i, pl := range l {
+		data[i] = prefixListJSON(pl, false)
+	}
+	writeResponse(w, http.StatusOK, "", data)
+}
+
+func (s *Server) handleUpdatePrefixList(w http.ResponseWriter, r *http.Request, mcrUid, id string) {
+	l := s.prefixList(w, mcrUid, id)
+	if l == nil {
+		return
+	}
+	o := &prefixListOrder{}
+	if err := json.NewDecoder(r.Body).Decode(o); err != nil {
+		writeResponse(w, http.StatusBadRequest, fmt.Sprintf("Could not parse prefix filter list: %v", err), nil)
+		return
+	}
+	if errs := validatePrefixListOrder(o); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if o.AddressFamily != l.addressFamily {
+		writeValidationErrors(w, []fieldError{{Field: "addressFamily", Message: "The address family of a prefix filter list cannot be changed"}})
+		return
+	}
+	l.description = o.Description
+	l.entries = append([]*prefixListEntry{}, o.Entries...)
+	writeResponse(w, http.StatusOK, "Prefix filter list updated", prefixListJSON(l, true))
+}
+
+func (s *Server) handleDeletePrefixList(w http.ResponseWriter, mcrUid, id string) {
+	l := s.prefixList(w, mcrUid, id)
+	if l == nil {
+		return
+	}
+	if name := s.prefixListInUse(l); name != "" {
+		writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Prefix filter list %d is in use by VXC %q", l.id, name)}})
+		return
+	}
+	delete(s.prefixLists, l.id)
+	writeResponse(w, http.StatusOK, "Prefix filter list deleted", nil)
+}
+
+func prefixListJSON(l *prefixList, entries bool) map[string]interface{} {
+	d := map[string]interface{}{
+		"id":            l.id,
+		"description":   l.description,
+		"addressFamily": l.addressFamily,
+	}
+	if entries {
+		d["entries"] = l.entries
+	}
+	return d
+}
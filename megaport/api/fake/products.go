@@ -0,0 +1,1416 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+const (
+	DefaultMcrAsn = 133937
+
+	awsAmazonAsn = 64512
+	vlanMin      = 2
+	vlanMax      = 4093
+	lagPortsMax  = 8
+	lagSpeedMin  = 10000
+	mveVnicsMax  = 5
+)
+
+// mveSizes maps the sizes of MVEs to their CPU count and the maximum rate of
+// their VXCs.
+var mveSizes = map[string]struct{ cpuCount, speed uint64 }{
+	api.MveSizeSmall:  {2, 500},
+	api.MveSizeMedium: {4, 1000},
+	api.MveSizeLarge:  {8, 5000},
+}
+
+// mveVendorFields lists the bootstrap configuration required by each vendor
+// of MVE images.
+var mveVendorFields = map[string][]string{
+	api.MveVendorCisco:    {"adminSshPublicKey", "sshPublicKey"},
+	api.MveVendorFortinet: {"adminSshPublicKey", "sshPublicKey", "licenseData"},
+	api.MveVendorPaloAlto: {"adminSshPublicKey", "sshPublicKey", "adminPasswordHash"},
+	api.MveVendorVersa:    {"directorAddress", "controllerAddress", "localAuth", "remoteAuth", "serialNumber"},
+}
+
+type product struct {
+	id                    uint64
+	uid                   string
+	name                  string
+	productType           string
+	locationId            uint64
+	speed                 uint64
+	term                  uint64
+	costCentre            string
+	marketplaceVisibility bool
+	asn                   uint64
+	status                string
+	createDate            uint64
+	liveDate              uint64
+	// vlans maps each VLAN in use on the product to the uid of the VXC or
+	// IX that uses it
+	vlans map[uint64]string
+	// lagId is set for the ports of a LAG, which are cancelled along with
+	// their primary port
+	lagId      uint64
+	lagPrimary bool
+	// terminateDate is set for products that are cancelled at the end of
+	// their term, until when they are pending cancellation
+	terminateDate uint64
+	// vendorConfig and vnics are set for MVEs
+	vendorConfig map[string]interface{}
+	vnics        []string
+}
+
+type vxc struct {
+	id            uint64
+	uid           string
+	name          string
+	costCentre    string
+	rateLimit     uint64
+	aEnd          vxcEnd
+	bEnd          vxcEnd
+	partnerConfig map[string]interface{}
+	status        string
+	createDate    uint64
+	liveDate      uint64
+}
+
+type vxcEnd struct {
+	productUid string
+	vlan       uint64
+	vnicIndex  uint64
+	// partnerConfig is the configuration of the end when it is an MCR
+	partnerConfig map[string]interface{}
+}
+
+// order is a single item of a network design, which is either a port, MCR or
+// MVE order, or a VXC or IX order when ProductUid refers to the A-End product.
+type order struct {
+	ProductName           string
+	ProductType           string
+	LocationId            uint64
+	PortSpeed             uint64
+	Term                  uint64
+	CostCentre            string
+	Virtual               bool
+	MarketplaceVisibility bool
+	Config                struct {
+		McrAsn uint64
+	}
+	LagId        uint64
+	LagPortCount uint64
+	VendorConfig map[string]interface{}
+	Vnics        []struct {
+		Description string
+	}
+	ProductUid     string
+	AssociatedVxcs []*vxcOrder
+	AssociatedIxs  []*ixOrder
+}
+
+type vxcOrder struct {
+	ProductName    string
+	RateLimit      uint64
+	CostCentre     string
+	AEnd           *vxcOrderEnd
+	BEnd           *vxcOrderEnd
+	PartnerConfigs map[string]interface{}
+}
+
+type vxcOrderEnd struct {
+	PartnerConfig map[string]interface{}
+	ProductUid    string
+	Vlan          uint64
+	VnicIndex     uint64
+}
+
+type productUpdate struct {
+	Name                  *string
+	CostCentre            *string
+	MarketplaceVisibility *bool
+	RateLimit             *uint64
+	AEndVlan              *uint64
+	BEndVlan              *uint64
+	AEndConfig            map[string]interface{}
+	BEndConfig            map[string]interface{}
+	Asn                   *uint64
+	MacAddress            *string
+	Vlan                  *uint64
+}
+
+func isDeleted(status string) bool {
+	return status == api.ProductStatusCancelled ||
+		status == api.ProductStatusCancelledParent ||
+		status == api.ProductStatusDecommissioned
+}
+
+// advance moves a provisioning status one step through the lifecycle.
+func advance(status string) string {
+	switch status {
+	case api.ProductStatusDeployable:
+		return api.ProductStatusConfigured
+	case api.ProductStatusConfigured:
+		return api.ProductStatusLive
+	case api.ProductStatusCancelled, api.ProductStatusCancelledParent:
+		return api.ProductStatusDecommissioned
+	}
+	return status
+}
+
+// cancellationPending reports whether the product has been cancelled at the
+// end of its term
+func (p *product) cancellationPending() bool {
+	return p.status == api.ProductStatusCancelled && p.terminateDate > now()
+}
+
+// termEndDate returns the end of the current term of the product, which
+// renews automatically.
+func (p *product) termEndDate() uint64 {
+	term := int(p.term)
+	if term == 0 {
+		term = 1
+	}
+	end := time.Unix(0, int64(p.createDate)*int64(time.Millisecond)).AddDate(0, term, 0)
+	for !end.After(time.Now()) {
+		end = end.AddDate(0, term, 0)
+	}
+	return uint64(end.UnixNano() / int64(time.Millisecond))
+}
+
+func now() uint64 {
+	return uint64(time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+func containsUint(l []uint64, v uint64) bool {
+	for _, e := range l {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(m map[string]interface{}, k string) string {
+	v, _ := m[k].(string)
+	return v
+}
+
+func (s *Server) location(id uint64) *api.Location {
+	for _, l := range s.Locations {
+		if l.Id == id {
+			return l
+		}
+	}
+	return nil
+}
+
+func (s *Server) partnerPort(uid string) *api.Megaport {
+	for _, p := range s.PartnerPorts {
+		if p.ProductUid == uid {
+			return p
+		}
+	}
+	return nil
+}
+
+// cloudPort returns the GCP, Azure or Oracle port with the given uid, along with its
+// connect type.
+func (s *Server) cloudPort(uid string) (*api.MegaportCloud, string) {
+	for _, p := range s.GcpPorts {
+		if p.ProductUid == uid {
+			return p, api.VxcConnectTypeGoogle
+		}
+	}
+	for _, p := range s.AzurePorts {
+		if p.ProductUid == uid {
+			return p, api.VxcConnectTypeAzure
+		}
+	}
+	for _, p := range s.OraclePorts {
+		if p.ProductUid == uid {
+			return p, api.VxcConnectTypeOracle
+		}
+	}
+	return nil, ""
+}
+
+func (s *Server) liveProduct(uid string) *product {
+	if p, ok := s.products[uid]; ok && !isDeleted(p.status) {
+		return p
+	}
+	return nil
+}
+
+func (p *product) checkVlan(vlan uint64, vxcUid string) string {
+	if vlan < vlanMin || vlan > vlanMax {
+		return fmt.Sprintf("VLAN %d is outside of the valid range %d-%d", vlan, vlanMin, vlanMax)
+	}
+	if owner, ok := p.vlans[vlan]; ok && owner != vxcUid {
+		return fmt.Sprintf("VLAN %d is already in use on %s", vlan, p.uid)
+	}
+	return ""
+}
+
+func (p *product) freeVlan() uint64 {
+	for v := uint64(vlanMin); v <= vlanMax; v++ {
+		if _, ok := p.vlans[v]; !ok {
+			return v
+		}
+	}
+	return 0
+}
+
+func (s *Server) handleNetworkDesign(w http.ResponseWriter, r *http.Request, buy bool) {
+	orders := []*order{}
+	if err := json.NewDecoder(r.Body).Decode(&orders); err != nil {
+		writeResponse(w, http.StatusBadRequest, fmt.Sprintf("Could not parse network design: %v", err), nil)
+		return
+	}
+	if len(orders) == 0 {
+		writeValidationErrors(w, []fieldError{{Message: "The network design is empty"}})
+		return
+	}
+	errs := []fieldError{}
+	for _, o := range orders {
+		errs = append(errs, s.validateOrder(o)...)
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if !buy {
+		writeResponse(w, http.StatusOK, "Validation passed", nil)
+		return
+	}
+	data := []map[string]interface{}{}
+	for _, o := range orders {
+		if o.ProductUid == "" {
+			for _, p := range s.createProducts(o) {
+				data = append(data, map[string]interface{}{
+					"productType":         p.productType,
+					"technicalServiceUid": p.uid,
+				})
+			}
+			continue
+		}
+		for _, vo := range o.AssociatedVxcs {
+			v := s.createVxc(o.ProductUid, vo)
+			data = append(data, map[string]interface{}{
+				"productType":             api.ProductTypeVxc,
+				"vxcJTechnicalServiceUid": v.uid,
+			})
+		}
+		for _, xo := range o.AssociatedIxs {
+			x := s.createIx(o.ProductUid, xo)
+			data = append(data, map[string]interface{}{
+				"productType":         api.ProductTypeIx,
+				"technicalServiceUid": x.uid,
+			})
+		}
+	}
+	writeResponse(w, http.StatusOK, "Your order has been placed", data)
+}
+
+func (s *Server) validateOrder(o *order) []fieldError {
+	if o.ProductUid == "" {
+		return s.validateProductOrder(o)
+	}
+	if len(o.AssociatedVxcs) == 0 && len(o.AssociatedIxs) == 0 {
+		return []fieldError{{Field: "associatedVxcs", Message: "At least one VXC or IX must be ordered"}}
+	}
+	errs := []fieldError{}
+	for _, vo := range o.AssociatedVxcs {
+		errs = append(errs, s.validateVxcOrder(o.ProductUid, vo)...)
+	}
+	for _, xo := range o.AssociatedIxs {
+		errs = append(errs, s.validateIxOrder(o.ProductUid, xo)...)
+	}
+	return errs
+}
+
+func (s *Server) validateProductOrder(o *order) []fieldError {
+	errs := []fieldError{}
+	if o.ProductName == "" {
+		errs = append(errs, fieldError{Field: "productName", Message: "A product name is required"})
+	}
+	loc := s.location(o.LocationId)
+	if loc == nil {
+		return append(errs, fieldError{Field: "locationId", Message: fmt.Sprintf("Location %d does not exist", o.LocationId)})
+	}
+	switch {
+	case o.ProductType == api.ProductTypePort && !o.Virtual:
+		if o.PortSpeed%1000 != 0 || !containsUint(loc.Products.Megaport, o.PortSpeed/1000) {
+			errs = append(errs, fieldError{Field: "portSpeed", Message: fmt.Sprintf("Port speed %d is not available at %s", o.PortSpeed, loc.Name)})
+		}
+		if !containsUint([]uint64{1, 12, 24, 36}, o.Term) {
+			errs = append(errs, fieldError{Field: "term", Message: "The term must be one of 1, 12, 24 or 36 months"})
+		}
+		errs = append(errs, s.validateLagOrder(o)...)
+	case o.ProductType == api.ProductTypeMcr2:
+		if !loc.Products.Mcr || !containsUint(loc.Products.Mcr2, o.PortSpeed) {
+			errs = append(errs, fieldError{Field: "portSpeed", Message: fmt.Sprintf("MCR speed %d is not available at %s", o.PortSpeed, loc.Name)})
+		}
+	case o.ProductType == api.ProductTypeMve:
+		if !containsUint([]uint64{1, 12, 24, 36}, o.Term) {
+			errs = append(errs, fieldError{Field: "term", Message: "The term must be one of 1, 12, 24 or 36 months"})
+		}
+		errs = append(errs, validateMveOrder(o)...)
+	default:
+		errs = append(errs, fieldError{Field: "productType", Message: fmt.Sprintf("Product type %q is not supported", o.ProductType)})
+	}
+	return errs
+}
+
+// validateLagOrder checks the LAG fields of a port order. A new LAG is ordered
+// with LagPortCount ports, and ports are added to an existing LAG by setting
+// LagId, in which case the order must match the existing ports.
+func (s *Server) validateLagOrder(o *order) []fieldError {
+	if o.LagPortCount == 0 {
+		if o.LagId != 0 {
+			return []fieldError{{Field: "lagPortCount", Message: "The number of ports to add to the LAG is required"}}
+		}
+		return nil
+	}
+	errs := []fieldError{}
+	if o.PortSpeed < lagSpeedMin {
+		errs = append(errs, fieldError{Field: "portSpeed", Message: fmt.Sprintf("LAG ports must have a speed of at least %d Mbps", lagSpeedMin)})
+	}
+	count := o.LagPortCount
+	if o.LagId != 0 {
+		lag := s.lagPorts(o.LagId)
+		if len(lag) == 0 {
+			return append(errs, fieldError{Field: "lagId", Message: fmt.Sprintf("LAG %d does not exist", o.LagId)})
+		}
+		if lag[0].locationId != o.LocationId || lag[0].speed != o.PortSpeed {
+			errs = append(errs, fieldError{Field: "lagId", Message: "Ports added to a LAG must match the location and speed of the LAG"})
+		}
+		count += uint64(len(lag))
+	}
+	if count > lagPortsMax {
+		errs = append(errs, fieldError{Field: "lagPortCount", Message: fmt.Sprintf("A LAG can have at most %d ports", lagPortsMax)})
+	}
+	return errs
+}
+
+func validateMveOrder(o *order) []fieldError {
+	errs := []fieldError{}
+	vendor := stringValue(o.VendorConfig, "vendor")
+	fields, ok := mveVendorFields[vendor]
+	if !ok {
+		return append(errs, fieldError{Field: "vendorConfig.vendor", Message: fmt.Sprintf("Vendor %q is not supported", vendor)})
+	}
+	if id, _ := o.VendorConfig["imageId"].(float64); id <= 0 {
+		errs = append(errs, fieldError{Field: "vendorConfig.imageId", Message: "An image id is required"})
+	}
+	if _, ok := mveSizes[stringValue(o.VendorConfig, "productSize")]; !ok {
+		errs = append(errs, fieldError{Field: "vendorConfig.productSize", Message: fmt.Sprintf("Invalid MVE size %q", stringValue(o.VendorConfig, "productSize"))})
+	}
+	for _, f := range fields {
+		if stringValue(o.VendorConfig, f) == "" {
+			errs = append(errs, fieldError{Field: "vendorConfig." + f, Message: fmt.Sprintf("%s is required for %s images", f, vendor)})
+		}
+	}
+	if len(o.Vnics) > mveVnicsMax {
+		errs = append(errs, fieldError{Field: "vnics", Message: fmt.Sprintf("An MVE can have at most %d vNICs", mveVnicsMax)})
+	}
+	return errs
+}
+
+// lagPorts returns the ports of a LAG that have not been cancelled, starting
+// with the primary port.
+func (s *Server) lagPorts(lagId uint64) []*product {
+	l := []*product{}
+	for _, p := range s.products {
+		if p.lagId == lagId && !isDeleted(p.status) {
+			l = append(l, p)
+		}
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].lagPrimary || (!l[j].lagPrimary && l[i].id < l[j].id) })
+	return l
+}
+
+func (s *Server) validateVxcOrder(aUid string, vo *vxcOrder) []fieldError {
+	a := s.liveProduct(aUid)
+	if a == nil {
+		return []fieldError{{Field: "productUid", Message: fmt.Sprintf("Product %s does not exist", aUid)}}
+	}
+	errs := []fieldError{}
+	if vo.ProductName == "" {
+		errs = append(errs, fieldError{Field: "productName", Message: "A product name is required"})
+	}
+	if vo.RateLimit == 0 || vo.RateLimit > a.speed {
+		errs = append(errs, fieldError{Field: "rateLimit", Message: fmt.Sprintf("The rate limit must be between 1 and %d Mbps", a.speed)})
+	}
+	if vo.AEnd != nil && vo.AEnd.Vlan != 0 {
+		if msg := a.checkVlan(vo.AEnd.Vlan, ""); msg != "" {
+			errs = append(errs, fieldError{Field: "aEnd.vlan", Message: msg})
+		}
+	}
+	if vo.AEnd != nil && vo.AEnd.PartnerConfig != nil {
+		errs = append(errs, s.validateVRouterConfig(a, vo.AEnd.PartnerConfig, "aEnd.partnerConfig")...)
+	}
+	if vo.AEnd != nil && vo.AEnd.VnicIndex != 0 && vo.AEnd.VnicIndex >= uint64(len(a.vnics)) {
+		errs = append(errs, fieldError{Field: "aEnd.vNicIndex", Message: fmt.Sprintf("vNIC %d does not exist on %s", vo.AEnd.VnicIndex, aUid)})
+	}
+	if vo.BEnd == nil || vo.BEnd.ProductUid == "" {
+		return append(errs, fieldError{Field: "bEnd.productUid", Message: "A B-End product is required"})
+	}
+	if b := s.liveProduct(vo.BEnd.ProductUid); b != nil {
+		if vo.BEnd.Vlan != 0 {
+			if msg := b.checkVlan(vo.BEnd.Vlan, ""); msg != "" {
+				errs = append(errs, fieldError{Field: "bEnd.vlan", Message: msg})
+			}
+		}
+		if vo.PartnerConfigs != nil {
+			errs = append(errs, fieldError{Field: "partnerConfigs", Message: "Partner configuration is only supported for VXCs to partner ports"})
+		}
+		return errs
+	}
+	if p := s.partnerPort(vo.BEnd.ProductUid); p != nil {
+		if !p.VxcPermitted {
+			errs = append(errs, fieldError{Field: "bEnd.productUid", Message: fmt.Sprintf("VXCs to %s are not permitted", p.Title)})
+		}
+		return append(errs, s.validatePartnerConfig(p.ConnectType, vo.RateLimit, vo.PartnerConfigs, "")...)
+	}
+	if p, connectType := s.cloudPort(vo.BEnd.ProductUid); p != nil {
+		return append(errs, s.validatePartnerConfig(connectType, vo.RateLimit, vo.PartnerConfigs, "")...)
+	}
+	return append(errs, fieldError{Field: "bEnd.productUid", Message: fmt.Sprintf("Product %s does not exist", vo.BEnd.ProductUid)})
+}
+
+// validatePartnerConfig checks the partner configuration of a VXC to a port
+// of the given connect type. vxcUid is set when validating an update to an
+// existing VXC.
+func (s *Server) validatePartnerConfig(connectType string, rateLimit uint64, pc map[string]interface{}, vxcUid string) []fieldError {
+	switch connectType {
+	case api.VxcConnectTypeAws:
+		if pc == nil || stringValue(pc, "connectType") != api.VxcConnectTypeAws {
+			return []fieldError{{Field: "partnerConfigs", Message: "An AWS partner configuration is required"}}
+		}
+		errs := []fieldError{}
+		if stringValue(pc, "ownerAccount") == "" {
+			errs = append(errs, fieldError{Field: "partnerConfigs.ownerAccount", Message: "An AWS account id is required"})
+		}
+		if asn, _ := pc["asn"].(float64); asn <= 0 {
+			errs = append(errs, fieldError{Field: "partnerConfigs.asn", Message: "A customer ASN is required"})
+		}
+		if t := stringValue(pc, "type"); t != "private" && t != "public" {
+			errs = append(errs, fieldError{Field: "partnerConfigs.type", Message: fmt.Sprintf("Invalid connection type %q", t)})
+		}
+		return errs
+	case api.VxcConnectTypeGoogle:
+		if pc == nil || stringValue(pc, "connectType") != api.VxcConnectTypeGoogle {
+			return []fieldError{{Field: "partnerConfigs", Message: "A Google partner configuration is required"}}
+		}
+		errs := []fieldError{}
+		pk := stringValue(pc, "pairingKey")
+		if !gcpPairingKeyRegexp.MatchString(pk) {
+			errs = append(errs, fieldError{Field: "partnerConfigs.pairingKey", Message: "Invalid pairing key"})
+		}
+		for _, v := range s.vxcs {
+			if v.uid != vxcUid && !isDeleted(v.status) && stringValue(v.partnerConfig, "pairingKey") == pk {
+				errs = append(errs, fieldError{Field: "partnerConfigs.pairingKey", Message: "The pairing key is already in use"})
+				break
+			}
+		}
+		if !containsUint(s.GcpBandwidths, rateLimit) {
+			errs = append(errs, fieldError{Field: "rateLimit", Message: fmt.Sprintf("The rate limit %d is not supported by Google Cloud", rateLimit)})
+		}
+		return errs
+	case api.VxcConnectTypeAzure:
+		if pc == nil || stringValue(pc, "connectType") != api.VxcConnectTypeAzure {
+			return []fieldError{{Field: "partnerConfigs", Message: "An Azure partner configuration is required"}}
+		}
+		errs := []fieldError{}
+		sk := stringValue(pc, "serviceKey")
+		if !azureServiceKeyRegexp.MatchString(sk) {
+			errs = append(errs, fieldError{Field: "partnerConfigs.serviceKey", Message: "Invalid service key"})
+		}
+		// A circuit has a primary and a secondary connection
+		n := 0
+		for _, v := range s.vxcs {
+			if v.uid != vxcUid && !isDeleted(v.status) && stringValue(v.partnerConfig, "serviceKey") == sk {
+				n++
+			}
+		}
+		if n >= 2 {
+			errs = append(errs, fieldError{Field: "partnerConfigs.serviceKey", Message: "The service key is already in use by two VXCs"})
+		}
+		if rateLimit > s.AzureBandwidth {
+			errs = append(errs, fieldError{Field: "rateLimit", Message: fmt.Sprintf("The rate limit must be at most %d Mbps, the bandwidth of the circuit", s.AzureBandwidth)})
+		}
+		return append(errs, validateAzurePeers(pc["peers"])...)
+	case api.VxcConnectTypeOracle:
+		if pc == nil || stringValue(pc, "connectType") != api.VxcConnectTypeOracle {
+			return []fieldError{{Field: "partnerConfigs", Message: "An Oracle partner configuration is required"}}
+		}
+		errs := []fieldError{}
+		id := stringValue(pc, "virtualCircuitId")
+		if !oracleOcidRegexp.MatchString(id) {
+			errs = append(errs, fieldError{Field: "partnerConfigs.virtualCircuitId", Message: "Invalid virtual circuit id"})
+		}
+		for _, v := range s.vxcs {
+			if v.uid != vxcUid && !isDeleted(v.status) && stringValue(v.partnerConfig, "virtualCircuitId") == id {
+				errs = append(errs, fieldError{Field: "partnerConfigs.virtualCircuitId", Message: "The virtual circuit is already in use"})
+				break
+			}
+		}
+		if !containsUint(s.OracleBandwidths, rateLimit) {
+			errs = append(errs, fieldError{Field: "rateLimit", Message: fmt.Sprintf("The rate limit %d is not supported by Oracle Cloud", rateLimit)})
+		}
+		return errs
+	default:
+		if pc != nil {
+			return []fieldError{{Field: "partnerConfigs", Message: "Partner configuration is not supported for this port"}}
+		}
+		return nil
+	}
+}
+
+func validateAzurePeers(v interface{}) []fieldError {
+	if v == nil {
+		return nil
+	}
+	peers, ok := v.([]interface{})
+	if !ok {
+		return []fieldError{{Field: "partnerConfigs.peers", Message: "The peers must be a list"}}
+	}
+	errs := []fieldError{}
+	seen := map[string]bool{}
+	for _, p := range peers {
+		peer, ok := p.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fieldError{Field: "partnerConfigs.peers", Message: "Invalid peer"})
+			continue
+		}
+		t := stringValue(peer, "type")
+		if t != "private" && t != "microsoft" {
+			errs = append(errs, fieldError{Field: "partnerConfigs.peers.type", Message: fmt.Sprintf("Invalid peering type %q", t)})
+		} else if seen[t] {
+			errs = append(errs, fieldError{Field: "partnerConfigs.peers.type", Message: fmt.Sprintf("There can only be one %s peering", t)})
+		}
+		seen[t] = true
+		for _, k := range []string{"primary_subnet", "secondary_subnet"} {
+			if _, _, err := net.ParseCIDR(stringValue(peer, k)); err != nil {
+				errs = append(errs, fieldError{Field: "partnerConfigs.peers." + k, Message: fmt.Sprintf("Invalid subnet %q", stringValue(peer, k))})
+			}
+		}
+		if vlan, _ := peer["vlan"].(float64); vlan < vlanMin || vlan > vlanMax {
+			errs = append(errs, fieldError{Field: "partnerConfigs.peers.vlan", Message: fmt.Sprintf("The VLAN must be between %d and %d", vlanMin, vlanMax)})
+		}
+		if t == "microsoft" && stringValue(peer, "prefixes") == "" {
+			errs = append(errs, fieldError{Field: "partnerConfigs.peers.prefixes", Message: "Microsoft peering requires the prefixes to advertise"})
+		}
+	}
+	return errs
+}
+
+// validateVRouterConfig checks the partner configuration of the MCR end of a
+// VXC. The local address of each BGP connection must be one of the addresses
+// of its interface and the peer address must be in the same subnet. The
+// prefix filter lists they refer to must belong to the MCR.
+func (s *Server) validateVRouterConfig(p *product, pc map[string]interface{}, field string) []fieldError {
+	if p.productType != api.ProductTypeMcr2 {
+		return []fieldError{{Field: field, Message: "Partner configuration of the A-End is only supported for MCRs"}}
+	}
+	if stringValue(pc, "connectType") != api.VxcConnectTypeVRouter {
+		return []fieldError{{Field: field, Message: "A VROUTER partner configuration is required"}}
+	}
+	interfaces, ok := pc["interfaces"].([]interface{})
+	if !ok {
+		return []fieldError{{Field: field + ".interfaces", Message: "The interfaces must be a list"}}
+	}
+	errs := []fieldError{}
+	for _, i := range interfaces {
+		iface, ok := i.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fieldError{Field: field + ".interfaces", Message: "Invalid interface"})
+			continue
+		}
+		ips := map[string]*net.IPNet{}
+		addresses, _ := iface["ipAddresses"].([]interface{})
+		if len(addresses) == 0 {
+			errs = append(errs, fieldError{Field: field + ".interfaces.ipAddresses", Message: "At least one IP address is required"})
+		}
+		for _, a := range addresses {
+			a, _ := a.(string)
+			ip, n, err := net.ParseCIDR(a)
+			if err != nil {
+				errs = append(errs, fieldError{Field: field + ".interfaces.ipAddresses", Message: fmt.Sprintf("Invalid IP address %q", a)})
+				continue
+			}
+			ips[ip.String()] = n
+		}
+		nat, _ := iface["natIpAddresses"].([]interface{})
+		for _, a := range nat {
+			if a, _ := a.(string); net.ParseIP(a) == nil {
+				errs = append(errs, fieldError{Field: field + ".interfaces.natIpAddresses", Message: fmt.Sprintf("Invalid IP address %q", a)})
+			}
+		}
+		if bfd, ok := iface["bfd"].(map[string]interface{}); ok {
+			for _, k := range []string{"txInterval", "rxInterval"} {
+				if v, _ := bfd[k].(float64); v < 300 || v > 9000 {
+					errs = append(errs, fieldError{Field: field + ".interfaces.bfd." + k, Message: "The interval must be between 300 and 9000 ms"})
+				}
+			}
+			if v, _ := bfd["multiplier"].(float64); v < 3 || v > 20 {
+				errs = append(errs, fieldError{Field: field + ".interfaces.bfd.multiplier", Message: "The multiplier must be between 3 and 20"})
+			}
+		}
+		connections, _ := iface["bgpConnections"].([]interface{})
+		for _, c := range connections {
+			bgp, ok := c.(map[string]interface{})
+			if !ok {
+				errs = append(errs, fieldError{Field: field + ".interfaces.bgpConnections", Message: "Invalid BGP connection"})
+				continue
+			}
+			for _, id := range bgpPrefixListIds(bgp) {
+				if l, ok := s.prefixLists[id]; !ok || l.mcrUid != p.uid {
+					errs = append(errs, fieldError{Field: field + ".interfaces.bgpConnections", Message: fmt.Sprintf("Prefix filter list %d does not exist on the MCR", id)})
+				}
+			}
+			if asn, _ := bgp["peerAsn"].(float64); asn <= 0 {
+				errs = append(errs, fieldError{Field: field + ".interfaces.bgpConnections.peerAsn", Message: "A peer ASN is required"})
+			}
+			local := net.ParseIP(stringValue(bgp, "localIpAddress"))
+			n, ok := ips[local.String()]
+			if local == nil || !ok {
+				errs = append(errs, fieldError{Field: field + ".interfaces.bgpConnections.localIpAddress", Message: fmt.Sprintf("%q is not an address of the interface", stringValue(bgp, "localIpAddress"))})
+				continue
+			}
+			if peer := net.ParseIP(stringValue(bgp, "peerIpAddress")); peer == nil || peer.Equal(local) || !n.Contains(peer) {
+				errs = append(errs, fieldError{Field: field + ".interfaces.bgpConnections.peerIpAddress", Message: fmt.Sprintf("%q is not a peer address in %s", stringValue(bgp, "peerIpAddress"), n)})
+			}
+		}
+	}
+	return errs
+}
+
+// createProducts creates the products of an order, which are several ports
+// for LAG orders and a single product otherwise.
+func (s *Server) createProducts(o *order) []*product {
+	if o.LagPortCount == 0 {
+		return []*product{s.createProduct(o)}
+	}
+	lagId := o.LagId
+	if lagId == 0 {
+		lagId = s.newId()
+	}
+	l := make([]*product, o.LagPortCount)
+	for i := range l {
+		l[i] = s.createProduct(o)
+		l[i].lagId = lagId
+		l[i].lagPrimary = o.LagId == 0 && i == 0
+	}
+	return l
+}
+
+func (s *Server) createProduct(o *order) *product {
+	p := &product{
+		id:                    s.newId(),
+		uid:                   s.newUid(),
+		name:                  o.ProductName,
+		productType:           o.ProductType,
+		locationId:            o.LocationId,
+		speed:                 o.PortSpeed,
+		term:                  o.Term,
+		costCentre:            o.CostCentre,
+		marketplaceVisibility: o.MarketplaceVisibility,
+		status:                api.ProductStatusDeployable,
+		createDate:            now(),
+		vlans:                 map[uint64]string{},
+	}
+	if p.productType == api.ProductTypeMve {
+		p.vendorConfig = o.VendorConfig
+		p.speed = mveSizes[stringValue(o.VendorConfig, "productSize")].speed
+		for _, v := range o.Vnics {
+			p.vnics = append(p.vnics, v.Description)
+		}
+		if len(p.vnics) == 0 {
+			p.vnics = []string{"Data Plane"}
+		}
+	}
+	if p.productType == api.ProductTypeMcr2 {
+		p.term = 1
+		p.marketplaceVisibility = false
+		p.asn = o.Config.McrAsn
+		if p.asn == 0 {
+			p.asn = DefaultMcrAsn
+		}
+	}
+	s.products[p.uid] = p
+	return p
+}
+
+func (s *Server) createVxc(aUid string, vo *vxcOrder) *vxc {
+	a := s.products[aUid]
+	v := &vxc{
+		id:         s.newId(),
+		uid:        s.newUid(),
+		name:       vo.ProductName,
+		costCentre: vo.CostCentre,
+		rateLimit:  vo.RateLimit,
+		status:     api.ProductStatusDeployable,
+		createDate: now(),
+	}
+	v.aEnd = vxcEnd{productUid: aUid}
+	if vo.AEnd != nil {
+		v.aEnd.vlan = vo.AEnd.Vlan
+		v.aEnd.vnicIndex = vo.AEnd.VnicIndex
+		v.aEnd.partnerConfig = vo.AEnd.PartnerConfig
+	}
+	if v.aEnd.vlan == 0 {
+		v.aEnd.vlan = a.freeVlan()
+	}
+	a.vlans[v.aEnd.vlan] = v.uid
+	v.bEnd = vxcEnd{productUid: vo.BEnd.ProductUid, vlan: vo.BEnd.Vlan}
+	if b := s.liveProduct(v.bEnd.productUid); b != nil {
+		if v.bEnd.vlan == 0 {
+			v.bEnd.vlan = b.freeVlan()
+		}
+		b.vlans[v.bEnd.vlan] = v.uid
+	}
+	if vo.PartnerConfigs != nil {
+		v.partnerConfig = s.newPartnerConfig(v, vo.PartnerConfigs)
+	}
+	s.vxcs[v.uid] = v
+	return v
+}
+
+// newPartnerConfig fills in the values that the API computes when they are
+// not part of the order.
+func (s *Server) newPartnerConfig(v *vxc, pc map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{}
+	for k, val := range pc {
+		c[k] = val
+	}
+	if stringValue(c, "connectType") == api.VxcConnectTypeAws {
+		c["amazonAsn"] = awsAmazonAsn
+		if stringValue(c, "name") == "" {
+			c["name"] = v.name
+		}
+		if stringValue(c, "authKey") == "" {
+			c["authKey"] = strings.Replace(uuid.New().String(), "-", "", -1)[:16]
+		}
+		if stringValue(c, "amazonIpAddress") == "" && stringValue(c, "customerIpAddress") == "" {
+			n := s.newId()
+			c["amazonIpAddress"] = fmt.Sprintf("169.254.%d.%d/30", n/64%256, n%64*4+1)
+			c["customerIpAddress"] = fmt.Sprintf("169.254.%d.%d/30", n/64%256, n%64*4+2)
+		}
+	}
+	return c
+}
+
+func (s *Server) releaseVlans(v *vxc) {
+	for _, e := range []vxcEnd{v.aEnd, v.bEnd} {
+		if p, ok := s.products[e.productUid]; ok && p.vlans[e.vlan] == v.uid {
+			delete(p.vlans, e.vlan)
+		}
+	}
+}
+
+func (s *Server) sortedVxcs() []*vxc {
+	l := make([]*vxc, 0, len(s.vxcs))
+	for _, v := range s.vxcs {
+		l = append(l, v)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].id < l[j].id })
+	return l
+}
+
+func (s *Server) handleListProducts(w http.ResponseWriter) {
+	l := make([]*product, 0, len(s.products))
+	for _, p := range s.products {
+		if p.status != api.ProductStatusDecommissioned {
+			l = append(l, p)
+		}
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].id < l[j].id })
+	data := make([]map[string]interface{}, len(l))
+	for i, p := range l {
+		data[i] = s.productJSON(p)
+	}
+	writeResponse(w, http.StatusOK, "", data)
+	// Products that are being set up also move along when they are listed,
+	// so that they can be waited on through the list alone, e.g. the ports
+	// of a LAG
+	for _, p := range l {
+		if p.status == api.ProductStatusDeployable {
+			advanceProduct(p)
+		}
+	}
+}
+
+func advanceProduct(p *product) {
+	if p.cancellationPending() {
+		return
+	}
+	p.status = advance(p.status)
+	if p.status == api.ProductStatusLive && p.liveDate == 0 {
+		p.liveDate = now()
+	}
+}
+
+func (s *Server) handleGetProduct(w http.ResponseWriter, uid string) {
+	// Each retrieval returns the current state of the product and then moves
+	// it along its lifecycle, so that every status is observed once
+	if p, ok := s.products[uid]; ok {
+		writeResponse(w, http.StatusOK, "", s.productJSON(p))
+		advanceProduct(p)
+		return
+	}
+	if v, ok := s.vxcs[uid]; ok {
+		writeResponse(w, http.StatusOK, "", s.vxcJSON(v))
+		v.status = advance(v.status)
+		if v.status == api.ProductStatusLive && v.liveDate == 0 {
+			v.liveDate = now()
+		}
+		return
+	}
+	if x, ok := s.ixs[uid]; ok {
+		writeResponse(w, http.StatusOK, "", s.ixJSON(x))
+		x.status = advance(x.status)
+		if x.status == api.ProductStatusLive && x.liveDate == 0 {
+			x.liveDate = now()
+		}
+		return
+	}
+	writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+}
+
+func (s *Server) handlePortVlan(w http.ResponseWriter, r *http.Request, uid string) {
+	p, ok := s.products[uid]
+	if !ok {
+		writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+		return
+	}
+	vlan, err := strconv.ParseUint(r.URL.Query().Get("vlan"), 10, 64)
+	if err != nil {
+		writeValidationErrors(w, []fieldError{{Field: "vlan", Message: "Invalid VLAN"}})
+		return
+	}
+	available := []uint64{}
+	if p.checkVlan(vlan, "") == "" {
+		available = append(available, vlan)
+	}
+	writeResponse(w, http.StatusOK, "", available)
+}
+
+func (s *Server) handleProductAction(w http.ResponseWriter, uid, action string) {
+	switch action {
+	case "CANCEL", "UN_CANCEL":
+		s.handleProductTermAction(w, uid, action)
+		return
+	case "CANCEL_NOW":
+	default:
+		writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Action %s is not supported", action)}})
+		return
+	}
+	if p, ok := s.products[uid]; ok {
+		if isDeleted(p.status) && !p.cancellationPending() {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has already been cancelled", uid)}})
+			return
+		}
+		for _, m := range s.productAndLagPorts(p) {
+			m.terminateDate = 0
+			s.cancelProduct(m)
+		}
+		writeResponse(w, http.StatusOK, fmt.Sprintf("Action [CANCEL_NOW Service %s] has been done.", uid), nil)
+		return
+	}
+	if v, ok := s.vxcs[uid]; ok {
+		if isDeleted(v.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has already been cancelled", uid)}})
+			return
+		}
+		v.status = api.ProductStatusCancelled
+		s.releaseVlans(v)
+		writeResponse(w, http.StatusOK, fmt.Sprintf("Action [CANCEL_NOW Service %s] has been done.", uid), nil)
+		return
+	}
+	if x, ok := s.ixs[uid]; ok {
+		if isDeleted(x.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has already been cancelled", uid)}})
+			return
+		}
+		s.cancelIx(x, api.ProductStatusCancelled)
+		writeResponse(w, http.StatusOK, fmt.Sprintf("Action [CANCEL_NOW Service %s] has been done.", uid), nil)
+		return
+	}
+	writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+}
+
+// handleProductTermAction cancels a port, MCR or MVE at the end of its term,
+// leaving its VXCs and IXs untouched, or restores it until then.
+func (s *Server) handleProductTermAction(w http.ResponseWriter, uid, action string) {
+	p, ok := s.products[uid]
+	if !ok {
+		writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+		return
+	}
+	if action == "CANCEL" {
+		if isDeleted(p.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has already been cancelled", uid)}})
+			return
+		}
+		for _, m := range s.productAndLagPorts(p) {
+			m.status = api.ProductStatusCancelled
+			m.terminateDate = m.termEndDate()
+		}
+	} else {
+		if !p.cancellationPending() {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s is not pending cancellation", uid)}})
+			return
+		}
+		for _, m := range s.productAndLagPorts(p) {
+			m.status = api.ProductStatusLive
+			m.terminateDate = 0
+		}
+	}
+	writeResponse(w, http.StatusOK, fmt.Sprintf("Action [%s Service %s] has been done.", action, uid), nil)
+}
+
+// productAndLagPorts returns all the ports of the LAG when p is its primary
+// port, and p otherwise.
+func (s *Server) productAndLagPorts(p *product) []*product {
+	if !p.lagPrimary {
+		return []*product{p}
+	}
+	l := []*product{}
+	for _, m := range s.products {
+		if m.lagId == p.lagId && (!isDeleted(m.status) || m.cancellationPending()) {
+			l = append(l, m)
+		}
+	}
+	return l
+}
+
+// cancelProduct cancels a product along with the VXCs and IXs connected to
+// it.
+func (s *Server) cancelProduct(p *product) {
+	p.status = api.ProductStatusCancelled
+	for _, v := range s.vxcs {
+		if !isDeleted(v.status) && (v.aEnd.productUid == p.uid || v.bEnd.productUid == p.uid) {
+			v.status = api.ProductStatusCancelledParent
+			s.releaseVlans(v)
+		}
+	}
+	for _, x := range s.ixs {
+		if !isDeleted(x.status) && x.productUid == p.uid {
+			s.cancelIx(x, api.ProductStatusCancelledParent)
+		}
+	}
+}
+
+func (s *Server) handleUpdateProduct(w http.ResponseWriter, r *http.Request, productType, uid string) {
+	u := &productUpdate{}
+	if err := json.NewDecoder(r.Body).Decode(u); err != nil {
+		writeResponse(w, http.StatusBadRequest, fmt.Sprintf("Could not parse update: %v", err), nil)
+		return
+	}
+	if u.Name != nil && *u.Name == "" {
+		writeValidationErrors(w, []fieldError{{Field: "name", Message: "A product name is required"}})
+		return
+	}
+	if p, ok := s.products[uid]; ok {
+		if strings.ToLower(p.productType) != productType {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s is not of type %s", uid, productType)}})
+			return
+		}
+		if isDeleted(p.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has been cancelled", uid)}})
+			return
+		}
+		if u.Name != nil {
+			p.name = *u.Name
+		}
+		if u.CostCentre != nil {
+			p.costCentre = *u.CostCentre
+		}
+		if u.MarketplaceVisibility != nil && p.productType == api.ProductTypePort {
+			p.marketplaceVisibility = *u.MarketplaceVisibility
+		}
+		writeResponse(w, http.StatusOK, "Product updated", s.productJSON(p))
+		return
+	}
+	if v, ok := s.vxcs[uid]; ok {
+		if productType != strings.ToLower(api.ProductTypeVxc) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s is not of type %s", uid, productType)}})
+			return
+		}
+		if isDeleted(v.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has been cancelled", uid)}})
+			return
+		}
+		s.updateVxc(w, v, u)
+		return
+	}
+	if x, ok := s.ixs[uid]; ok {
+		if productType != strings.ToLower(api.ProductTypeIx) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s is not of type %s", uid, productType)}})
+			return
+		}
+		if isDeleted(x.status) {
+			writeValidationErrors(w, []fieldError{{Message: fmt.Sprintf("Service %s has been cancelled", uid)}})
+			return
+		}
+		s.updateIx(w, x, u)
+		return
+	}
+	writeResponse(w, http.StatusNotFound, fmt.Sprintf("Could not find a service with UID %s", uid), nil)
+}
+
+func (s *Server) updateVxc(w http.ResponseWriter, v *vxc, u *productUpdate) {
+	a := s.products[v.aEnd.productUid]
+	b := s.liveProduct(v.bEnd.productUid)
+	rateLimit := v.rateLimit
+	if u.RateLimit != nil {
+		rateLimit = *u.RateLimit
+	}
+	if rateLimit == 0 || rateLimit > a.speed {
+		writeValidationErrors(w, []fieldError{{Field: "rateLimit", Message: fmt.Sprintf("The rate limit must be between 1 and %d Mbps", a.speed)}})
+		return
+	}
+	if u.AEndVlan != nil {
+		if msg := a.checkVlan(*u.AEndVlan, v.uid); msg != "" {
+			writeResponse(w, http.StatusConflict, msg, nil)
+			return
+		}
+	}
+	if u.BEndVlan != nil && b != nil {
+		if msg := b.checkVlan(*u.BEndVlan, v.uid); msg != "" {
+			writeResponse(w, http.StatusConflict, msg, nil)
+			return
+		}
+	}
+	if u.AEndConfig != nil {
+		if errs := s.validateVRouterConfig(a, u.AEndConfig, "aEndConfig"); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+	}
+	pc := v.partnerConfig
+	if u.BEndConfig != nil {
+		if pc == nil || stringValue(u.BEndConfig, "connectType") != stringValue(pc, "connectType") {
+			writeValidationErrors(w, []fieldError{{Field: "bEndConfig", Message: "The partner configuration does not match the B-End of the VXC"}})
+			return
+		}
+		pc = map[string]interface{}{}
+		for k, val := range v.partnerConfig {
+			pc[k] = val
+		}
+		for k, val := range u.BEndConfig {
+			pc[k] = val
+		}
+	}
+	if pc != nil {
+		if errs := s.validatePartnerConfig(stringValue(pc, "connectType"), rateLimit, pc, v.uid); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+	}
+	if u.AEndVlan != nil && *u.AEndVlan != v.aEnd.vlan {
+		delete(a.vlans, v.aEnd.vlan)
+		v.aEnd.vlan = *u.AEndVlan
+		a.vlans[v.aEnd.vlan] = v.uid
+	}
+	if u.BEndVlan != nil && b != nil && *u.BEndVlan != v.bEnd.vlan {
+		delete(b.vlans, v.bEnd.vlan)
+		v.bEnd.vlan = *u.BEndVlan
+		b.vlans[v.bEnd.vlan] = v.uid
+	}
+	if u.Name != nil {
+		v.name = *u.Name
+	}
+	if u.CostCentre != nil {
+		v.costCentre = *u.CostCentre
+	}
+	if u.AEndConfig != nil {
+		v.aEnd.partnerConfig = u.AEndConfig
+	}
+	v.rateLimit = rateLimit
+	v.partnerConfig = pc
+	writeResponse(w, http.StatusOK, "VXC updated", s.vxcJSON(v))
+}
+
+func (s *Server) productJSON(p *product) map[string]interface{} {
+	vxcs := []map[string]interface{}{}
+	for _, v := range s.sortedVxcs() {
+		if v.aEnd.productUid == p.uid || v.bEnd.productUid == p.uid {
+			vxcs = append(vxcs, s.vxcJSON(v))
+		}
+	}
+	ixs := []map[string]interface{}{}
+	for _, x := range s.sortedIxs() {
+		if x.productUid == p.uid {
+			ixs = append(ixs, s.ixJSON(x))
+		}
+	}
+	resources := map[string]interface{}{}
+	switch p.productType {
+	case api.ProductTypeMve:
+		imageId, _ := p.vendorConfig["imageId"].(float64)
+		resources["virtual_machine"] = []map[string]interface{}{{
+			"cpu_count":     mveSizes[stringValue(p.vendorConfig, "productSize")].cpuCount,
+			"id":            p.id,
+			"image":         map[string]interface{}{"id": imageId, "vendor": stringValue(p.vendorConfig, "vendor")},
+			"name":          p.name,
+			"resource_name": "virtual_machine",
+			"resource_type": "virtual_machine",
+		}}
+	case api.ProductTypeMcr2:
+		resources["virtual_router"] = map[string]interface{}{
+			"id":            p.id,
+			"mcrAsn":        p.asn,
+			"name":          p.name,
+			"resource_name": "virtual_router",
+			"resource_type": "virtual_router",
+			"speed":         p.speed,
+		}
+	default:
+		resources["interface"] = map[string]interface{}{
+			"demarcation":   "",
+			"description":   "",
+			"id":            p.id,
+			"loa_template":  "megaport",
+			"media":         "LR",
+			"name":          "Interface",
+			"port_speed":    p.speed,
+			"resource_name": "interface",
+			"resource_type": "interface",
+			"up":            1,
+		}
+	}
+	var lagId interface{}
+	if p.lagId != 0 {
+		lagId = p.lagId
+	}
+	var terminateDate interface{}
+	if p.terminateDate != 0 {
+		terminateDate = p.terminateDate
+	}
+	j := map[string]interface{}{
+		"productId":             p.id,
+		"productUid":            p.uid,
+		"productName":           p.name,
+		"productType":           p.productType,
+		"provisioningStatus":    p.status,
+		"locationId":            p.locationId,
+		"portSpeed":             p.speed,
+		"contractTermMonths":    p.term,
+		"costCentre":            p.costCentre,
+		"marketplaceVisibility": p.marketplaceVisibility,
+		"lagId":                 lagId,
+		"lagPrimary":            p.lagPrimary,
+		"virtual":               false,
+		"vxcPermitted":          true,
+		"vxcAutoApproval":       false,
+		"cancelable":            !isDeleted(p.status),
+		"companyUid":            CompanyUid,
+		"companyName":           CompanyName,
+		"createDate":            p.createDate,
+		"liveDate":              p.liveDate,
+		"terminateDate":         terminateDate,
+		"associatedVxcs":        vxcs,
+		"associatedIxs":         ixs,
+		"resources":             resources,
+	}
+	if p.productType == api.ProductTypeMve {
+		vnics := make([]map[string]interface{}, len(p.vnics))
+		for i, d := range p.vnics {
+			vnics[i] = map[string]interface{}{"description": d, "vlan": 0}
+		}
+		j["mveSize"] = stringValue(p.vendorConfig, "productSize")
+		j["vendor"] = stringValue(p.vendorConfig, "vendor")
+		j["vnics"] = vnics
+	}
+	return j
+}
+
+func (s *Server) vxcJSON(v *vxc) map[string]interface{} {
+	resources := map[string]interface{}{
+		"vll": map[string]interface{}{
+			"a_vlan":          v.aEnd.vlan,
+			"b_vlan":          v.bEnd.vlan,
+			"id":              v.id,
+			"name":            v.name,
+			"rate_limit_mbps": v.rateLimit,
+			"resource_name":   "vll",
+			"resource_type":   "vll",
+			"up":              1,
+		},
+	}
+	ccs := []map[string]interface{}{}
+	for i, e := range []vxcEnd{v.aEnd, v.bEnd} {
+		if p, ok := s.products[e.productUid]; ok && p.productType == api.ProductTypeMcr2 {
+			peers, status := vRouterBgpStatusJSON(e.partnerConfig)
+			ccs = append(ccs, map[string]interface{}{
+				"connectType":       api.VxcConnectTypeVRouter,
+				"resource_name":     []string{"a_csp_connection", "b_csp_connection"}[i],
+				"resource_type":     "csp_connection",
+				"virtualRouterId":   p.id,
+				"virtualRouterName": p.name,
+				"vlan":              e.vlan,
+				"interfaces":        vRouterInterfacesJSON(e.partnerConfig),
+				"bgp_peers":         peers,
+				"bgp_status":        status,
+			})
+		}
+	}
+	if v.partnerConfig != nil {
+		cc := map[string]interface{}{}
+		for k, val := range v.partnerConfig {
+			cc[k] = val
+		}
+		cc["resource_name"] = "b_csp_connection"
+		cc["resource_type"] = "csp_connection"
+		if stringValue(cc, "connectType") == api.VxcConnectTypeAws {
+			cc["vif_id"] = fmt.Sprintf("dxvif-fg%06x", v.id)
+		}
+		if stringValue(cc, "connectType") == api.VxcConnectTypeGoogle {
+			cc["bandwidth"] = v.rateLimit
+			cc["bandwidths"] = s.GcpBandwidths
+			cc["csp_name"] = "Google"
+		}
+		if stringValue(cc, "connectType") == api.VxcConnectTypeOracle {
+			p, _ := s.cloudPort(v.bEnd.productUid)
+			cc["bandwidth"] = v.rateLimit
+			cc["bandwidths"] = s.OracleBandwidths
+			cc["csp_name"] = "Oracle"
+			cc["megaports"] = []map[string]interface{}{{"port": p.ProductId, "vxc": v.id}}
+		}
+		if stringValue(cc, "connectType") == api.VxcConnectTypeAzure {
+			// The API reports the service key under another name, and
+			// never reports the shared keys
+			cc["service_key"] = cc["serviceKey"]
+			delete(cc, "serviceKey")
+			peers := []interface{}{}
+			if l, ok := cc["peers"].([]interface{}); ok {
+				for _, p := range l {
+					peer := map[string]interface{}{}
+					for k, val := range p.(map[string]interface{}) {
+						if k != "shared_key" {
+							peer[k] = val
+						}
+					}
+					peers = append(peers, peer)
+				}
+			}
+			cc["peers"] = peers
+			cc["managed"] = len(peers) > 0
+			cc["vlan"] = v.bEnd.vlan
+			p, _ := s.cloudPort(v.bEnd.productUid)
+			cc["megaports"] = []map[string]interface{}{{"port": p.ProductId, "type": p.Type, "vxc": v.id}}
+		}
+		ccs = append(ccs, cc)
+	}
+	// The API returns a single object, instead of a list, when the VXC has
+	// only one CSP connection
+	switch len(ccs) {
+	case 0:
+	case 1:
+		resources["csp_connection"] = ccs[0]
+	default:
+		resources["csp_connection"] = ccs
+	}
+	return map[string]interface{}{
+		"productId":          v.id,
+		"productUid":         v.uid,
+		"productName":        v.name,
+		"productType":        api.ProductTypeVxc,
+		"provisioningStatus": v.status,
+		"rateLimit":          v.rateLimit,
+		"costCentre":         v.costCentre,
+		"contractTermMonths": 1,
+		"cancelable":         !isDeleted(v.status),
+		"createDate":         v.createDate,
+		"liveDate":           v.liveDate,
+		"aEnd":               s.vxcEndJSON(v.aEnd),
+		"bEnd":               s.vxcEndJSON(v.bEnd),
+		"resources":          resources,
+	}
+}
+
+// vRouterInterfacesJSON renders the interfaces of the MCR end of a VXC. The
+// API never reports the BGP passwords.
+func vRouterInterfacesJSON(pc map[string]interface{}) []interface{} {
+	interfaces := []interface{}{}
+	l, _ := pc["interfaces"].([]interface{})
+	for _, i := range l {
+		iface := map[string]interface{}{}
+		for k, val := range i.(map[string]interface{}) {
+			iface[k] = val
+		}
+		connections := []interface{}{}
+		if l, ok := iface["bgpConnections"].([]interface{}); ok {
+			for _, c := range l {
+				bgp := map[string]interface{}{}
+				for k, val := range c.(map[string]interface{}) {
+					if k != "password" {
+						bgp[k] = val
+					}
+				}
+				connections = append(connections, bgp)
+			}
+		}
+		iface["bgpConnections"] = connections
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces
+}
+
+// vRouterBgpStatusJSON returns the peers of the BGP connections configured on
+// an MCR, which are all reported as established.
+func vRouterBgpStatusJSON(pc map[string]interface{}) ([]string, map[string]interface{}) {
+	peers := []string{}
+	status := map[string]interface{}{}
+	l, _ := pc["interfaces"].([]interface{})
+	for _, i := range l {
+		connections, _ := i.(map[string]interface{})["bgpConnections"].([]interface{})
+		for _, c := range connections {
+			if ip := stringValue(c.(map[string]interface{}), "peerIpAddress"); ip != "" {
+				peers = append(peers, ip)
+				status[ip] = 1
+			}
+		}
+	}
+	return peers, status
+}
+
+func (s *Server) vxcEndJSON(e vxcEnd) map[string]interface{} {
+	m := map[string]interface{}{
+		"productUid": e.productUid,
+		"vlan":       e.vlan,
+		"vNicIndex":  e.vnicIndex,
+	}
+	var locationId uint64
+	if p, ok := s.products[e.productUid]; ok {
+		m["ownerUid"] = CompanyUid
+		m["productName"] = p.name
+		locationId = p.locationId
+	} else if p := s.partnerPort(e.productUid); p != nil {
+		m["ownerUid"] = p.CompanyUid
+		m["productName"] = p.Title
+		locationId = p.LocationId
+	} else if p, _ := s.cloudPort(e.productUid); p != nil {
+		m["ownerUid"] = p.CompanyUid
+		m["productName"] = p.Name
+		locationId = p.LocationId
+	}
+	m["locationId"] = locationId
+	if l := s.location(locationId); l != nil {
+		m["location"] = l.Name
+	}
+	return m
+}
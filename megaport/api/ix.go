@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const (
+	ProductTypeIx = "IX"
+)
+
+type ixCreatePayload struct {
+	AssociatedIxs []*ixCreatePayloadAssociatedIx `json:"associatedIxs"`
+	ProductUid    *string                        `json:"productUid"`
+}
+
+type ixCreatePayloadAssociatedIx struct {
+	Asn                *uint64 `json:"asn"`
+	CostCentre         *string `json:"costCentre,omitempty"`
+	MacAddress         *string `json:"macAddress"`
+	NetworkServiceType *string `json:"networkServiceType"`
+	ProductName        *string `json:"productName"`
+	RateLimit          *uint64 `json:"rateLimit"`
+	Vlan               *uint64 `json:"vlan,omitempty"`
+}
+
+type ixUpdatePayload struct {
+	Asn        *uint64 `json:"asn,omitempty"`
+	CostCentre *string `json:"costCentre,omitempty"`
+	MacAddress *string `json:"macAddress,omitempty"`
+	Name       *string `json:"name,omitempty"`
+	RateLimit  *uint64 `json:"rateLimit,omitempty"`
+	Vlan       *uint64 `json:"vlan,omitempty"`
+}
+
+// IxCreateInput orders a connection from a port to the Internet Exchange
+// named InternetExchange, as returned by GetInternetExchanges. The VLAN is
+// chosen by Megaport when it is not set.
+type IxCreateInput struct {
+	Asn              *uint64
+	InternetExchange *string
+	InvoiceReference *string
+	MacAddress       *string
+	Name             *string
+	ProductUid       *string
+	RateLimit        *uint64
+	Vlan             *uint64
+}
+
+func (v *IxCreateInput) productType() string {
+	return ProductTypeIx
+}
+
+func (v *IxCreateInput) toPayload() ([]byte, error) {
+	payload := []*ixCreatePayload{{
+		AssociatedIxs: []*ixCreatePayloadAssociatedIx{{
+			Asn:                v.Asn,
+			CostCentre:         v.InvoiceReference,
+			MacAddress:         v.MacAddress,
+			NetworkServiceType: v.InternetExchange,
+			ProductName:        v.Name,
+			RateLimit:          v.RateLimit,
+			Vlan:               v.Vlan,
+		}},
+		ProductUid: v.ProductUid,
+	}}
+	return json.Marshal(payload)
+}
+
+type IxUpdateInput struct {
+	Asn              *uint64
+	InvoiceReference *string
+	MacAddress       *string
+	Name             *string
+	ProductUid       *string
+	RateLimit        *uint64
+	Vlan             *uint64
+}
+
+func (v *IxUpdateInput) productType() string {
+	return ProductTypeIx
+}
+
+func (v *IxUpdateInput) toPayload() ([]byte, error) {
+	payload := &ixUpdatePayload{
+		Asn:        v.Asn,
+		CostCentre: v.InvoiceReference,
+		MacAddress: v.MacAddress,
+		Name:       v.Name,
+		RateLimit:  v.RateLimit,
+		Vlan:       v.Vlan,
+	}
+	return json.Marshal(payload)
+}
+
+func (c *Client) CreateIx(ctx context.Context, v *IxCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	uid := d[0]["technicalServiceUid"].(string)
+	return &uid, nil
+}
+
+func (c *Client) GetIx(ctx context.Context, uid string) (*ProductAssociatedIx, error) {
+	d := &ProductAssociatedIx{}
+	if err := c.get(ctx, uid, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (c *Client) UpdateIx(ctx context.Context, v *IxUpdateInput) error {
+	return c.update(ctx, *v.ProductUid, v)
+}
+
+func (c *Client) DeleteIx(ctx context.Context, uid string) error {
+	return c.delete(ctx, uid)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestIxCreateInput_toPayload(t *testing.T) {
+	testCases := []struct {
+		i IxCreateInput
+		o []byte
+	}{
+		{ // 0
+			IxCreateInput{
+				Asn:              Uint64(uint64(64512)),
+				InternetExchange: String("London IX"),
+				InvoiceReference: String("foo"),
+				MacAddress:       String("00:11:22:33:44:55"),
+				Name:             String("bar"),
+				ProductUid:       String("baz"),
+				RateLimit:        Uint64(uint64(500)),
+				Vlan:             Uint64(uint64(100)),
+			},
+			[]byte(`[{"associatedIxs":[{"asn":64512,"costCentre":"foo","macAddress":"00:11:22:33:44:55","networkServiceType":"London IX","productName":"bar","rateLimit":500,"vlan":100}],"productUid":"baz"}]`),
+		},
+		{ // 1
+			IxCreateInput{
+				Asn:              Uint64(uint64(64512)),
+				InternetExchange: String("London IX"),
+				MacAddress:       String("00:11:22:33:44:55"),
+				Name:             String("bar"),
+				ProductUid:       String("baz"),
+				RateLimit:        Uint64(uint64(500)),
+			},
+			[]byte(`[{"associatedIxs":[{"asn":64512,"macAddress":"00:11:22:33:44:55","networkServiceType":"London IX","productName":"bar","rateLimit":500}],"productUid":"baz"}]`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := tc.i.toPayload()
+		if err != nil {
+			t.Errorf("IxCreateInput.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("IxCreateInput.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
+
+func TestIxUpdateInput_toPayload(t *testing.T) {
+	testCases := []struct {
+		i IxUpdateInput
+		o []byte
+	}{
+		{ // 0
+			IxUpdateInput{
+				Asn:              Uint64(uint64(64512)),
+				InvoiceReference: String("foo"),
+				MacAddress:       String("00:11:22:33:44:55"),
+				Name:             String("bar"),
+				ProductUid:       String("baz"),
+				RateLimit:        Uint64(uint64(500)),
+				Vlan:             Uint64(uint64(100)),
+			},
+			[]byte(`{"asn":64512,"costCentre":"foo","macAddress":"00:11:22:33:44:55","name":"bar","rateLimit":500,"vlan":100}`),
+		},
+		{ // 1
+			IxUpdateInput{
+				Name:       String("bar"),
+				ProductUid: String("baz"),
+			},
+			[]byte(`{"name":"bar"}`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := tc.i.toPayload()
+		if err != nil {
+			t.Errorf("IxUpdateInput.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("IxUpdateInput.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
+
+func TestProductAssociatedIx_IpAddresses(t *testing.T) {
+	v := &ProductAssociatedIx{}
+	if err := json.Unmarshal([]byte(`{"productUid":"foo","productType":"IX","resources":{"ip_address":[{"address":"192.0.2.10/24","resource_name":"ip_address","resource_type":"ip_address","version":4},{"address":"2001:db8::10/64","resource_name":"ip_address","resource_type":"ip_address","version":6}]}}`), v); err != nil {
+		t.Fatalf("TestProductAssociatedIx_IpAddresses: %v", err)
+	}
+	if a := fmt.Sprint(v.IpAddresses(4)); a != "[192.0.2.10/24]" {
+		t.Errorf("TestProductAssociatedIx_IpAddresses: unexpected IPv4 addresses %s", a)
+	}
+	if a := fmt.Sprint(v.IpAddresses(6)); a != "[2001:db8::10/64]" {
+		t.Errorf("TestProductAssociatedIx_IpAddresses: unexpected IPv6 addresses %s", a)
+	}
+}
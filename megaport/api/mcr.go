@@ -1,11 +1,21 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
+const (
+	PrefixFilterListAddressFamilyIPv4 = "IPv4"
+	PrefixFilterListAddressFamilyIPv6 = "IPv6"
+
+	PrefixFilterListActionPermit = "permit"
+	PrefixFilterListActionDeny   = "deny"
+)
+
 type McrCreateInput interface {
 	networkDesignInput
 }
@@ -86,8 +96,8 @@ func (v *Mcr2UpdateInput) toPayload() ([]byte, error) {
 	return json.Marshal(payload)
 }
 
-func (c *Client) CreateMcr(v McrCreateInput) (*string, error) {
-	d, err := c.create(v)
+func (c *Client) CreateMcr(ctx context.Context, v McrCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
 	if err != nil {
 		return nil, err
 	}
@@ -95,29 +105,171 @@ func (c *Client) CreateMcr(v McrCreateInput) (*string, error) {
 	return &uid, nil
 }
 
-func (c *Client) GetMcr(uid string) (*Product, error) {
+// ValidateMcr checks the order of an MCR without buying it.
+func (c *Client) ValidateMcr(ctx context.Context, v McrCreateInput) error {
+	return c.validate(ctx, v)
+}
+
+func (c *Client) GetMcr(ctx context.Context, uid string) (*Product, error) {
 	d := &Product{}
-	if err := c.get(uid, d); err != nil {
+	if err := c.get(ctx, uid, d); err != nil {
 		return nil, err
 	}
 	return d, nil
 }
 
-func (c *Client) UpdateMcr(v McrUpdateInput) error {
-	return c.update(v.productUid(), v)
+func (c *Client) UpdateMcr(ctx context.Context, v McrUpdateInput) error {
+	return c.update(ctx, v.productUid(), v)
+}
+
+func (c *Client) DeleteMcr(ctx context.Context, uid string) error {
+	return c.delete(ctx, uid)
+}
+
+// CancelMcr cancels an MCR at the end of its contract term. Until then, the
+// MCR is pending cancellation and can be restored with RestoreMcr.
+func (c *Client) CancelMcr(ctx context.Context, uid string) error {
+	return c.cancel(ctx, uid)
 }
 
-func (c *Client) DeleteMcr(uid string) error {
-	return c.delete(uid)
+func (c *Client) RestoreMcr(ctx context.Context, uid string) error {
+	return c.restore(ctx, uid)
 }
 
-func (c *Client) ListMcrs() ([]*Product, error) {
+func (c *Client) ListMcrs(ctx context.Context) ([]*Product, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/products", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
 	data := []*Product{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type McrPrefixFilterListEntryInput struct {
+	Action *string
+	Prefix *string
+	Ge     *uint64
+	Le     *uint64
+}
+
+type McrPrefixFilterListCreateInput struct {
+	AddressFamily *string
+	Description   *string
+	Entries       []*McrPrefixFilterListEntryInput
+	McrUid        *string
+}
+
+func (v *McrPrefixFilterListCreateInput) toPayload() ([]byte, error) {
+	return json.Marshal(newMcrPrefixFilterListPayload(v.AddressFamily, v.Description, v.Entries))
+}
+
+type McrPrefixFilterListUpdateInput struct {
+	AddressFamily *string
+	Description   *string
+	Entries       []*McrPrefixFilterListEntryInput
+	Id            *uint64
+	McrUid        *string
+}
+
+func (v *McrPrefixFilterListUpdateInput) toPayload() ([]byte, error) {
+	return json.Marshal(newMcrPrefixFilterListPayload(v.AddressFamily, v.Description, v.Entries))
+}
+
+type mcrPrefixFilterListPayload struct {
+	AddressFamily *string                            `json:"addressFamily"`
+	Description   *string                            `json:"description"`
+	Entries       []*mcrPrefixFilterListPayloadEntry `json:"entries"`
+}
+
+type mcrPrefixFilterListPayloadEntry struct {
+	Action *string `json:"action"`
+	Prefix *string `json:"prefix"`
+	Ge     *uint64 `json:"ge,omitempty"`
+	Le     *uint64 `json:"le,omitempty"`
+}
+
+// newMcrPrefixFilterListPayload returns the payload of a prefix filter list.
+// The entries are always sent, in order, since the API replaces all of them on
+// every update.
+func newMcrPrefixFilterListPayload(addressFamily, description *string, entries []*McrPrefixFilterListEntryInput) *mcrPrefixFilterListPayload {
+	payload := &mcrPrefixFilterListPayload{
+		AddressFamily: addressFamily,
+		Description:   description,
+		Entries:       make([]*mcrPrefixFilterListPayloadEntry, len(entries)),
+	}
+	for i, e := range entries {
+		payload.Entries[i] = &mcrPrefixFilterListPayloadEntry{
+			Action: e.Action,
+			Prefix: e.Prefix,
+			Ge:     e.Ge,
+			Le:     e.Le,
+		}
+	}
+	return payload
+}
+
+// CreateMcrPrefixFilterList creates a prefix filter list on an MCR and returns
+// its id, which is what BGP connections refer to.
+func (c *Client) CreateMcrPrefixFilterList(ctx context.Context, v *McrPrefixFilterListCreateInput) (*uint64, error) {
+	payload, err := v.toPayload()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/product/mcr2/%s/prefixList", c.BaseURL, *v.McrUid), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	data := &McrPrefixFilterList{}
+	if err := c.do(ctx, req, data); err != nil {
+		return nil, err
+	}
+	return &data.Id, nil
+}
+
+func (c *Client) GetMcrPrefixFilterList(ctx context.Context, mcrUid string, id uint64) (*McrPrefixFilterList, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/product/mcr2/%s/prefixList/%d", c.BaseURL, mcrUid, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	data := &McrPrefixFilterList{}
+	if err := c.do(ctx, req, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *Client) UpdateMcrPrefixFilterList(ctx context.Context, v *McrPrefixFilterListUpdateInput) error {
+	payload, err := v.toPayload()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v2/product/mcr2/%s/prefixList/%d", c.BaseURL, *v.McrUid, *v.Id), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, nil)
+}
+
+func (c *Client) DeleteMcrPrefixFilterList(ctx context.Context, mcrUid string, id uint64) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v2/product/mcr2/%s/prefixList/%d", c.BaseURL, mcrUid, id), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, nil)
+}
+
+// ListMcrPrefixFilterLists returns the prefix filter lists of an MCR. The
+// entries of the lists are not included.
+func (c *Client) ListMcrPrefixFilterLists(ctx context.Context, mcrUid string) ([]*McrPrefixFilterList, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/product/mcr2/%s/prefixLists", c.BaseURL, mcrUid), nil)
+	if err != nil {
+		return nil, err
+	}
+	data := []*McrPrefixFilterList{}
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, err
 	}
 	return data, nil
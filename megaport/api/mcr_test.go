@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestMcrPrefixFilterListCreateInput_toPayload(t *testing.T) {
+	testCases := []struct {
+		i McrPrefixFilterListCreateInput
+		o []byte
+	}{
+		{ // 0
+			McrPrefixFilterListCreateInput{
+				AddressFamily: String(PrefixFilterListAddressFamilyIPv4),
+				Description:   String("foo"),
+				Entries: []*McrPrefixFilterListEntryInput{
+					{
+						Action: String(PrefixFilterListActionPermit),
+						Prefix: String("10.0.0.0/8"),
+						Ge:     Uint64(uint64(16)),
+						Le:     Uint64(uint64(24)),
+					},
+					{
+						Action: String(PrefixFilterListActionDeny),
+						Prefix: String("0.0.0.0/0"),
+					},
+				},
+				McrUid: String("bar"),
+			},
+			[]byte(`{"addressFamily":"IPv4","description":"foo","entries":[{"action":"permit","prefix":"10.0.0.0/8","ge":16,"le":24},{"action":"deny","prefix":"0.0.0.0/0"}]}`),
+		},
+		{ // 1
+			McrPrefixFilterListCreateInput{
+				AddressFamily: String(PrefixFilterListAddressFamilyIPv6),
+				Description:   String("foo"),
+			},
+			[]byte(`{"addressFamily":"IPv6","description":"foo","entries":[]}`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := tc.i.toPayload()
+		if err != nil {
+			t.Errorf("McrPrefixFilterListCreateInput.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("McrPrefixFilterListCreateInput.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
+
+func TestClient_McrPrefixFilterList(t *testing.T) {
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		resp   string
+		f      func(c *Client) (interface{}, error)
+		check  func(v interface{}) bool
+	}{
+		{ // 0
+			http.MethodPost,
+			"/v2/product/mcr2/foo/prefixList",
+			`{"addressFamily":"IPv4","description":"bar","entries":[{"action":"permit","prefix":"10.0.0.0/8"}]}`,
+			`{"message":"","data":{"id":12,"description":"bar","addressFamily":"IPv4"}}`,
+			func(c *Client) (interface{}, error) {
+				return c.CreateMcrPrefixFilterList(context.Background(), &McrPrefixFilterListCreateInput{
+					AddressFamily: String(PrefixFilterListAddressFamilyIPv4),
+					Description:   String("bar"),
+					Entries:       []*McrPrefixFilterListEntryInput{{Action: String(PrefixFilterListActionPermit), Prefix: String("10.0.0.0/8")}},
+					McrUid:        String("foo"),
+				})
+			},
+			func(v interface{}) bool { return *v.(*uint64) == 12 },
+		},
+		{ // 1
+			http.MethodGet,
+			"/v2/product/mcr2/foo/prefixList/12",
+			"",
+			`{"message":"","data":{"id":12,"description":"bar","addressFamily":"IPv4","entries":[{"action":"permit","prefix":"10.0.0.0/8","ge":16,"le":24}]}}`,
+			func(c *Client) (interface{}, error) {
+				return c.GetMcrPrefixFilterList(context.Background(), "foo", 12)
+			},
+			func(v interface{}) bool {
+				l := v.(*McrPrefixFilterList)
+				return l.Id == 12 && l.Description == "bar" && l.AddressFamily == PrefixFilterListAddressFamilyIPv4 &&
+					len(l.Entries) == 1 && *l.Entries[0] == McrPrefixFilterListEntry{Action: "permit", Prefix: "10.0.0.0/8", Ge: 16, Le: 24}
+			},
+		},
+		{ // 2
+			http.MethodPut,
+			"/v2/product/mcr2/foo/prefixList/12",
+			`{"addressFamily":"IPv4","description":"baz","entries":[{"action":"deny","prefix":"10.0.0.0/8","le":32}]}`,
+			`{"message":"Prefix filter list updated","data":null}`,
+			func(c *Client) (interface{}, error) {
+				return nil, c.UpdateMcrPrefixFilterList(context.Background(), &McrPrefixFilterListUpdateInput{
+					AddressFamily: String(PrefixFilterListAddressFamilyIPv4),
+					Description:   String("baz"),
+					Entries:       []*McrPrefixFilterListEntryInput{{Action: String(PrefixFilterListActionDeny), Prefix: String("10.0.0.0/8"), Le: Uint64(uint64(32))}},
+					Id:            Uint64(uint64(12)),
+					McrUid:        String("foo"),
+				})
+			},
+			nil,
+		},
+		{ // 3
+			http.MethodDelete,
+			"/v2/product/mcr2/foo/prefixList/12",
+			"",
+			`{"message":"Prefix filter list deleted","data":null}`,
+			func(c *Client) (interface{}, error) {
+				return nil, c.DeleteMcrPrefixFilterList(context.Background(), "foo", 12)
+			},
+			nil,
+		},
+		{ // 4
+			http.MethodGet,
+			"/v2/product/mcr2/foo/prefixLists",
+			"",
+			`{"message":"","data":[{"id":12,"description":"bar","addressFamily":"IPv4"},{"id":13,"description":"baz","addressFamily":"IPv6"}]}`,
+			func(c *Client) (interface{}, error) {
+				return c.ListMcrPrefixFilterLists(context.Background(), "foo")
+			},
+			func(v interface{}) bool {
+				l := v.([]*McrPrefixFilterList)
+				return len(l) == 2 && l[0].Id == 12 && l[1].Id == 13 && l[1].AddressFamily == PrefixFilterListAddressFamilyIPv6
+			},
+		},
+	}
+	for i, tc := range testCases {
+		c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != tc.method || r.URL.Path != tc.path {
+				t.Errorf("TestClient_McrPrefixFilterList #%d: unexpected request: got '%s %s', expected '%s %s'", i, r.Method, r.URL.Path, tc.method, tc.path)
+			}
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("TestClient_McrPrefixFilterList #%d: %v", i, err)
+			}
+			if string(body) != tc.body {
+				t.Errorf("TestClient_McrPrefixFilterList #%d: unexpected body: got '%s', expected '%s'", i, body, tc.body)
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, tc.resp)
+		})
+		v, err := tc.f(c)
+		if err != nil {
+			t.Errorf("TestClient_McrPrefixFilterList #%d: %v", i, err)
+		} else if tc.check != nil && !tc.check(v) {
+			t.Errorf("TestClient_McrPrefixFilterList #%d: unexpected result: %#v", i, v)
+		}
+		s.Close()
+	}
+}
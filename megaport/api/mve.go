@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	ProductTypeMve = "MVE"
+
+	MveVendorCisco    = "cisco"
+	MveVendorFortinet = "fortinet"
+	MveVendorPaloAlto = "palo_alto"
+	MveVendorVersa    = "versa"
+
+	MveSizeSmall  = "SMALL"
+	MveSizeMedium = "MEDIUM"
+	MveSizeLarge  = "LARGE"
+)
+
+type mveCreatePayload struct {
+	CostCentre   *string                 `json:"costCentre,omitempty"`
+	LocationId   *uint64                 `json:"locationId"`
+	ProductName  *string                 `json:"productName"`
+	ProductType  *string                 `json:"productType"`
+	Term         *uint64                 `json:"term"`
+	VendorConfig interface{}             `json:"vendorConfig"`
+	Vnics        []*mveCreatePayloadVnic `json:"vnics,omitempty"`
+}
+
+type mveCreatePayloadVnic struct {
+	Description *string `json:"description"`
+}
+
+// MveCreateInput orders a Megaport Virtual Edge running the image of
+// VendorConfig. Megaport creates a single vNIC when VnicDescriptions is empty.
+type MveCreateInput struct {
+	InvoiceReference *string
+	LocationId       *uint64
+	Name             *string
+	Term             *uint64
+	VendorConfig     MveVendorConfig
+	VnicDescriptions []string
+}
+
+func (v *MveCreateInput) productType() string {
+	return ProductTypeMve
+}
+
+func (v *MveCreateInput) toPayload() ([]byte, error) {
+	payload := []*mveCreatePayload{{
+		CostCentre:   v.InvoiceReference,
+		LocationId:   v.LocationId,
+		ProductName:  v.Name,
+		ProductType:  String(ProductTypeMve),
+		Term:         v.Term,
+		VendorConfig: v.VendorConfig.toPayload(),
+	}}
+	for _, d := range v.VnicDescriptions {
+		payload[0].Vnics = append(payload[0].Vnics, &mveCreatePayloadVnic{Description: String(d)})
+	}
+	return json.Marshal(payload)
+}
+
+type MveUpdateInput struct {
+	InvoiceReference *string
+	Name             *string
+	ProductUid       *string
+}
+
+func (v *MveUpdateInput) productType() string {
+	return ProductTypeMve
+}
+
+func (v *MveUpdateInput) toPayload() ([]byte, error) {
+	payload := &portUpdatePayload{
+		Name:       v.Name,
+		CostCentre: v.InvoiceReference,
+	}
+	return json.Marshal(payload)
+}
+
+// MveVendorConfig is the image of an MVE along with the bootstrap
+// configuration of its vendor.
+type MveVendorConfig interface {
+	vendor() string
+	toPayload() interface{}
+}
+
+type MveVendorConfigCisco struct {
+	AdminSshPublicKey  *string
+	CloudInit          *string
+	FmcIpAddress       *string
+	FmcNatId           *string
+	FmcRegistrationKey *string
+	ImageId            *uint64
+	ManageLocally      *bool
+	ProductSize        *string
+	SshPublicKey       *string
+}
+
+func (v *MveVendorConfigCisco) vendor() string {
+	return MveVendorCisco
+}
+
+func (v *MveVendorConfigCisco) toPayload() interface{} {
+	return &mveCreatePayloadVendorConfigCisco{
+		AdminSshPublicKey:  v.AdminSshPublicKey,
+		CloudInit:          v.CloudInit,
+		FmcIpAddress:       v.FmcIpAddress,
+		FmcNatId:           v.FmcNatId,
+		FmcRegistrationKey: v.FmcRegistrationKey,
+		ImageId:            v.ImageId,
+		ManageLocally:      v.ManageLocally,
+		ProductSize:        v.ProductSize,
+		SshPublicKey:       v.SshPublicKey,
+		Vendor:             String(v.vendor()),
+	}
+}
+
+type mveCreatePayloadVendorConfigCisco struct {
+	AdminSshPublicKey  *string `json:"adminSshPublicKey,omitempty"`
+	CloudInit          *string `json:"cloudInit,omitempty"`
+	FmcIpAddress       *string `json:"fmcIpAddress,omitempty"`
+	FmcNatId           *string `json:"fmcNatId,omitempty"`
+	FmcRegistrationKey *string `json:"fmcRegistrationKey,omitempty"`
+	ImageId            *uint64 `json:"imageId"`
+	ManageLocally      *bool   `json:"manageLocally,omitempty"`
+	ProductSize        *string `json:"productSize"`
+	SshPublicKey       *string `json:"sshPublicKey,omitempty"`
+	Vendor             *string `json:"vendor"`
+}
+
+type MveVendorConfigFortinet struct {
+	AdminSshPublicKey *string
+	ImageId           *uint64
+	LicenseData       *string
+	ProductSize       *string
+	SshPublicKey      *string
+}
+
+func (v *MveVendorConfigFortinet) vendor() string {
+	return MveVendorFortinet
+}
+
+func (v *MveVendorConfigFortinet) toPayload() interface{} {
+	return &mveCreatePayloadVendorConfigFortinet{
+		AdminSshPublicKey: v.AdminSshPublicKey,
+		ImageId:           v.ImageId,
+		LicenseData:       v.LicenseData,
+		ProductSize:       v.ProductSize,
+		SshPublicKey:      v.SshPublicKey,
+		Vendor:            String(v.vendor()),
+	}
+}
+
+type mveCreatePayloadVendorConfigFortinet struct {
+	AdminSshPublicKey *string `json:"adminSshPublicKey,omitempty"`
+	ImageId           *uint64 `json:"imageId"`
+	LicenseData       *string `json:"licenseData,omitempty"`
+	ProductSize       *string `json:"productSize"`
+	SshPublicKey      *string `json:"sshPublicKey,omitempty"`
+	Vendor            *string `json:"vendor"`
+}
+
+type MveVendorConfigPaloAlto struct {
+	AdminPasswordHash *string
+	AdminSshPublicKey *string
+	ImageId           *uint64
+	LicenseData       *string
+	ProductSize       *string
+	SshPublicKey      *string
+}
+
+func (v *MveVendorConfigPaloAlto) vendor() string {
+	return MveVendorPaloAlto
+}
+
+func (v *MveVendorConfigPaloAlto) toPayload() interface{} {
+	return &mveCreatePayloadVendorConfigPaloAlto{
+		AdminPasswordHash: v.AdminPasswordHash,
+		AdminSshPublicKey: v.AdminSshPublicKey,
+		ImageId:           v.ImageId,
+		LicenseData:       v.LicenseData,
+		ProductSize:       v.ProductSize,
+		SshPublicKey:      v.SshPublicKey,
+		Vendor:            String(v.vendor()),
+	}
+}
+
+type mveCreatePayloadVendorConfigPaloAlto struct {
+	AdminPasswordHash *string `json:"adminPasswordHash,omitempty"`
+	AdminSshPublicKey *string `json:"adminSshPublicKey,omitempty"`
+	ImageId           *uint64 `json:"imageId"`
+	LicenseData       *string `json:"licenseData,omitempty"`
+	ProductSize       *string `json:"productSize"`
+	SshPublicKey      *string `json:"sshPublicKey,omitempty"`
+	Vendor            *string `json:"vendor"`
+}
+
+type MveVendorConfigVersa struct {
+	ControllerAddress *string
+	DirectorAddress   *string
+	ImageId           *uint64
+	LocalAuth         *string
+	ProductSize       *string
+	RemoteAuth        *string
+	SerialNumber      *string
+}
+
+func (v *MveVendorConfigVersa) vendor() string {
+	return MveVendorVersa
+}
+
+func (v *MveVendorConfigVersa) toPayload() interface{} {
+	return &mveCreatePayloadVendorConfigVersa{
+		ControllerAddress: v.ControllerAddress,
+		DirectorAddress:   v.DirectorAddress,
+		ImageId:           v.ImageId,
+		LocalAuth:         v.LocalAuth,
+		ProductSize:       v.ProductSize,
+		RemoteAuth:        v.RemoteAuth,
+		SerialNumber:      v.SerialNumber,
+		Vendor:            String(v.vendor()),
+	}
+}
+
+type mveCreatePayloadVendorConfigVersa struct {
+	ControllerAddress *string `json:"controllerAddress"`
+	DirectorAddress   *string `json:"directorAddress"`
+	ImageId           *uint64 `json:"imageId"`
+	LocalAuth         *string `json:"localAuth"`
+	ProductSize       *string `json:"productSize"`
+	RemoteAuth        *string `json:"remoteAuth"`
+	SerialNumber      *string `json:"serialNumber"`
+	Vendor            *string `json:"vendor"`
+}
+
+func (c *Client) CreateMve(ctx context.Context, v *MveCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	uid := d[0]["technicalServiceUid"].(string)
+	return &uid, nil
+}
+
+func (c *Client) GetMve(ctx context.Context, uid string) (*Product, error) {
+	d := &Product{}
+	if err := c.get(ctx, uid, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (c *Client) UpdateMve(ctx context.Context, v *MveUpdateInput) error {
+	return c.update(ctx, *v.ProductUid, v)
+}
+
+func (c *Client) DeleteMve(ctx context.Context, uid string) error {
+	return c.delete(ctx, uid)
+}
+
+func (c *Client) ListMves(ctx context.Context) ([]*Product, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/products", c.BaseURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	data := []*Product{}
+	if err := c.do(ctx, req, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
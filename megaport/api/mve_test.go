@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestMveCreateInput_toPayload(t *testing.T) {
+	testCases := []struct {
+		i MveCreateInput
+		o []byte
+	}{
+		{ // 0
+			MveCreateInput{
+				InvoiceReference: String("foo"),
+				LocationId:       Uint64(uint64(1)),
+				Name:             String("bar"),
+				Term:             Uint64(uint64(12)),
+				VendorConfig: &MveVendorConfigCisco{
+					AdminSshPublicKey: String("ssh-rsa AAAA"),
+					ImageId:           Uint64(uint64(23)),
+					ManageLocally:     Bool(true),
+					ProductSize:       String(MveSizeSmall),
+					SshPublicKey:      String("ssh-rsa BBBB"),
+				},
+				VnicDescriptions: []string{"inside", "outside"},
+			},
+			[]byte(`[{"costCentre":"foo","locationId":1,"productName":"bar","productType":"MVE","term":12,"vendorConfig":{"adminSshPublicKey":"ssh-rsa AAAA","imageId":23,"manageLocally":true,"productSize":"SMALL","sshPublicKey":"ssh-rsa BBBB","vendor":"cisco"},"vnics":[{"description":"inside"},{"description":"outside"}]}]`),
+		},
+		{ // 1
+			MveCreateInput{
+				LocationId: Uint64(uint64(1)),
+				Name:       String("bar"),
+				Term:       Uint64(uint64(1)),
+				VendorConfig: &MveVendorConfigVersa{
+					ControllerAddress: String("controller.example.com"),
+					DirectorAddress:   String("director.example.com"),
+					ImageId:           Uint64(uint64(42)),
+					LocalAuth:         String("local"),
+					ProductSize:       String(MveSizeLarge),
+					RemoteAuth:        String("remote"),
+					SerialNumber:      String("abc123"),
+				},
+			},
+			[]byte(`[{"locationId":1,"productName":"bar","productType":"MVE","term":1,"vendorConfig":{"controllerAddress":"controller.example.com","directorAddress":"director.example.com","imageId":42,"localAuth":"local","productSize":"LARGE","remoteAuth":"remote","serialNumber":"abc123","vendor":"versa"}}]`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := tc.i.toPayload()
+		if err != nil {
+			t.Errorf("MveCreateInput.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("MveCreateInput.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
+
+func TestMveVendorConfig_toPayload(t *testing.T) {
+	testCases := []struct {
+		i MveVendorConfig
+		o []byte
+	}{
+		{ // 0
+			&MveVendorConfigFortinet{
+				AdminSshPublicKey: String("ssh-rsa AAAA"),
+				ImageId:           Uint64(uint64(1)),
+				LicenseData:       String("license"),
+				ProductSize:       String(MveSizeMedium),
+				SshPublicKey:      String("ssh-rsa BBBB"),
+			},
+			[]byte(`{"adminSshPublicKey":"ssh-rsa AAAA","imageId":1,"licenseData":"license","productSize":"MEDIUM","sshPublicKey":"ssh-rsa BBBB","vendor":"fortinet"}`),
+		},
+		{ // 1
+			&MveVendorConfigPaloAlto{
+				AdminPasswordHash: String("$1$hash"),
+				ImageId:           Uint64(uint64(2)),
+				ProductSize:       String(MveSizeSmall),
+			},
+			[]byte(`{"adminPasswordHash":"$1$hash","imageId":2,"productSize":"SMALL","vendor":"palo_alto"}`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := json.Marshal(tc.i.toPayload())
+		if err != nil {
+			t.Errorf("MveVendorConfig.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("MveVendorConfig.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
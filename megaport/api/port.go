@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -23,7 +24,8 @@ type portCreatePayload struct {
 	CreateDate            *uint64                      `json:"createDate,omitempty"` // TODO: need to fill in? :o
 	Config                *portCreatePayloadPortConfig `json:"config,omitempty"`
 	CostCentre            *string                      `json:"costCentre"`
-	LagPortCount          *uint64                      `json:"lagPortCount,omitempty"` // TODO: Required: the number of ports in this LAG order (https://dev.megaport.com/#standard-api-orders-validate-lag-order)
+	LagId                 *uint64                      `json:"lagId,omitempty"`
+	LagPortCount          *uint64                      `json:"lagPortCount,omitempty"` // The number of ports in this LAG order (https://dev.megaport.com/#standard-api-orders-validate-lag-order)
 	LocationId            *uint64                      `json:"locationId"`
 	LocationUid           *string                      `json:"locationUid,omitempty"` // TODO: null in example, is it a string? https://dev.megaport.com/#standard-api-orders-validate-port-order
 	Market                *string                      `json:"market,omitempty"`      // TODO: what is this ???
@@ -46,7 +48,11 @@ type portUpdatePayload struct {
 	// RateLimit             *uint64 `json:"rateLimit,omitempty"` // Only applicable to MCR. Must be one of 100, 500, 1000, 2000, 3000, 4000, 5000
 }
 
+// PortCreateInput orders a single port, or a LAG of LagPortCount ports. When
+// LagId is set, LagPortCount ports are added to that existing LAG instead.
 type PortCreateInput struct {
+	LagId                 *uint64
+	LagPortCount          *uint64
 	LocationId            *uint64
 	MarketplaceVisibility *bool
 	Name                  *string
@@ -70,6 +76,10 @@ func (v *PortCreateInput) toPayload() ([]byte, error) {
 		Virtual:               Bool(false), // TODO
 		MarketplaceVisibility: v.MarketplaceVisibility,
 	}}
+	if v.LagPortCount != nil && *v.LagPortCount > 0 {
+		payload[0].LagPortCount = v.LagPortCount
+		payload[0].LagId = v.LagId
+	}
 	return json.Marshal(payload)
 }
 
@@ -93,8 +103,10 @@ func (v *PortUpdateInput) toPayload() ([]byte, error) {
 	return json.Marshal(payload)
 }
 
-func (c *Client) CreatePort(v *PortCreateInput) (*string, error) {
-	d, err := c.create(v)
+// CreatePort orders a port and returns its uid. For LAG orders, the uid of the
+// first port of the order is returned, which is the primary port of a new LAG.
+func (c *Client) CreatePort(ctx context.Context, v *PortCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
 	if err != nil {
 		return nil, err
 	}
@@ -102,35 +114,71 @@ func (c *Client) CreatePort(v *PortCreateInput) (*string, error) {
 	return &uid, nil
 }
 
-func (c *Client) GetPort(uid string) (*Product, error) {
+// ValidatePort checks the order of a port without buying it.
+func (c *Client) ValidatePort(ctx context.Context, v *PortCreateInput) error {
+	return c.validate(ctx, v)
+}
+
+func (c *Client) GetPort(ctx context.Context, uid string) (*Product, error) {
 	d := &Product{}
-	if err := c.get(uid, d); err != nil {
+	if err := c.get(ctx, uid, d); err != nil {
 		return nil, err
 	}
 	return d, nil
 }
 
-func (c *Client) UpdatePort(v *PortUpdateInput) error {
-	return c.update(*v.ProductUid, v)
+func (c *Client) UpdatePort(ctx context.Context, v *PortUpdateInput) error {
+	return c.update(ctx, *v.ProductUid, v)
+}
+
+func (c *Client) DeletePort(ctx context.Context, uid string) error {
+	return c.delete(ctx, uid)
+}
+
+// CancelPort cancels a port at the end of its contract term. Until then, the
+// port is pending cancellation and can be restored with RestorePort.
+func (c *Client) CancelPort(ctx context.Context, uid string) error {
+	return c.cancel(ctx, uid)
 }
 
-func (c *Client) DeletePort(uid string) error {
-	return c.delete(uid)
+func (c *Client) RestorePort(ctx context.Context, uid string) error {
+	return c.restore(ctx, uid)
 }
 
-func (c *Client) ListPorts() ([]*Product, error) {
+func (c *Client) ListPorts(ctx context.Context) ([]*Product, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/products", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
 	data := []*Product{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (c *Client) GetPortVlanIdAvailable(uid string, vlanId uint64) (bool, error) {
+// GetLagPorts returns the ports of a LAG that have not been deleted, starting
+// with the primary port.
+func (c *Client) GetLagPorts(ctx context.Context, lagId uint64) ([]*Product, error) {
+	ports, err := c.ListPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lag := []*Product{}
+	for _, p := range ports {
+		if p.LagId != lagId || c.IsResourceDeleted(p.ProvisioningStatus) {
+			continue
+		}
+		if p.LagPrimary {
+			lag = append([]*Product{p}, lag...)
+		} else {
+			lag = append(lag, p)
+		}
+	}
+	return lag, nil
+}
+
+func (c *Client) GetPortVlanIdAvailable(ctx context.Context, uid string, vlanId uint64) (bool, error) {
 	v := url.Values{}
 	v.Set("vlan", strconv.FormatUint(vlanId, 10))
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/product/port/%s/vlan?%s", c.BaseURL, uid, v.Encode()), nil)
@@ -138,7 +186,7 @@ func (c *Client) GetPortVlanIdAvailable(uid string, vlanId uint64) (bool, error)
 		return false, err
 	}
 	data := []uint64{}
-	if err := c.do(req, &data); err != nil {
+	if err := c.do(ctx, req, &data); err != nil {
 		return false, err
 	}
 	for _, id := range data {
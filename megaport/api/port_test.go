@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPortCreateInput_toPayload(t *testing.T) {
+	testCases := []struct {
+		i PortCreateInput
+		o []byte
+	}{
+		{ // 0
+			PortCreateInput{
+				LocationId: Uint64(uint64(1)),
+				Name:       String("foo"),
+				Speed:      Uint64(uint64(10000)),
+				Term:       Uint64(uint64(12)),
+			},
+			[]byte(`[{"costCentre":null,"locationId":1,"portSpeed":10000,"productName":"foo","productType":"MEGAPORT","term":12,"virtual":false}]`),
+		},
+		{ // 1
+			PortCreateInput{
+				LagPortCount: Uint64(uint64(2)),
+				LocationId:   Uint64(uint64(1)),
+				Name:         String("foo"),
+				Speed:        Uint64(uint64(10000)),
+				Term:         Uint64(uint64(12)),
+			},
+			[]byte(`[{"costCentre":null,"lagPortCount":2,"locationId":1,"portSpeed":10000,"productName":"foo","productType":"MEGAPORT","term":12,"virtual":false}]`),
+		},
+		{ // 2
+			PortCreateInput{
+				LagId:        Uint64(uint64(123)),
+				LagPortCount: Uint64(uint64(1)),
+				LocationId:   Uint64(uint64(1)),
+				Name:         String("foo"),
+				Speed:        Uint64(uint64(10000)),
+				Term:         Uint64(uint64(12)),
+			},
+			[]byte(`[{"costCentre":null,"lagId":123,"lagPortCount":1,"locationId":1,"portSpeed":10000,"productName":"foo","productType":"MEGAPORT","term":12,"virtual":false}]`),
+		},
+		{ // 3
+			PortCreateInput{
+				LagId:        Uint64(uint64(123)),
+				LagPortCount: Uint64(uint64(0)),
+				LocationId:   Uint64(uint64(1)),
+				Name:         String("foo"),
+				Speed:        Uint64(uint64(10000)),
+				Term:         Uint64(uint64(12)),
+			},
+			[]byte(`[{"costCentre":null,"locationId":1,"portSpeed":10000,"productName":"foo","productType":"MEGAPORT","term":12,"virtual":false}]`),
+		},
+	}
+	for i, tc := range testCases {
+		payload, err := tc.i.toPayload()
+		if err != nil {
+			t.Errorf("PortCreateInput.toPayload #%d: %v", i, err)
+		}
+		if !bytes.Equal(payload, tc.o) {
+			t.Errorf("PortCreateInput.toPayload #%d: expected %s, got %s", i, tc.o, payload)
+		}
+	}
+}
+
+func TestClient_GetLagPorts(t *testing.T) {
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/products" {
+			t.Errorf("TestClient_GetLagPorts: unexpected request: got '%s %s'", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"data":[`+
+			`{"productUid":"a","lagId":1,"lagPrimary":false,"provisioningStatus":"LIVE"},`+
+			`{"productUid":"b","lagId":2,"lagPrimary":true,"provisioningStatus":"LIVE"},`+
+			`{"productUid":"c","lagId":1,"lagPrimary":true,"provisioningStatus":"LIVE"},`+
+			`{"productUid":"d","lagId":1,"lagPrimary":false,"provisioningStatus":"CANCELLED"},`+
+			`{"productUid":"e","provisioningStatus":"LIVE"},`+
+			`{"productUid":"f","lagId":1,"lagPrimary":false,"provisioningStatus":"CONFIGURED"}`+
+			`]}`)
+	})
+	defer s.Close()
+	ports, err := c.GetLagPorts(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("TestClient_GetLagPorts: %v", err)
+	}
+	uids := make([]string, len(ports))
+	for i, p := range ports {
+		uids[i] = p.ProductUid
+	}
+	if fmt.Sprint(uids) != "[c a f]" {
+		t.Errorf("TestClient_GetLagPorts: unexpected ports: got %v, expected [c a f]", uids)
+	}
+}
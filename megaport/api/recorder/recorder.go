@@ -0,0 +1,459 @@
+// Package recorder implements an http.RoundTripper that records the traffic to
+// the Megaport API into cassette files and replays it from them, so that the
+// acceptance tests can run without access to the API.
+//
+// Secrets are scrubbed from cassettes while recording: credentials in query
+// strings and form bodies, and the values of sensitive fields in JSON bodies,
+// such as tokens, BGP auth keys, AWS account ids, pairing keys and service
+// keys, are replaced with numbered placeholders. Request headers are not
+// recorded at all. When replaying, a request matches a recorded one if they
+// are identical apart from the scrubbed values, and the values of the live
+// request take the place of the placeholders in the responses that follow.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Mode string
+
+const (
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+
+	redacted = "REDACTED"
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(redacted + `-\d{4}`)
+
+	// sensitiveKeys are the JSON fields, query parameters and form values
+	// that are scrubbed, in lower case
+	sensitiveKeys = map[string]bool{
+		"access_token":     true,
+		"account":          true,
+		"authkey":          true,
+		"client_secret":    true,
+		"onetimepassword":  true,
+		"owneraccount":     true,
+		"pairingkey":       true,
+		"password":         true,
+		"refresh_token":    true,
+		"service_key":      true,
+		"servicekey":       true,
+		"shared_key":       true,
+		"token":            true,
+		"username":         true,
+		"virtualcircuitid": true,
+	}
+
+	// sensitivePaths match request paths with a secret in their first group
+	sensitivePaths = []*regexp.Regexp{
+		regexp.MustCompile(`^/v2/secure/azure/(.+)$`),
+		regexp.MustCompile(`^/v2/secure/google/(.+)$`),
+		regexp.MustCompile(`^/v2/secure/oracle/(.+)$`),
+	}
+
+	recordedHeaders = []string{"Content-Type", "Retry-After"}
+)
+
+// Cassette holds the interactions recorded for a single test.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// Recorder records or replays the requests sent through it, depending on its
+// mode. It is safe for concurrent use.
+type Recorder struct {
+	// Transport sends requests while recording. If nil, http.DefaultTransport
+	// is used.
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette *Cassette
+	// secrets maps scrubbed values to their placeholders while recording
+	secrets map[string]string
+	// used marks the interactions that have been replayed and values maps
+	// placeholders to the values seen in live requests while replaying
+	used   []bool
+	values map[string]string
+}
+
+// New returns a Recorder that records to, or replays from, the cassette at
+// path. When replaying, the error satisfies os.IsNotExist if there is no
+// cassette at path.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		cassette: &Cassette{},
+		secrets:  map[string]string{},
+		values:   map[string]string{},
+	}
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, r.cassette); err != nil {
+			return nil, fmt.Errorf("recorder: cannot parse cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("recorder: unknown mode %q", mode)
+	}
+	return r, nil
+}
+
+// Stop saves the cassette when recording. It does nothing when replaying.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	if r.mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := r.transport().RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	contentType := req.Header.Get("Content-Type")
+	i := &Interaction{
+		Request: Request{
+			Method:      req.Method,
+			URL:         scrubURL(req.URL, r.placeholder),
+			ContentType: contentType,
+			Body:        string(scrubBody(body, contentType, r.placeholder)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    map[string]string{},
+			Body:       string(scrubBody(respBody, resp.Header.Get("Content-Type"), r.placeholder)),
+		},
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			i.Response.Headers[h] = v
+		}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	return resp, nil
+}
+
+// placeholder returns the placeholder for a secret, which is the same every
+// time the secret is seen.
+func (r *Recorder) placeholder(v string) string {
+	if p, ok := r.secrets[v]; ok {
+		return p
+	}
+	p := fmt.Sprintf("%s-%04d", redacted, len(r.secrets)+1)
+	r.secrets[v] = p
+	return p
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	contentType := req.Header.Get("Content-Type")
+	redact := func(string) string { return redacted }
+	u := scrubURL(req.URL, redact)
+	b := string(scrubBody(body, contentType, redact))
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request.Method != req.Method ||
+			placeholderRegexp.ReplaceAllString(in.Request.URL, redacted) != u ||
+			placeholderRegexp.ReplaceAllString(in.Request.Body, redacted) != b {
+			continue
+		}
+		if !r.used[i] {
+			match = i
+			break
+		}
+		// Polling might take more requests than it did while recording,
+		// in which case the last response is served again
+		if req.Method == http.MethodGet {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("recorder: no interaction in %s matches %s %s", r.path, req.Method, u)
+	}
+	r.used[match] = true
+	in := r.cassette.Interactions[match]
+	r.learnURL(in.Request.URL, req.URL)
+	r.learnBody(in.Request.Body, body, in.Request.ContentType)
+	respBody := placeholderRegexp.ReplaceAllStringFunc(in.Response.Body, func(p string) string {
+		v, ok := r.values[p]
+		if !ok {
+			return p
+		}
+		e, _ := json.Marshal(v)
+		return string(e[1 : len(e)-1])
+	})
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(strings.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}
+	for k, v := range in.Response.Headers {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
+
+// learnURL records the live values of the placeholders in a recorded URL.
+func (r *Recorder) learnURL(recorded string, live *url.URL) {
+	ru, err := url.Parse(recorded)
+	if err != nil {
+		return
+	}
+	for _, re := range sensitivePaths {
+		rm := re.FindStringSubmatch(ru.Path)
+		lm := re.FindStringSubmatch(live.Path)
+		if rm != nil && lm != nil {
+			r.learn(rm[1], lm[1])
+		}
+	}
+	r.learnValues(ru.Query(), live.Query())
+}
+
+// learnBody records the live values of the placeholders in a recorded body.
+func (r *Recorder) learnBody(recorded string, live []byte, contentType string) {
+	if isForm(contentType) {
+		rv, rerr := url.ParseQuery(recorded)
+		lv, lerr := url.ParseQuery(string(live))
+		if rerr == nil && lerr == nil {
+			r.learnValues(rv, lv)
+		}
+		return
+	}
+	var rv, lv interface{}
+	if err := json.Unmarshal([]byte(recorded), &rv); err != nil {
+		return
+	}
+	if err := json.Unmarshal(live, &lv); err != nil {
+		return
+	}
+	r.learnJSON(rv, lv)
+}
+
+func (r *Recorder) learnValues(recorded, live url.Values) {
+	for k, rv := range recorded {
+		lv := live[k]
+		for i := range rv {
+			if i < len(lv) {
+				r.learn(rv[i], lv[i])
+			}
+		}
+	}
+}
+
+func (r *Recorder) learnJSON(recorded, live interface{}) {
+	switch rv := recorded.(type) {
+	case map[string]interface{}:
+		lv, ok := live.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for k, v := range rv {
+			r.learnJSON(v, lv[k])
+		}
+	case []interface{}:
+		lv, ok := live.([]interface{})
+		if !ok {
+			return
+		}
+		for i := range rv {
+			if i < len(lv) {
+				r.learnJSON(rv[i], lv[i])
+			}
+		}
+	case string:
+		if lv, ok := live.(string); ok {
+			r.learn(rv, lv)
+		}
+	}
+}
+
+func (r *Recorder) learn(recorded, live string) {
+	if placeholderRegexp.MatchString(recorded) && placeholderRegexp.FindString(recorded) == recorded {
+		r.values[recorded] = live
+	}
+}
+
+// scrubURL returns the path and query of u, with secrets replaced by f.
+func scrubURL(u *url.URL, f func(string) string) string {
+	path := u.Path
+	for _, re := range sensitivePaths {
+		if m := re.FindStringSubmatchIndex(path); m != nil {
+			path = path[:m[2]] + f(path[m[2]:m[3]]) + path[m[3]:]
+		}
+	}
+	if u.RawQuery == "" {
+		return path
+	}
+	return path + "?" + scrubValues(u.Query(), f).Encode()
+}
+
+// scrubBody returns a canonical form of a JSON or form encoded body, with
+// secrets replaced by f. Other bodies are returned as they are.
+func scrubBody(b []byte, contentType string, f func(string) string) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	if isForm(contentType) {
+		v, err := url.ParseQuery(string(b))
+		if err != nil {
+			return b
+		}
+		return []byte(scrubValues(v, f).Encode())
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return b
+	}
+	s, err := json.Marshal(scrubJSON(v, f))
+	if err != nil {
+		return b
+	}
+	return s
+}
+
+func scrubValues(v url.Values, f func(string) string) url.Values {
+	for _, k := range sortedKeys(v) {
+		if !sensitiveKeys[strings.ToLower(k)] {
+			continue
+		}
+		for i := range v[k] {
+			v[k][i] = f(v[k][i])
+		}
+	}
+	return v
+}
+
+func scrubJSON(v interface{}, f func(string) string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(t) {
+			e := t[k]
+			if s, ok := e.(string); ok && s != "" && sensitiveKeys[strings.ToLower(k)] {
+				t[k] = f(s)
+				continue
+			}
+			t[k] = scrubJSON(e, f)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = scrubJSON(t[i], f)
+		}
+	}
+	return v
+}
+
+// sortedKeys returns the keys of a url.Values or JSON object in order, so that
+// placeholders are numbered the same way every time a test is recorded.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case url.Values:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isForm(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+	return err == nil && t == "application/x-www-form-urlencoded"
+}
@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/login":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("newTestServer: %v", err)
+			}
+			w.Write([]byte(`{"data":{"token":"secret-token"}}`)) // nolint: errcheck
+		case "/v2/networkdesign/buy":
+			var v []map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				t.Errorf("newTestServer: %v", err)
+			}
+			w.Write([]byte(`{"data":[{"technicalServiceUid":"uid-1","ownerAccount":"` + v[0]["ownerAccount"].(string) + `"}]}`)) // nolint: errcheck
+		case "/v2/product/uid-1":
+			w.Write([]byte(`{"data":{"productUid":"uid-1","ownerAccount":"123456789012","authKey":"generated-key"}}`)) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func doRequest(t *testing.T, c *http.Client, method, u, contentType, body string) string {
+	req, err := http.NewRequest(method, u, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Auth-Token", "secret-token")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("doRequest: %s %s: %v", method, u, err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRecorder(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+	path := filepath.Join(t.TempDir(), "cassettes", "TestRecorder.json")
+
+	rec, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &http.Client{Transport: rec}
+	doRequest(t, c, http.MethodPost, s.URL+"/v2/login", "application/x-www-form-urlencoded",
+		url.Values{"username": {"foo"}, "password": {"bar"}}.Encode())
+	doRequest(t, c, http.MethodPost, s.URL+"/v2/networkdesign/buy", "application/json",
+		`[{"productName":"test","ownerAccount":"123456789012"}]`)
+	if got := doRequest(t, c, http.MethodGet, s.URL+"/v2/product/uid-1", "", ""); !strings.Contains(got, "generated-key") {
+		t.Errorf("TestRecorder: unexpected response while recording: %s", got)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"foo", "bar", "secret-token", "123456789012", "generated-key", "X-Auth-Token"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("TestRecorder: cassette contains %q:\n%s", secret, b)
+		}
+	}
+
+	rep, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = &http.Client{Transport: rep}
+	s.Close()
+	if got := doRequest(t, c, http.MethodPost, s.URL+"/v2/login", "application/x-www-form-urlencoded",
+		url.Values{"username": {"baz"}, "password": {"qux"}}.Encode()); got != `{"data":{"token":"REDACTED-0003"}}` {
+		t.Errorf("TestRecorder: unexpected login response: %s", got)
+	}
+	got := doRequest(t, c, http.MethodPost, s.URL+"/v2/networkdesign/buy", "application/json",
+		`[{"ownerAccount":"210987654321","productName":"test"}]`)
+	if !strings.Contains(got, `"ownerAccount":"210987654321"`) {
+		t.Errorf("TestRecorder: the replayed response does not use the live account id: %s", got)
+	}
+	for i := 0; i < 2; i++ {
+		got = doRequest(t, c, http.MethodGet, s.URL+"/v2/product/uid-1", "", "")
+		if !strings.Contains(got, `"ownerAccount":"210987654321"`) {
+			t.Errorf("TestRecorder: the replayed response does not use the live account id: %s", got)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodPost, s.URL+"/v2/networkdesign/buy", strings.NewReader(`[{"productName":"other"}]`))
+	if _, err := c.Do(req); err == nil {
+		t.Errorf("TestRecorder: expected an error for a request that was not recorded")
+	}
+	if err := rep.Stop(); err != nil {
+		t.Errorf("TestRecorder: %v", err)
+	}
+}
+
+func TestScrubURL(t *testing.T) {
+	u, err := url.Parse("https://api.megaport.com/v2/secure/google/7e51371e-72a3-40b5-b844-2e3efefaee59/europe-west2/1?username=foo&x=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "/v2/secure/google/REDACTED?username=REDACTED&x=1"
+	if got := scrubURL(u, func(string) string { return redacted }); got != expected {
+		t.Errorf("TestScrubURL: got %q, expected %q", got, expected)
+	}
+}
+
+func TestNew_unknownMode(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "foo.json"), Mode("foo")); err == nil {
+		t.Errorf("TestNew_unknownMode: expected an error")
+	}
+}
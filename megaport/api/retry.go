@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 4
+	DefaultRetryMinWait = 1 * time.Second
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// isRetryableRequest reports whether a request can be safely sent more than
+// once. Reads are always safe to repeat, and so is validating a network
+// design since it does not modify anything. Anything else, most notably
+// networkdesign/buy, could end up ordering (and paying for) the same product
+// twice so it is never retried.
+func isRetryableRequest(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return true
+	}
+	return req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/v2/networkdesign/validate")
+}
+
+// isRetryableResponse reports whether the outcome of a request indicates a
+// transient failure that is worth retrying.
+func isRetryableResponse(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// retryWait returns how long to wait before the next attempt. A Retry-After
+// header sent by the API takes precedence over the exponential backoff, but
+// both are capped at RetryMaxWait.
+func (c *Client) retryWait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > c.RetryMaxWait {
+				return c.RetryMaxWait
+			}
+			return d
+		}
+	}
+	d := time.Duration(float64(c.RetryMinWait) * math.Pow(2, float64(attempt)))
+	if d <= 0 || d > c.RetryMaxWait {
+		d = c.RetryMaxWait
+	}
+	// Add jitter so that concurrent callers do not retry in lockstep
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func drainResponseBody(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+	resp.Body.Close()
+}
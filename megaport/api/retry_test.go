@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_doRetries(t *testing.T) {
+	testCases := []struct {
+		method   string
+		path     string
+		status   int
+		attempts int
+	}{
+		{http.MethodGet, "/v2/product/foo", http.StatusServiceUnavailable, 3},
+		{http.MethodGet, "/v2/product/foo", http.StatusTooManyRequests, 3},
+		{http.MethodGet, "/v2/product/foo", http.StatusBadRequest, 1},
+		{http.MethodGet, "/v2/product/foo", http.StatusNotImplemented, 1},
+		{http.MethodPost, "/v2/networkdesign/validate", http.StatusBadGateway, 3},
+		{http.MethodPost, "/v2/networkdesign/buy", http.StatusBadGateway, 1},
+		{http.MethodPut, "/v2/product/vxc/foo", http.StatusBadGateway, 1},
+	}
+	for i, tc := range testCases {
+		attempts := 0
+		c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("TestClient_doRetries: %v", err)
+			}
+			if string(body) != "{}" {
+				t.Errorf("TestClient_doRetries (#%d): unexpected body on attempt %d: got '%s', expected '{}'", i, attempts, body)
+			}
+			w.WriteHeader(tc.status)
+			fmt.Fprint(w, `{"message":"foo"}`)
+		})
+		c.MaxRetries = 2
+		c.RetryMinWait = time.Millisecond
+		c.RetryMaxWait = 10 * time.Millisecond
+		req, err := http.NewRequest(tc.method, s.URL+tc.path, bytes.NewReader([]byte("{}")))
+		if err != nil {
+			t.Fatalf("TestClient_doRetries: %v", err)
+		}
+		if err := c.do(context.Background(), req, nil); err == nil {
+			t.Errorf("TestClient_doRetries (#%d): expected an error but did not get one", i)
+		}
+		if attempts != tc.attempts {
+			t.Errorf("TestClient_doRetries (#%d): unexpected number of attempts: got %d, expected %d", i, attempts, tc.attempts)
+		}
+		s.Close()
+	}
+}
+
+func TestClient_doRetriesEventuallySucceeds(t *testing.T) {
+	attempts := 0
+	c, s := testClientServer(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message":"slow down"}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"data":"foo"}`)
+	})
+	defer s.Close()
+	c.RetryMinWait = time.Hour // Retry-After must take precedence
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("TestClient_doRetriesEventuallySucceeds: %v", err)
+	}
+	data := ""
+	if err := c.do(context.Background(), req, &data); err != nil {
+		t.Errorf("TestClient_doRetriesEventuallySucceeds: %v", err)
+	}
+	if data != "foo" {
+		t.Errorf("TestClient_doRetriesEventuallySucceeds: unexpected data: got '%s', expected 'foo'", data)
+	}
+}
+
+func TestClient_retryWait(t *testing.T) {
+	c := NewClient("")
+	c.RetryMinWait = time.Second
+	c.RetryMaxWait = 10 * time.Second
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if w := c.retryWait(attempt, nil); w < max/2 || w > max {
+			t.Errorf("TestClient_retryWait: unexpected wait for attempt %d: got %s, expected between %s and %s", attempt, w, max/2, max)
+		}
+	}
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "3")
+	if w := c.retryWait(0, resp); w != 3*time.Second {
+		t.Errorf("TestClient_retryWait: unexpected wait with Retry-After: got %s, expected %s", w, 3*time.Second)
+	}
+	resp.Header.Set("Retry-After", "60")
+	if w := c.retryWait(0, resp); w != c.RetryMaxWait {
+		t.Errorf("TestClient_retryWait: unexpected wait with Retry-After: got %s, expected %s", w, c.RetryMaxWait)
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(5*time.Second).UTC().Format(http.TimeFormat))
+	if w := c.retryWait(0, resp); w <= 3*time.Second || w > 5*time.Second {
+		t.Errorf("TestClient_retryWait: unexpected wait with Retry-After date: got %s, expected about %s", w, 5*time.Second)
+	}
+}
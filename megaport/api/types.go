@@ -3,12 +3,15 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 const (
 	VxcTypePrivate = "private"
 	VxcTypeAws     = "aws"
+	VxcTypeAzure   = "azure"
 	VxcTypeGcp     = "gcp"
+	VxcTypeOracle  = "oracle"
 	VxcTypePartner = "partner"
 )
 
@@ -92,7 +95,7 @@ type MegaportCloud struct {
 	ProductId   uint64
 	ProductUid  string
 	State       string      // This refers to the geographical location
-	Type        string      // Potentially only used for Oracle ports
+	Type        string      // "primary" or "secondary", only used for Azure and Oracle ports
 	Vxc         interface{} // TODO: what is the appropriate type?
 }
 
@@ -115,10 +118,26 @@ type InternetExchangeIPAddress struct {
 	Value string
 }
 
+// McrPrefixFilterList data, where Entries is only set when the list is
+// retrieved on its own
+type McrPrefixFilterList struct {
+	AddressFamily string
+	Description   string
+	Entries       []*McrPrefixFilterListEntry
+	Id            uint64
+}
+
+type McrPrefixFilterListEntry struct {
+	Action string
+	Ge     uint64
+	Le     uint64
+	Prefix string
+}
+
 type Product struct {
 	AdminLocked bool
 	// AggregationId // TODO: haven't seen a value other than null
-	// AssociatedIxs []ProductsAssociatedIx // TODO: haven't seen a value other than an empty list
+	AssociatedIxs  []ProductAssociatedIx
 	AssociatedVxcs []ProductAssociatedVxc
 	// AttributeTags // TODO: haven't seen a value other than an empty map
 	BuyoutPort         bool
@@ -131,13 +150,16 @@ type Product struct {
 	CostCentre         string
 	CreateDate         uint64
 	CreatedBy          string
-	// LagId // TODO: haven't seen a value other than null
+	// LagId is only set for the ports of a LAG, and LagPrimary for the
+	// primary port of the LAG
+	LagId                 uint64
 	LagPrimary            bool
 	LiveDate              uint64
 	LocationId            uint64
 	Locked                bool
 	Market                string
 	MarketplaceVisibility bool
+	MveSize               string
 	PortSpeed             uint64
 	ProductName           string
 	ProductType           string
@@ -145,18 +167,37 @@ type Product struct {
 	ProvisioningStatus    string
 	Resources             ProductResources
 	// SecondaryName // TODO: haven't seen a value other than null
-	// TerminateDate // TODO: haven't seen a value other than null
+	// TerminateDate is only set for products that have been cancelled
+	TerminateDate uint64
 	// UsageAlgorithm // TODO: haven't seen a value other than null
+	// Vendor, MveSize and Vnics are only set for MVEs
+	Vendor          string
 	Virtual         bool
+	Vnics           []ProductVnic
 	VxcPermitted    bool
 	VxcAutoApproval bool
 }
 
+// CancellationPending reports whether the product has been cancelled at the
+// end of its contract term, which has not been reached yet.
+func (p *Product) CancellationPending() bool {
+	return p.ProvisioningStatus == ProductStatusCancelled &&
+		p.TerminateDate > uint64(time.Now().UnixNano()/int64(time.Millisecond))
+}
+
+// ProductVnic is a virtual network interface of an MVE, which VXCs connect to
+// by its index
+type ProductVnic struct {
+	Description string
+	Vlan        uint64
+}
+
 type ProductResources struct { // TODO: verify these are the only valid fields
 	// CrossConnect  ProductResourcesCrossConnect `json:"cross_connect"` // TODO: only referenced in https://dev.megaport.com/#general-get-product-list
-	Interface     ProductResourcesInterface
-	VirtualRouter ProductResourcesVirtualRouter `json:"virtual_router"`
-	VLL           ProductResourcesVLL
+	Interface      ProductResourcesInterface
+	VirtualMachine []ProductResourcesVirtualMachine `json:"virtual_machine"`
+	VirtualRouter  ProductResourcesVirtualRouter    `json:"virtual_router"`
+	VLL            ProductResourcesVLL
 }
 
 type ProductResourcesInterface struct {
@@ -197,6 +238,23 @@ func (pr *ProductResourcesInterface) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
+// ProductResourcesVirtualMachine is the virtual machine of an MVE
+type ProductResourcesVirtualMachine struct {
+	CpuCount     uint64 `json:"cpu_count"`
+	Id           uint64
+	Image        ProductResourcesVirtualMachineImage
+	Name         string
+	ResourceName string `json:"resource_name"`
+	ResourceType string `json:"resource_type"`
+}
+
+type ProductResourcesVirtualMachineImage struct {
+	Id      uint64
+	Product string
+	Vendor  string
+	Version string
+}
+
 type ProductResourcesVirtualRouter struct {
 	Id           uint64 `json:"-"`
 	McrASN       uint64 `json:"-"`
@@ -270,6 +328,48 @@ func (pr *ProductResourcesVLL) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
+// ProductAssociatedIx is a connection from a port to an Internet Exchange,
+// where NetworkServiceType is the name of the Internet Exchange
+type ProductAssociatedIx struct {
+	Asn                uint64
+	CostCentre         string
+	CreateDate         uint64
+	LocationId         uint64
+	MacAddress         string
+	NetworkServiceType string
+	ProductName        string
+	ProductType        string
+	ProductUid         string
+	ProvisioningStatus string
+	RateLimit          uint64
+	Resources          ProductAssociatedIxResources
+	Vlan               uint64
+}
+
+type ProductAssociatedIxResources struct {
+	IpAddresses []ProductAssociatedIxResourcesIpAddress `json:"ip_address"`
+}
+
+// ProductAssociatedIxResourcesIpAddress is a peering address assigned to the
+// connection, where Version is either 4 or 6
+type ProductAssociatedIxResourcesIpAddress struct {
+	Address      string
+	ResourceName string `json:"resource_name"`
+	ResourceType string `json:"resource_type"`
+	Version      uint64
+}
+
+// IpAddresses returns the peering addresses of the given IP version.
+func (v *ProductAssociatedIx) IpAddresses(version uint64) []string {
+	a := []string{}
+	for _, ip := range v.Resources.IpAddresses {
+		if ip.Version == version {
+			a = append(a, ip.Address)
+		}
+	}
+	return a
+}
+
 type ProductAssociatedVxc struct {
 	AdminLocked bool
 	// AttributeTags // TODO: haven't seen a value other than an empty map
@@ -305,9 +405,15 @@ func (v *ProductAssociatedVxc) Type() string {
 			if c, ok := c.(*ProductAssociatedVxcResourcesCspConnectionAws); ok && c.ConnectType == VxcConnectTypeAws {
 				return VxcTypeAws
 			}
+			if c, ok := c.(*ProductAssociatedVxcResourcesCspConnectionAzure); ok && c.ConnectType == VxcConnectTypeAzure {
+				return VxcTypeAzure
+			}
 			if c, ok := c.(*ProductAssociatedVxcResourcesCspConnectionGcp); ok && c.ConnectType == VxcConnectTypeGoogle {
 				return VxcTypeGcp
 			}
+			if c, ok := c.(*ProductAssociatedVxcResourcesCspConnectionOracle); ok && c.ConnectType == VxcConnectTypeOracle {
+				return VxcTypeOracle
+			}
 		}
 	}
 	return VxcTypePartner
@@ -320,6 +426,8 @@ type ProductAssociatedVxcEnd struct {
 	ProductUid  string
 	ProductName string
 	Vlan        uint64
+	// VnicIndex is the vNIC of the end when it is an MVE
+	VnicIndex uint64 `json:"vNicIndex"`
 	// SecondaryName // TODO: haven't seen a value other than null
 }
 
@@ -344,6 +452,18 @@ func (pr *ProductAssociatedVxcResources) GetCspConnection(connectType string) Cs
 	return nil
 }
 
+// GetVRouterCspConnection returns the CSP connection of the MCR at the given
+// end of the VXC, where resourceName is "a_csp_connection" or
+// "b_csp_connection".
+func (pr *ProductAssociatedVxcResources) GetVRouterCspConnection(resourceName string) *ProductAssociatedVxcResourcesCspConnectionVRouter {
+	for _, c := range pr.CspConnection {
+		if c, ok := c.(*ProductAssociatedVxcResourcesCspConnectionVRouter); ok && c.ResourceName == resourceName {
+			return c
+		}
+	}
+	return nil
+}
+
 func (pr *ProductAssociatedVxcResources) UnmarshalJSON(b []byte) (err error) {
 	ccs := []CspConnection{}
 	ccr := struct {
@@ -367,8 +487,12 @@ func (pr *ProductAssociatedVxcResources) UnmarshalJSON(b []byte) (err error) {
 		switch t := ct.ConnectType; t {
 		case VxcConnectTypeAws:
 			cc = &ProductAssociatedVxcResourcesCspConnectionAws{}
+		case VxcConnectTypeAzure:
+			cc = &ProductAssociatedVxcResourcesCspConnectionAzure{}
 		case VxcConnectTypeGoogle:
 			cc = &ProductAssociatedVxcResourcesCspConnectionGcp{}
+		case VxcConnectTypeOracle:
+			cc = &ProductAssociatedVxcResourcesCspConnectionOracle{}
 		case VxcConnectTypeVRouter:
 			cc = &ProductAssociatedVxcResourcesCspConnectionVRouter{}
 		default:
@@ -443,6 +567,38 @@ func (pr *ProductAssociatedVxcResourcesCspConnectionAws) UnmarshalJSON(b []byte)
 	return nil
 }
 
+type ProductAssociatedVxcResourcesCspConnectionAzure struct {
+	ConnectType string
+	Managed     bool
+	Megaports   []ProductAssociatedVxcResourcesCspConnectionAzureMegaports
+	// Peers is only populated for circuits whose peerings are managed by Megaport
+	Peers        []ProductAssociatedVxcResourcesCspConnectionAzurePeer
+	ResourceName string `json:"resource_name"`
+	ResourceType string `json:"resource_type"`
+	ServiceKey   string `json:"service_key"`
+	Vlan         uint64
+}
+
+func (c ProductAssociatedVxcResourcesCspConnectionAzure) connectType() string {
+	return VxcConnectTypeAzure
+}
+
+type ProductAssociatedVxcResourcesCspConnectionAzureMegaports struct {
+	Port uint64
+	Type string // "primary" or "secondary"
+	Vxc  uint64
+}
+
+type ProductAssociatedVxcResourcesCspConnectionAzurePeer struct {
+	PeerAsn         string `json:"peer_asn"`
+	Prefixes        string
+	PrimarySubnet   string `json:"primary_subnet"`
+	SecondarySubnet string `json:"secondary_subnet"`
+	SharedKey       string `json:"shared_key"`
+	Type            string
+	Vlan            uint64
+}
+
 type ProductAssociatedVxcResourcesCspConnectionGcp struct {
 	Bandwidth    uint64
 	Bandwidths   []uint64
@@ -458,6 +614,26 @@ func (c ProductAssociatedVxcResourcesCspConnectionGcp) connectType() string {
 	return VxcConnectTypeGoogle
 }
 
+type ProductAssociatedVxcResourcesCspConnectionOracle struct {
+	Bandwidth        uint64
+	Bandwidths       []uint64
+	ConnectType      string
+	CspName          string `json:"csp_name"`
+	Megaports        []ProductAssociatedVxcResourcesCspConnectionOracleMegaports
+	ResourceName     string `json:"resource_name"`
+	ResourceType     string `json:"resource_type"`
+	VirtualCircuitId string
+}
+
+func (c ProductAssociatedVxcResourcesCspConnectionOracle) connectType() string {
+	return VxcConnectTypeOracle
+}
+
+type ProductAssociatedVxcResourcesCspConnectionOracleMegaports struct {
+	Port uint64
+	Vxc  uint64
+}
+
 type ProductAssociatedVxcResourcesCspConnectionVRouter struct {
 	ConnectType       string
 	BGPPeers          []string          `json:"bgp_peers"`
@@ -475,7 +651,35 @@ func (c ProductAssociatedVxcResourcesCspConnectionVRouter) connectType() string
 	return VxcConnectTypeVRouter
 }
 
-type ProductAssociatedVxcResourcesCspConnectionVRouterInterfaces struct{}
+type ProductAssociatedVxcResourcesCspConnectionVRouterInterfaces struct {
+	Bfd            *ProductAssociatedVxcResourcesCspConnectionVRouterBfd
+	BgpConnections []ProductAssociatedVxcResourcesCspConnectionVRouterBgpConnection
+	IpAddresses    []string
+	NatIpAddresses []string
+}
+
+type ProductAssociatedVxcResourcesCspConnectionVRouterBfd struct {
+	Multiplier uint64
+	RxInterval uint64
+	TxInterval uint64
+}
+
+type ProductAssociatedVxcResourcesCspConnectionVRouterBgpConnection struct {
+	AsPathPrependCount uint64
+	BfdEnabled         bool
+	Description        string
+	ExportBlacklist    uint64
+	ExportWhitelist    uint64
+	ImportBlacklist    uint64
+	ImportWhitelist    uint64
+	LocalIpAddress     string
+	MedIn              uint64
+	MedOut             uint64
+	Password           string
+	PeerAsn            uint64
+	PeerIpAddress      string
+	Shutdown           bool
+}
 
 type ProductAssociatedVxcResourcesCspConnectionGcpMegaports struct {
 	Port uint64
@@ -25,6 +25,65 @@ func TestProductAssociatedVxcResources_UnmarshalJSON(t *testing.T) {
 				&ProductAssociatedVxcResourcesCspConnectionGcp{ConnectType: VxcConnectTypeGoogle},
 			}},
 		},
+		{
+			`{"csp_connection":{"connectType":"AZURE","managed":true,"service_key":"foo","vlan":100,"megaports":[{"port":1,"type":"primary","vxc":2}],"peers":[{"type":"private","peer_asn":"64512","primary_subnet":"10.0.0.0/30","secondary_subnet":"10.0.0.4/30","vlan":200}]}}`,
+			ProductAssociatedVxcResources{CspConnection: []CspConnection{
+				&ProductAssociatedVxcResourcesCspConnectionAzure{
+					ConnectType: VxcConnectTypeAzure,
+					Managed:     true,
+					Megaports:   []ProductAssociatedVxcResourcesCspConnectionAzureMegaports{{Port: 1, Type: "primary", Vxc: 2}},
+					Peers: []ProductAssociatedVxcResourcesCspConnectionAzurePeer{{
+						PeerAsn:         "64512",
+						PrimarySubnet:   "10.0.0.0/30",
+						SecondarySubnet: "10.0.0.4/30",
+						Type:            "private",
+						Vlan:            200,
+					}},
+					ServiceKey: "foo",
+					Vlan:       100,
+				},
+			}},
+		},
+		{
+			`{"csp_connection":{"connectType":"ORACLE","bandwidth":1000,"bandwidths":[1000,2000],"csp_name":"Oracle","megaports":[{"port":1,"vxc":2}],"resource_name":"b_csp_connection","resource_type":"csp_connection","virtualCircuitId":"ocid1.virtualcircuit.oc1.uk-london-1.foo"}}`,
+			ProductAssociatedVxcResources{CspConnection: []CspConnection{
+				&ProductAssociatedVxcResourcesCspConnectionOracle{
+					Bandwidth:        1000,
+					Bandwidths:       []uint64{1000, 2000},
+					ConnectType:      VxcConnectTypeOracle,
+					CspName:          "Oracle",
+					Megaports:        []ProductAssociatedVxcResourcesCspConnectionOracleMegaports{{Port: 1, Vxc: 2}},
+					ResourceName:     "b_csp_connection",
+					ResourceType:     "csp_connection",
+					VirtualCircuitId: "ocid1.virtualcircuit.oc1.uk-london-1.foo",
+				},
+			}},
+		},
+		{
+			`{"csp_connection":{"connectType":"VROUTER","resource_name":"a_csp_connection","resource_type":"csp_connection","virtualRouterId":1,"virtualRouterName":"foo","vlan":100,"interfaces":[{"ipAddresses":["10.0.0.1/30"],"bfd":{"txInterval":300,"rxInterval":300,"multiplier":3},"bgpConnections":[{"peerAsn":64512,"localIpAddress":"10.0.0.1","peerIpAddress":"10.0.0.2","medIn":100,"bfdEnabled":true,"importWhitelist":10}]}]}}`,
+			ProductAssociatedVxcResources{CspConnection: []CspConnection{
+				&ProductAssociatedVxcResourcesCspConnectionVRouter{
+					ConnectType: VxcConnectTypeVRouter,
+					Interfaces: []ProductAssociatedVxcResourcesCspConnectionVRouterInterfaces{{
+						Bfd: &ProductAssociatedVxcResourcesCspConnectionVRouterBfd{Multiplier: 3, RxInterval: 300, TxInterval: 300},
+						BgpConnections: []ProductAssociatedVxcResourcesCspConnectionVRouterBgpConnection{{
+							BfdEnabled:      true,
+							ImportWhitelist: 10,
+							LocalIpAddress:  "10.0.0.1",
+							MedIn:           100,
+							PeerAsn:         64512,
+							PeerIpAddress:   "10.0.0.2",
+						}},
+						IpAddresses: []string{"10.0.0.1/30"},
+					}},
+					ResourceName:      "a_csp_connection",
+					ResourceType:      "csp_connection",
+					VirtualRouterId:   1,
+					VirtualRouterName: "foo",
+					Vlan:              100,
+				},
+			}},
+		},
 	}
 	for i, test := range tc {
 		v := ProductAssociatedVxcResources{}
@@ -42,3 +101,16 @@ func TestProductAssociatedVxcResources_UnmarshalJSON(t *testing.T) {
 		t.Errorf("TestProduct_UnmarshalJSON: expected an error but did not get one")
 	}
 }
+
+func TestProductAssociatedVxcResources_GetVRouterCspConnection(t *testing.T) {
+	v := ProductAssociatedVxcResources{}
+	if err := json.Unmarshal([]byte(`{"csp_connection":[{"connectType":"VROUTER","resource_name":"b_csp_connection","vlan":2},{"connectType":"VROUTER","resource_name":"a_csp_connection","vlan":1}]}`), &v); err != nil {
+		t.Fatalf("TestProductAssociatedVxcResources_GetVRouterCspConnection: %v", err)
+	}
+	if c := v.GetVRouterCspConnection("a_csp_connection"); c == nil || c.Vlan != 1 {
+		t.Errorf("TestProductAssociatedVxcResources_GetVRouterCspConnection: unexpected A-End connection: %#v", c)
+	}
+	if c := v.GetVRouterCspConnection("c_csp_connection"); c != nil {
+		t.Errorf("TestProductAssociatedVxcResources_GetVRouterCspConnection: expected no connection but got %#v", c)
+	}
+}
@@ -2,15 +2,19 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
 const (
 	VxcConnectTypeAws     = "AWS"
+	VxcConnectTypeAzure   = "AZURE"
 	VxcConnectTypeGoogle  = "GOOGLE"
+	VxcConnectTypeOracle  = "ORACLE"
 	VxcConnectTypeVRouter = "VROUTER"
 )
 
@@ -19,45 +23,51 @@ type networkDesignInput interface {
 	productType() string
 }
 
-func (c *Client) create(v networkDesignInput) ([]map[string]interface{}, error) {
-	payload, err := v.toPayload()
-	if err != nil {
+func (c *Client) create(ctx context.Context, v networkDesignInput) ([]map[string]interface{}, error) {
+	if err := c.validate(ctx, v); err != nil {
 		return nil, err
 	}
-	b := bytes.NewReader(payload)
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/networkdesign/validate", c.BaseURL), b)
+	payload, err := v.toPayload()
 	if err != nil {
 		return nil, err
 	}
-	if err := c.do(req, nil); err != nil {
-		return nil, err
-	}
-	if _, err := b.Seek(0, 0); err != nil {
-		return nil, err
-	}
-	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/networkdesign/buy", c.BaseURL), b)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/networkdesign/buy", c.BaseURL), bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
 	d := []map[string]interface{}{}
-	if err := c.do(req, &d); err != nil {
+	if err := c.do(ctx, req, &d); err != nil {
 		return nil, err
 	}
 	return d, nil
 }
 
-func (c *Client) get(uid string, v interface{}) error {
+// validate checks an order with Megaport without buying it. The returned
+// error holds the per-field errors of invalid orders.
+func (c *Client) validate(ctx context.Context, v networkDesignInput) error {
+	payload, err := v.toPayload()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/networkdesign/validate", c.BaseURL), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, nil)
+}
+
+func (c *Client) get(ctx context.Context, uid string, v interface{}) error {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/product/%s", c.BaseURL, uid), nil)
 	if err != nil {
 		return err
 	}
-	if err := c.do(req, v); err != nil {
+	if err := c.do(ctx, req, v); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Client) update(uid string, v networkDesignInput) error {
+func (c *Client) update(ctx context.Context, uid string, v networkDesignInput) error {
 	payload, err := v.toPayload()
 	if err != nil {
 		return err
@@ -67,18 +77,32 @@ func (c *Client) update(uid string, v networkDesignInput) error {
 	if err != nil {
 		return err
 	}
-	if err := c.do(req, nil); err != nil {
+	if err := c.do(ctx, req, nil); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Client) delete(uid string) error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/product/%s/action/CANCEL_NOW", c.BaseURL, uid), nil)
+func (c *Client) delete(ctx context.Context, uid string) error {
+	return c.action(ctx, uid, productActionCancelNow)
+}
+
+// cancel cancels a product at the end of its contract term, until when it can
+// be restored.
+func (c *Client) cancel(ctx context.Context, uid string) error {
+	return c.action(ctx, uid, productActionCancel)
+}
+
+func (c *Client) restore(ctx context.Context, uid string) error {
+	return c.action(ctx, uid, productActionUncancel)
+}
+
+func (c *Client) action(ctx context.Context, uid, action string) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/product/%s/action/%s", c.BaseURL, uid, action), nil)
 	if err != nil {
 		return err
 	}
-	if err := c.do(req, nil); err != nil {
+	if err := c.do(ctx, req, nil); err != nil {
 		return err
 	}
 	return nil
@@ -99,18 +123,22 @@ type vxcCreatePayloadAssociatedVxc struct {
 }
 
 type vxcCreatePayloadVxcEnd struct {
-	ProductUid *string `json:"productUid,omitempty"`
-	Vlan       *uint64 `json:"vlan,omitempty"`
+	PartnerConfig interface{} `json:"partnerConfig,omitempty"`
+	ProductUid    *string     `json:"productUid,omitempty"`
+	Vlan          *uint64     `json:"vlan,omitempty"`
+	VnicIndex     *uint64     `json:"vNicIndex,omitempty"`
 }
 
 type PrivateVxcCreateInput struct {
 	InvoiceReference *string
 	Name             *string
+	PartnerConfigA   PartnerConfig
 	ProductUidA      *string
 	ProductUidB      *string
 	RateLimit        *uint64
 	VlanA            *uint64
 	VlanB            *uint64
+	VnicIndexA       *uint64
 }
 
 func (v *PrivateVxcCreateInput) productType() string {
@@ -124,9 +152,7 @@ func (v *PrivateVxcCreateInput) toPayload() ([]byte, error) {
 		RateLimit:   v.RateLimit,
 		CostCentre:  v.InvoiceReference,
 	}
-	if v.VlanA != nil {
-		av.AEnd = &vxcCreatePayloadVxcEnd{Vlan: v.VlanA}
-	}
+	av.AEnd = newVxcCreatePayloadAEnd(v.VlanA, v.VnicIndexA, v.PartnerConfigA)
 	bEnd := &vxcCreatePayloadVxcEnd{ProductUid: v.ProductUidB, Vlan: v.VlanB}
 	if *bEnd != (vxcCreatePayloadVxcEnd{}) {
 		av.BEnd = bEnd
@@ -137,7 +163,21 @@ func (v *PrivateVxcCreateInput) toPayload() ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+// newVxcCreatePayloadAEnd returns the A-End of a VXC order, or nil when
+// neither the VLAN, the vNIC of an MVE nor the partner configuration are set.
+func newVxcCreatePayloadAEnd(vlan, vnicIndex *uint64, pc PartnerConfig) *vxcCreatePayloadVxcEnd {
+	if vlan == nil && vnicIndex == nil && pc == nil {
+		return nil
+	}
+	e := &vxcCreatePayloadVxcEnd{Vlan: vlan, VnicIndex: vnicIndex}
+	if pc != nil {
+		e.PartnerConfig = pc.toPayload()
+	}
+	return e
+}
+
 type vxcUpdatePayload struct {
+	AEndConfig interface{} `json:"aEndConfig,omitempty"`
 	AEndVlan   *uint64     `json:"aEndVlan,omitempty"`
 	BEndVlan   *uint64     `json:"bEndVlan,omitempty"`
 	CostCentre *string     `json:"costCentre,omitempty"`
@@ -150,6 +190,7 @@ type vxcUpdatePayload struct {
 type PrivateVxcUpdateInput struct {
 	InvoiceReference *string
 	Name             *string
+	PartnerConfigA   PartnerConfig
 	ProductUid       *string
 	RateLimit        *uint64
 	VlanA            *uint64
@@ -168,11 +209,14 @@ func (v *PrivateVxcUpdateInput) toPayload() ([]byte, error) {
 		Name:       v.Name,
 		RateLimit:  v.RateLimit,
 	}
+	if v.PartnerConfigA != nil {
+		payload.AEndConfig = v.PartnerConfigA.toPayload()
+	}
 	return json.Marshal(payload)
 }
 
-func (c *Client) CreatePrivateVxc(v *PrivateVxcCreateInput) (*string, error) {
-	d, err := c.create(v)
+func (c *Client) CreatePrivateVxc(ctx context.Context, v *PrivateVxcCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
 	if err != nil {
 		return nil, err
 	}
@@ -180,18 +224,23 @@ func (c *Client) CreatePrivateVxc(v *PrivateVxcCreateInput) (*string, error) {
 	return &uid, nil
 }
 
-func (c *Client) GetVxc(uid string) (*ProductAssociatedVxc, error) { // TODO: rename struct
+// ValidatePrivateVxc checks the order of a private VXC without buying it.
+func (c *Client) ValidatePrivateVxc(ctx context.Context, v *PrivateVxcCreateInput) error {
+	return c.validate(ctx, v)
+}
+
+func (c *Client) GetVxc(ctx context.Context, uid string) (*ProductAssociatedVxc, error) { // TODO: rename struct
 	d := &ProductAssociatedVxc{}
-	err := c.get(uid, d)
+	err := c.get(ctx, uid, d)
 	return d, err
 }
 
-func (c *Client) DeleteVxc(uid string) error {
-	return c.delete(uid)
+func (c *Client) DeleteVxc(ctx context.Context, uid string) error {
+	return c.delete(ctx, uid)
 }
 
-func (c *Client) UpdatePrivateVxc(v *PrivateVxcUpdateInput) error {
-	return c.update(*v.ProductUid, v)
+func (c *Client) UpdatePrivateVxc(ctx context.Context, v *PrivateVxcUpdateInput) error {
+	return c.update(ctx, *v.ProductUid, v)
 }
 
 type PartnerConfig interface {
@@ -262,14 +311,216 @@ type vxcCreatePayloadPartnerConfigGcp struct {
 	PairingKey  *string `json:"pairingKey,omitempty"`
 }
 
+type PartnerConfigOracle struct {
+	VirtualCircuitId *string
+}
+
+func (v *PartnerConfigOracle) connectType() string {
+	return "ORACLE"
+}
+
+func (v *PartnerConfigOracle) toPayload() interface{} {
+	return &vxcCreatePayloadPartnerConfigOracle{
+		ConnectType:      String(v.connectType()),
+		VirtualCircuitId: v.VirtualCircuitId,
+	}
+}
+
+type vxcCreatePayloadPartnerConfigOracle struct {
+	ConnectType      *string `json:"connectType,omitempty"`
+	VirtualCircuitId *string `json:"virtualCircuitId,omitempty"`
+}
+
+type PartnerConfigAzure struct {
+	Peers      []*PartnerConfigAzurePeer
+	ServiceKey *string
+}
+
+// PartnerConfigAzurePeer configures one of the peerings of an ExpressRoute
+// circuit, which is either "private" or "microsoft".
+type PartnerConfigAzurePeer struct {
+	PeerASN         *uint64
+	Prefixes        []string // Only used for microsoft peering
+	PrimarySubnet   *string
+	SecondarySubnet *string
+	SharedKey       *string
+	Type            *string
+	Vlan            *uint64
+}
+
+func (v *PartnerConfigAzure) connectType() string {
+	return "AZURE"
+}
+
+func (v *PartnerConfigAzure) toPayload() interface{} {
+	payload := &vxcCreatePayloadPartnerConfigAzure{
+		ConnectType: String(v.connectType()),
+		ServiceKey:  v.ServiceKey,
+	}
+	for _, p := range v.Peers {
+		pp := &vxcCreatePayloadPartnerConfigAzurePeer{
+			PrimarySubnet:   p.PrimarySubnet,
+			SecondarySubnet: p.SecondarySubnet,
+			SharedKey:       p.SharedKey,
+			Type:            p.Type,
+			Vlan:            p.Vlan,
+		}
+		if p.PeerASN != nil {
+			pp.PeerAsn = String(strconv.FormatUint(*p.PeerASN, 10))
+		}
+		if len(p.Prefixes) > 0 {
+			pp.Prefixes = String(strings.Join(p.Prefixes, ","))
+		}
+		payload.Peers = append(payload.Peers, pp)
+	}
+	return payload
+}
+
+type vxcCreatePayloadPartnerConfigAzure struct {
+	ConnectType *string                                   `json:"connectType,omitempty"`
+	ServiceKey  *string                                   `json:"serviceKey,omitempty"`
+	Peers       []*vxcCreatePayloadPartnerConfigAzurePeer `json:"peers,omitempty"`
+}
+
+type vxcCreatePayloadPartnerConfigAzurePeer struct {
+	PeerAsn         *string `json:"peer_asn,omitempty"`
+	Prefixes        *string `json:"prefixes,omitempty"`
+	PrimarySubnet   *string `json:"primary_subnet,omitempty"`
+	SecondarySubnet *string `json:"secondary_subnet,omitempty"`
+	SharedKey       *string `json:"shared_key,omitempty"`
+	Type            *string `json:"type,omitempty"`
+	Vlan            *uint64 `json:"vlan,omitempty"`
+}
+
+// PartnerConfigVRouter configures the MCR end of a VXC. It is set as the
+// partner configuration of the A-End, when the A-End is an MCR.
+type PartnerConfigVRouter struct {
+	Interfaces []*PartnerConfigVRouterInterface
+}
+
+type PartnerConfigVRouterInterface struct {
+	Bfd            *PartnerConfigVRouterBfd
+	BgpConnections []*PartnerConfigVRouterBgpConnection
+	IpAddresses    []string
+	NatIpAddresses []string
+}
+
+type PartnerConfigVRouterBfd struct {
+	Multiplier *uint64
+	RxInterval *uint64
+	TxInterval *uint64
+}
+
+// PartnerConfigVRouterBgpConnection configures a BGP session of the MCR. The
+// whitelists and blacklists refer to prefix filter lists of the MCR by id.
+type PartnerConfigVRouterBgpConnection struct {
+	AsPathPrependCount *uint64
+	BfdEnabled         *bool
+	Description        *string
+	ExportBlacklist    *uint64
+	ExportWhitelist    *uint64
+	ImportBlacklist    *uint64
+	ImportWhitelist    *uint64
+	LocalIpAddress     *string
+	MedIn              *uint64
+	MedOut             *uint64
+	Password           *string
+	PeerAsn            *uint64
+	PeerIpAddress      *string
+	Shutdown           *bool
+}
+
+func (v *PartnerConfigVRouter) connectType() string {
+	return "VROUTER"
+}
+
+func (v *PartnerConfigVRouter) toPayload() interface{} {
+	payload := &vxcCreatePayloadPartnerConfigVRouter{
+		ConnectType: String(v.connectType()),
+		Interfaces:  []*vxcCreatePayloadPartnerConfigVRouterInterface{},
+	}
+	for _, i := range v.Interfaces {
+		pi := &vxcCreatePayloadPartnerConfigVRouterInterface{
+			IpAddresses:    i.IpAddresses,
+			NatIpAddresses: i.NatIpAddresses,
+		}
+		if i.Bfd != nil {
+			pi.Bfd = &vxcCreatePayloadPartnerConfigVRouterBfd{
+				Multiplier: i.Bfd.Multiplier,
+				RxInterval: i.Bfd.RxInterval,
+				TxInterval: i.Bfd.TxInterval,
+			}
+		}
+		for _, c := range i.BgpConnections {
+			pi.BgpConnections = append(pi.BgpConnections, &vxcCreatePayloadPartnerConfigVRouterBgpConnection{
+				AsPathPrependCount: c.AsPathPrependCount,
+				BfdEnabled:         c.BfdEnabled,
+				Description:        c.Description,
+				ExportBlacklist:    c.ExportBlacklist,
+				ExportWhitelist:    c.ExportWhitelist,
+				ImportBlacklist:    c.ImportBlacklist,
+				ImportWhitelist:    c.ImportWhitelist,
+				LocalIpAddress:     c.LocalIpAddress,
+				MedIn:              c.MedIn,
+				MedOut:             c.MedOut,
+				Password:           c.Password,
+				PeerAsn:            c.PeerAsn,
+				PeerIpAddress:      c.PeerIpAddress,
+				Shutdown:           c.Shutdown,
+			})
+		}
+		payload.Interfaces = append(payload.Interfaces, pi)
+	}
+	return payload
+}
+
+// vxcCreatePayloadPartnerConfigVRouter always includes the interfaces, since
+// an empty list removes the configuration of the MCR end on update.
+type vxcCreatePayloadPartnerConfigVRouter struct {
+	ConnectType *string                                          `json:"connectType,omitempty"`
+	Interfaces  []*vxcCreatePayloadPartnerConfigVRouterInterface `json:"interfaces"`
+}
+
+type vxcCreatePayloadPartnerConfigVRouterInterface struct {
+	Bfd            *vxcCreatePayloadPartnerConfigVRouterBfd             `json:"bfd,omitempty"`
+	BgpConnections []*vxcCreatePayloadPartnerConfigVRouterBgpConnection `json:"bgpConnections,omitempty"`
+	IpAddresses    []string                                             `json:"ipAddresses,omitempty"`
+	NatIpAddresses []string                                             `json:"natIpAddresses,omitempty"`
+}
+
+type vxcCreatePayloadPartnerConfigVRouterBfd struct {
+	Multiplier *uint64 `json:"multiplier,omitempty"`
+	RxInterval *uint64 `json:"rxInterval,omitempty"`
+	TxInterval *uint64 `json:"txInterval,omitempty"`
+}
+
+type vxcCreatePayloadPartnerConfigVRouterBgpConnection struct {
+	AsPathPrependCount *uint64 `json:"asPathPrependCount,omitempty"`
+	BfdEnabled         *bool   `json:"bfdEnabled,omitempty"`
+	Description        *string `json:"description,omitempty"`
+	ExportBlacklist    *uint64 `json:"exportBlacklist,omitempty"`
+	ExportWhitelist    *uint64 `json:"exportWhitelist,omitempty"`
+	ImportBlacklist    *uint64 `json:"importBlacklist,omitempty"`
+	ImportWhitelist    *uint64 `json:"importWhitelist,omitempty"`
+	LocalIpAddress     *string `json:"localIpAddress,omitempty"`
+	MedIn              *uint64 `json:"medIn,omitempty"`
+	MedOut             *uint64 `json:"medOut,omitempty"`
+	Password           *string `json:"password,omitempty"`
+	PeerAsn            *uint64 `json:"peerAsn,omitempty"`
+	PeerIpAddress      *string `json:"peerIpAddress,omitempty"`
+	Shutdown           *bool   `json:"shutdown,omitempty"`
+}
+
 type CloudVxcCreateInput struct {
 	InvoiceReference *string
 	Name             *string
 	PartnerConfig    PartnerConfig
+	PartnerConfigA   PartnerConfig
 	ProductUidA      *string
 	ProductUidB      *string
 	RateLimit        *uint64
 	VlanA            *uint64
+	VnicIndexA       *uint64
 }
 
 func (v *CloudVxcCreateInput) toPayload() ([]byte, error) {
@@ -280,9 +531,7 @@ func (v *CloudVxcCreateInput) toPayload() ([]byte, error) {
 		ProductName:   v.Name,
 		RateLimit:     v.RateLimit,
 	}
-	if v.VlanA != nil {
-		av.AEnd = &vxcCreatePayloadVxcEnd{Vlan: v.VlanA}
-	}
+	av.AEnd = newVxcCreatePayloadAEnd(v.VlanA, v.VnicIndexA, v.PartnerConfigA)
 	bEnd := &vxcCreatePayloadVxcEnd{ProductUid: v.ProductUidB}
 	if *bEnd != (vxcCreatePayloadVxcEnd{}) {
 		av.BEnd = bEnd
@@ -302,6 +551,7 @@ type CloudVxcUpdateInput struct {
 	Name             *string
 	ProductUid       *string
 	PartnerConfig    PartnerConfig
+	PartnerConfigA   PartnerConfig
 	RateLimit        *uint64
 	VlanA            *uint64
 }
@@ -318,11 +568,14 @@ func (v *CloudVxcUpdateInput) toPayload() ([]byte, error) {
 		BEndConfig: v.PartnerConfig.toPayload(),
 		RateLimit:  v.RateLimit,
 	}
+	if v.PartnerConfigA != nil {
+		payload.AEndConfig = v.PartnerConfigA.toPayload()
+	}
 	return json.Marshal(payload)
 }
 
-func (c *Client) CreateCloudVxc(v *CloudVxcCreateInput) (*string, error) {
-	d, err := c.create(v)
+func (c *Client) CreateCloudVxc(ctx context.Context, v *CloudVxcCreateInput) (*string, error) {
+	d, err := c.create(ctx, v)
 	if err != nil {
 		return nil, err
 	}
@@ -330,6 +583,11 @@ func (c *Client) CreateCloudVxc(v *CloudVxcCreateInput) (*string, error) {
 	return &uid, nil
 }
 
-func (c *Client) UpdateCloudVxc(v *CloudVxcUpdateInput) error {
-	return c.update(*v.ProductUid, v)
+// ValidateCloudVxc checks the order of a cloud VXC without buying it.
+func (c *Client) ValidateCloudVxc(ctx context.Context, v *CloudVxcCreateInput) error {
+	return c.validate(ctx, v)
+}
+
+func (c *Client) UpdateCloudVxc(ctx context.Context, v *CloudVxcUpdateInput) error {
+	return c.update(ctx, *v.ProductUid, v)
 }
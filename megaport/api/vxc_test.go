@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"strconv"
 	"testing"
 
@@ -54,11 +55,27 @@ func TestPrivateVxcCreateInput_toPayload(t *testing.T) {
 			PrivateVxcCreateInput{},
 			[]byte(`[{}]`),
 		},
+		{ // 3
+			PrivateVxcCreateInput{
+				PartnerConfigA: &PartnerConfigVRouter{Interfaces: []*PartnerConfigVRouterInterface{{IpAddresses: []string{"10.0.0.1/30"}}}},
+				ProductUidA:    &uuidA,
+				ProductUidB:    &uuidB,
+			},
+			[]byte(`[{"productUid":"` + uuidA + `","associatedVxcs":[{"aEnd":{"partnerConfig":{"connectType":"VROUTER","interfaces":[{"ipAddresses":["10.0.0.1/30"]}]}},"bEnd":{"productUid":"` + uuidB + `"}}]}]`),
+		},
+		{ // 4
+			PrivateVxcCreateInput{
+				ProductUidA: &uuidA,
+				ProductUidB: &uuidB,
+				VnicIndexA:  Uint64(uint64(1)),
+			},
+			[]byte(`[{"productUid":"` + uuidA + `","associatedVxcs":[{"aEnd":{"vNicIndex":1},"bEnd":{"productUid":"` + uuidB + `"}}]}]`),
+		},
 	}
 	for i, tc := range testCases {
 		p, err := tc.i.toPayload()
 		if err != nil {
-			t.Errorf("PrivateVxcCreateInput.toPayload (#%d): %w", i, err)
+			t.Errorf("PrivateVxcCreateInput.toPayload (#%d): %v", i, err)
 		}
 		if !bytes.Equal(tc.o, p) {
 			t.Errorf("PrivateVxcCreateInput.toPayload (#%d):\n\tgot      `%s`\n\texpected `%s`", i, p, tc.o)
@@ -112,10 +129,134 @@ func TestPrivateVxcUpdateInput_toPayload(t *testing.T) {
 	for i, tc := range testCases {
 		p, err := tc.i.toPayload()
 		if err != nil {
-			t.Errorf("PrivateVxcUpdateInput.toPayload (#%d): %w", i, err)
+			t.Errorf("PrivateVxcUpdateInput.toPayload (#%d): %v", i, err)
 		}
 		if !bytes.Equal(tc.o, p) {
 			t.Errorf("PrivateVxcUpdateInput.toPayload (#%d):\n\tgot      `%s`\n\texpected `%s`", i, p, tc.o)
 		}
 	}
 }
+
+func TestPartnerConfigAzure_toPayload(t *testing.T) {
+	key := uuid.New().String()
+	testCases := []struct {
+		i PartnerConfigAzure
+		o []byte
+	}{
+		{ // 0
+			PartnerConfigAzure{
+				ServiceKey: &key,
+				Peers: []*PartnerConfigAzurePeer{
+					{
+						PeerASN:         Uint64(uint64(64512)),
+						PrimarySubnet:   String("10.0.0.0/30"),
+						SecondarySubnet: String("10.0.0.4/30"),
+						SharedKey:       String("foo"),
+						Type:            String("private"),
+						Vlan:            Uint64(uint64(100)),
+					},
+					{
+						PeerASN:         Uint64(uint64(64512)),
+						Prefixes:        []string{"1.1.1.0/24", "1.1.2.0/24"},
+						PrimarySubnet:   String("1.1.1.0/30"),
+						SecondarySubnet: String("1.1.1.4/30"),
+						Type:            String("microsoft"),
+						Vlan:            Uint64(uint64(200)),
+					},
+				},
+			},
+			[]byte(`{"connectType":"AZURE","serviceKey":"` + key + `","peers":[{"peer_asn":"64512","primary_subnet":"10.0.0.0/30","secondary_subnet":"10.0.0.4/30","shared_key":"foo","type":"private","vlan":100},{"peer_asn":"64512","prefixes":"1.1.1.0/24,1.1.2.0/24","primary_subnet":"1.1.1.0/30","secondary_subnet":"1.1.1.4/30","type":"microsoft","vlan":200}]}`),
+		},
+		{ // 1
+			PartnerConfigAzure{ServiceKey: &key},
+			[]byte(`{"connectType":"AZURE","serviceKey":"` + key + `"}`),
+		},
+	}
+	for i, tc := range testCases {
+		p, err := json.Marshal(tc.i.toPayload())
+		if err != nil {
+			t.Errorf("PartnerConfigAzure.toPayload (#%d): %v", i, err)
+		}
+		if !bytes.Equal(tc.o, p) {
+			t.Errorf("PartnerConfigAzure.toPayload (#%d):\n\tgot      `%s`\n\texpected `%s`", i, p, tc.o)
+		}
+	}
+}
+
+func TestPartnerConfigOracle_toPayload(t *testing.T) {
+	testCases := []struct {
+		i PartnerConfigOracle
+		o []byte
+	}{
+		{ // 0
+			PartnerConfigOracle{VirtualCircuitId: String("ocid1.virtualcircuit.oc1.uk-london-1.foo")},
+			[]byte(`{"connectType":"ORACLE","virtualCircuitId":"ocid1.virtualcircuit.oc1.uk-london-1.foo"}`),
+		},
+		{ // 1
+			PartnerConfigOracle{},
+			[]byte(`{"connectType":"ORACLE"}`),
+		},
+	}
+	for i, tc := range testCases {
+		p, err := json.Marshal(tc.i.toPayload())
+		if err != nil {
+			t.Errorf("PartnerConfigOracle.toPayload (#%d): %v", i, err)
+		}
+		if !bytes.Equal(tc.o, p) {
+			t.Errorf("PartnerConfigOracle.toPayload (#%d):\n\tgot      `%s`\n\texpected `%s`", i, p, tc.o)
+		}
+	}
+}
+
+func TestPartnerConfigVRouter_toPayload(t *testing.T) {
+	testCases := []struct {
+		i PartnerConfigVRouter
+		o []byte
+	}{
+		{ // 0
+			PartnerConfigVRouter{
+				Interfaces: []*PartnerConfigVRouterInterface{
+					{
+						Bfd: &PartnerConfigVRouterBfd{
+							Multiplier: Uint64(uint64(3)),
+							RxInterval: Uint64(uint64(300)),
+							TxInterval: Uint64(uint64(400)),
+						},
+						BgpConnections: []*PartnerConfigVRouterBgpConnection{
+							{
+								AsPathPrependCount: Uint64(uint64(2)),
+								BfdEnabled:         Bool(true),
+								Description:        String("foo"),
+								ExportWhitelist:    Uint64(uint64(10)),
+								ImportBlacklist:    Uint64(uint64(11)),
+								LocalIpAddress:     String("10.0.0.1"),
+								MedIn:              Uint64(uint64(100)),
+								MedOut:             Uint64(uint64(200)),
+								Password:           String("bar"),
+								PeerAsn:            Uint64(uint64(64512)),
+								PeerIpAddress:      String("10.0.0.2"),
+								Shutdown:           Bool(false),
+							},
+						},
+						IpAddresses:    []string{"10.0.0.1/30"},
+						NatIpAddresses: []string{"10.0.0.1"},
+					},
+				},
+			},
+			[]byte(`{"connectType":"VROUTER","interfaces":[{"bfd":{"multiplier":3,"rxInterval":300,"txInterval":400},"bgpConnections":[{"asPathPrependCount":2,"bfdEnabled":true,"description":"foo","exportWhitelist":10,"importBlacklist":11,"localIpAddress":"10.0.0.1","medIn":100,"medOut":200,"password":"bar","peerAsn":64512,"peerIpAddress":"10.0.0.2","shutdown":false}],"ipAddresses":["10.0.0.1/30"],"natIpAddresses":["10.0.0.1"]}]}`),
+		},
+		{ // 1
+			PartnerConfigVRouter{},
+			[]byte(`{"connectType":"VROUTER","interfaces":[]}`),
+		},
+	}
+	for i, tc := range testCases {
+		p, err := json.Marshal(tc.i.toPayload())
+		if err != nil {
+			t.Errorf("PartnerConfigVRouter.toPayload (#%d): %v", i, err)
+		}
+		if !bytes.Equal(tc.o, p) {
+			t.Errorf("PartnerConfigVRouter.toPayload (#%d):\n\tgot      `%s`\n\texpected `%s`", i, p, tc.o)
+		}
+	}
+}
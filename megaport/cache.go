@@ -0,0 +1,89 @@
+package megaport
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/mutexkv"
+)
+
+const (
+	cacheKeyLocations    = "locations"
+	cacheKeyPartnerPorts = "partner_ports"
+	cacheKeyProducts     = "products"
+)
+
+// dataSourceCache holds the lists that data sources look items up in, so that
+// they are fetched once per ttl instead of once per data source. Lists are
+// fetched again once they expire or are invalidated, e.g. after a resource
+// creates, updates or deletes a product.
+type dataSourceCache struct {
+	ttl     time.Duration
+	mutexKV *mutexkv.MutexKV
+
+	mu      sync.Mutex
+	entries map[string]*dataSourceCacheEntry
+	// generation is incremented by every invalidation, so that lists fetched
+	// while a product was being created or deleted are not cached
+	generation uint64
+}
+
+type dataSourceCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// newDataSourceCache returns an empty cache whose lists expire after ttl. A
+// ttl of 0 disables caching.
+func newDataSourceCache(ttl time.Duration) *dataSourceCache {
+	return &dataSourceCache{
+		ttl:     ttl,
+		mutexKV: mutexkv.NewMutexKV(),
+		entries: map[string]*dataSourceCacheEntry{},
+	}
+}
+
+// get returns the cached value of key, calling fetch to update it if it is
+// missing or has expired. Concurrent calls for the same key fetch it once.
+func (c *dataSourceCache) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return fetch()
+	}
+	c.mutexKV.Lock(key)
+	defer c.mutexKV.Unlock(key)
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	ttl, generation := c.ttl, c.generation
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.value, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if ttl > 0 && generation == c.generation {
+		c.entries[key] = &dataSourceCacheEntry{value: v, expires: time.Now().Add(ttl)}
+	}
+	c.mu.Unlock()
+	return v, nil
+}
+
+// invalidate drops the cached values of keys, so that they are fetched again
+// the next time they are looked up.
+func (c *dataSourceCache) invalidate(keys ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, k := range keys {
+		if _, ok := c.entries[k]; ok {
+			log.Printf("[INFO] Invalidating cached %s", k)
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package megaport
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDataSourceCache(t *testing.T) {
+	c := newDataSourceCache(time.Minute)
+	n := 0
+	fetch := func() (interface{}, error) {
+		n++
+		return n, nil
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := c.get(cacheKeyProducts, fetch); err != nil || v.(int) != 1 {
+			t.Errorf("TestDataSourceCache: expected the cached value 1, got %v (%v)", v, err)
+		}
+	}
+	c.invalidate(cacheKeyProducts)
+	if v, err := c.get(cacheKeyProducts, fetch); err != nil || v.(int) != 2 {
+		t.Errorf("TestDataSourceCache: expected the value to be fetched again after invalidation, got %v (%v)", v, err)
+	}
+	if v, err := c.get(cacheKeyLocations, fetch); err != nil || v.(int) != 3 {
+		t.Errorf("TestDataSourceCache: expected a different key to be fetched, got %v (%v)", v, err)
+	}
+	c.entries[cacheKeyProducts].expires = time.Now().Add(-time.Second)
+	if v, err := c.get(cacheKeyProducts, fetch); err != nil || v.(int) != 4 {
+		t.Errorf("TestDataSourceCache: expected the value to be fetched again after it expired, got %v (%v)", v, err)
+	}
+	if _, err := c.get(cacheKeyPartnerPorts, func() (interface{}, error) { return nil, errors.New("foo") }); err == nil {
+		t.Errorf("TestDataSourceCache: expected the error of fetch to be returned")
+	}
+	if _, ok := c.entries[cacheKeyPartnerPorts]; ok {
+		t.Errorf("TestDataSourceCache: expected failed fetches not to be cached")
+	}
+}
+
+func TestDataSourceCache_invalidatedDuringFetch(t *testing.T) {
+	c := newDataSourceCache(time.Minute)
+	if _, err := c.get(cacheKeyProducts, func() (interface{}, error) {
+		c.invalidate(cacheKeyProducts)
+		return 1, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.entries[cacheKeyProducts]; ok {
+		t.Errorf("TestDataSourceCache_invalidatedDuringFetch: expected a value fetched during an invalidation not to be cached")
+	}
+}
+
+func TestDataSourceCache_disabled(t *testing.T) {
+	for _, c := range []*dataSourceCache{nil, newDataSourceCache(0)} {
+		n := 0
+		fetch := func() (interface{}, error) {
+			n++
+			return n, nil
+		}
+		c.get(cacheKeyProducts, fetch) // nolint: errcheck
+		if v, err := c.get(cacheKeyProducts, fetch); err != nil || v.(int) != 2 {
+			t.Errorf("TestDataSourceCache_disabled: expected the value to be fetched every time, got %v (%v)", v, err)
+		}
+		c.invalidate(cacheKeyProducts)
+	}
+}
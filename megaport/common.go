@@ -2,13 +2,18 @@ package megaport
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
@@ -31,6 +36,308 @@ func resourceAttributePrivatePublic() *schema.Schema {
 	}
 }
 
+const (
+	cancellationModeNow       = "now"
+	cancellationModeEndOfTerm = "end_of_term"
+)
+
+// resourceAttributeCancellationMode selects whether destroying a product
+// cancels it straight away or at the end of its contract term.
+func resourceAttributeCancellationMode() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      cancellationModeNow,
+		ValidateFunc: validation.StringInSlice([]string{cancellationModeNow, cancellationModeEndOfTerm}, false),
+	}
+}
+
+func resourceAttributeCancellationPending() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Computed: true,
+	}
+}
+
+// customizeDiffRestoreProduct plans to restore a product that is pending
+// cancellation at the end of its term when restore_product_uid is set to its
+// id, e.g. after importing it. Products are not restored otherwise, so that
+// those cancelled on purpose, e.g. in the Megaport portal, stay cancelled.
+func customizeDiffRestoreProduct(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && d.Get("cancellation_pending").(bool) && d.Get("restore_product_uid") == d.Id() {
+		return d.SetNew("cancellation_pending", false)
+	}
+	return nil
+}
+
+// resourceMegaportProductImport imports ports and MCRs with the default
+// cancellation mode, which is not returned by the API.
+func resourceMegaportProductImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set("cancellation_mode", cancellationModeNow); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceAttributeRestoreProductUid is the product id of a product that is
+// pending cancellation at the end of its term, which is restored when the
+// resource is created instead of ordering a new one. Once the resource exists,
+// it only matters whether it is its id, see customizeDiffRestoreProduct.
+func resourceAttributeRestoreProductUid() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+			return d.Id() != "" && old != d.Id() && new != d.Id()
+		},
+	}
+}
+
+// getProductPendingCancellation retrieves a product with get, which is the
+// getter of the client for its type, and checks that it can be restored.
+func getProductPendingCancellation(ctx context.Context, get func(context.Context, string) (*api.Product, error), productUid string) (*api.Product, error) {
+	p, err := get(ctx, productUid)
+	if err != nil {
+		return nil, err
+	}
+	if !p.CancellationPending() {
+		return nil, fmt.Errorf("product %s is not pending cancellation", productUid)
+	}
+	return p, nil
+}
+
+// checkProductHasNoServices returns an error if any VXC or IX of the product
+// has not been deleted. Products are only cancelled at the end of their term
+// once their services are gone, as these would otherwise keep running and
+// being charged for until then.
+func checkProductHasNoServices(p *api.Product) error {
+	for _, v := range p.AssociatedVxcs {
+		if !isResourceDeleted(v.ProvisioningStatus) {
+			return fmt.Errorf("product %s cannot be cancelled at the end of its term while VXC %s (%s) is attached to it", p.ProductUid, v.ProductName, v.ProductUid)
+		}
+	}
+	for _, v := range p.AssociatedIxs {
+		if !isResourceDeleted(v.ProvisioningStatus) {
+			return fmt.Errorf("product %s cannot be cancelled at the end of its term while IX %s (%s) is attached to it", p.ProductUid, v.ProductName, v.ProductUid)
+		}
+	}
+	return nil
+}
+
+// resourceGetter is implemented by both schema.ResourceData and
+// schema.ResourceDiff, so that orders can be built during plan and apply.
+type resourceGetter interface {
+	Get(string) interface{}
+	GetOk(string) (interface{}, bool)
+}
+
+// vxcOrderFields maps the fields of VXC orders to the attributes of the VXC
+// resources, for customizeDiffValidateOrder.
+var vxcOrderFields = map[string]string{
+	"aEnd.productUid": "a_end.0.product_uid",
+	"aEnd.vNicIndex":  "a_end.0.vnic_index",
+	"aEnd.vlan":       "a_end.0.vlan",
+	"bEnd.productUid": "b_end.0.product_uid",
+	"bEnd.vlan":       "b_end.0.vlan",
+	"productName":     "name",
+	"rateLimit":       "rate_limit",
+}
+
+// vxcOrderFieldsWith returns vxcOrderFields along with the fields of the
+// partner configuration of a cloud VXC in partner.
+func vxcOrderFieldsWith(partner map[string]string) map[string]string {
+	m := map[string]string{}
+	for k, v := range vxcOrderFields {
+		m[k] = v
+	}
+	for k, v := range partner {
+		m[k] = v
+	}
+	return m
+}
+
+// heldOrderValues returns the values of the fields of VXC orders that are held
+// by a VXC until it is deleted, such as its VLANs and partner keys.
+var heldOrderValues = map[string]func(*api.ProductAssociatedVxc) interface{}{
+	"aEnd.vlan": func(v *api.ProductAssociatedVxc) interface{} { return v.AEnd.Vlan },
+	"bEnd.vlan": func(v *api.ProductAssociatedVxc) interface{} { return v.BEnd.Vlan },
+	"partnerConfigs.pairingKey": func(v *api.ProductAssociatedVxc) interface{} {
+		if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeGoogle).(*api.ProductAssociatedVxcResourcesCspConnectionGcp); ok {
+			return cc.PairingKey
+		}
+		return nil
+	},
+	"partnerConfigs.serviceKey": func(v *api.ProductAssociatedVxc) interface{} {
+		if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAzure).(*api.ProductAssociatedVxcResourcesCspConnectionAzure); ok {
+			return cc.ServiceKey
+		}
+		return nil
+	},
+	"partnerConfigs.virtualCircuitId": func(v *api.ProductAssociatedVxc) interface{} {
+		if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeOracle).(*api.ProductAssociatedVxcResourcesCspConnectionOracle); ok {
+			return cc.VirtualCircuitId
+		}
+		return nil
+	},
+}
+
+// customizeDiffValidateOrder validates the order of a new product with
+// Megaport during plan, so that an invalid order fails before anything else is
+// bought. Orders are only validated once all of their configured values are
+// known. Errors about the fields in fields are reported against their
+// attributes, and any other error is reported against the resource.
+//
+// Terraform plans the replacement of a product a second time as a new
+// product, which is when replacements are validated. The product being
+// replaced still exists then, so an error about a value that it holds, such as
+// a VLAN, is expected: see orderValueHeldByVxc.
+//
+// CustomizeDiff can only return a single error, which is only reported against
+// an attribute if it is a cty.PathError that has not been wrapped, so it must
+// not be combined with other functions by customdiff.All. Several errors are
+// returned together and listed along with their attributes.
+func customizeDiffValidateOrder(r func() *schema.Resource, fields map[string]string, validate func(context.Context, *api.Client, *schema.ResourceDiff) error) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		cfg := m.(*Config)
+		if d.Id() != "" || !resourceDiffKnown(d, "", r().Schema) {
+			return nil
+		}
+		err := validate(ctx, cfg.Client, d)
+		if err == nil {
+			return nil
+		}
+		e := &api.Error{}
+		if !api.IsValidation(err) || !errors.As(err, &e) {
+			// The order is validated again before it is bought
+			log.Printf("[WARN] Could not validate order during plan: %v", err)
+			return nil
+		}
+		if len(e.FieldErrors) == 0 {
+			return fmt.Errorf("invalid order: %w", err)
+		}
+		errs := &multierror.Error{ErrorFormat: formatOrderErrors}
+		for _, fe := range e.FieldErrors {
+			a, ok := fields[fe.Field]
+			if !ok {
+				errs = multierror.Append(errs, &orderError{FieldError: fe})
+				continue
+			}
+			held, err := orderValueHeldByVxc(ctx, cfg.Client, d, fe.Field, a)
+			if err != nil {
+				log.Printf("[WARN] Could not look up the VXC holding %s: %v", a, err)
+			}
+			if held {
+				log.Printf("[WARN] Ignoring error about %s, which is held by the VXC being replaced: %s", a, fe.Message)
+				continue
+			}
+			errs = multierror.Append(errs, &orderError{FieldError: fe, Attribute: a})
+		}
+		switch len(errs.Errors) {
+		case 0:
+			return nil
+		case 1:
+			return errs.Errors[0].(*orderError).pathError()
+		}
+		return errs
+	}
+}
+
+// orderValueHeldByVxc reports whether the value of attribute, ordered for the
+// field of a VXC order, is held by a VXC of the same name on the same A-End
+// product, which is what the VXC being replaced looks like when Terraform plans
+// its replacement. Errors about such values do not fail the plan, as the VXC is
+// deleted before its replacement is ordered, which validates them again.
+func orderValueHeldByVxc(ctx context.Context, client *api.Client, d *schema.ResourceDiff, field, attribute string) (bool, error) {
+	get, ok := heldOrderValues[field]
+	if !ok {
+		return false, nil
+	}
+	p, err := client.GetPort(ctx, d.Get("a_end.0.product_uid").(string))
+	if err != nil {
+		return false, err
+	}
+	for _, v := range p.AssociatedVxcs {
+		if v.ProductName != d.Get("name").(string) || isResourceDeleted(v.ProvisioningStatus) {
+			continue
+		}
+		vxc, err := client.GetVxc(ctx, v.ProductUid)
+		if err != nil {
+			return false, err
+		}
+		if fmt.Sprint(get(vxc)) == fmt.Sprint(d.Get(attribute)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// orderError is an error about a field of an order, along with the attribute
+// of the resource that the field maps to, if any.
+type orderError struct {
+	api.FieldError
+	Attribute string
+}
+
+func (e *orderError) Error() string {
+	switch {
+	case e.Attribute != "":
+		return fmt.Sprintf("%s: %s", e.Attribute, e.Message)
+	case e.Field != "":
+		return fmt.Sprintf("invalid order: %s: %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("invalid order: %s", e.Message)
+}
+
+// pathError returns the error as a cty.PathError, which is reported against
+// its attribute, or as is if it is not about an attribute.
+func (e *orderError) pathError() error {
+	if e.Attribute == "" {
+		return e
+	}
+	p := cty.Path{}
+	for _, k := range strings.Split(e.Attribute, ".") {
+		if i, err := strconv.Atoi(k); err == nil {
+			p = p.IndexInt(i)
+		} else {
+			p = p.GetAttr(k)
+		}
+	}
+	return p.NewErrorf("%s", e.Message)
+}
+
+func formatOrderErrors(errs []error) string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "* " + err.Error()
+	}
+	return fmt.Sprintf("%d errors in order:\n\n%s", len(errs), strings.Join(msgs, "\n"))
+}
+
+// resourceDiffKnown reports whether the values of all the attributes in m,
+// including those of nested blocks, are known. Computed attributes are
+// skipped, as their values are left for Megaport to fill in when they are not
+// configured.
+func resourceDiffKnown(d *schema.ResourceDiff, prefix string, m map[string]*schema.Schema) bool {
+	for k, s := range m {
+		if s.Computed {
+			continue
+		}
+		if !d.NewValueKnown(prefix + k) {
+			return false
+		}
+		e, ok := s.Elem.(*schema.Resource)
+		if !ok || s.Type != schema.TypeList {
+			continue
+		}
+		for i := range d.Get(prefix + k).([]interface{}) {
+			if !resourceDiffKnown(d, fmt.Sprintf("%s%s.%d.", prefix, k, i), e.Schema) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func resourceMegaportVxcEndElem() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
@@ -48,6 +355,153 @@ func resourceMegaportVxcEndElem() *schema.Resource {
 	}
 }
 
+// resourceMegaportVxcAEndElem is the A-End of a VXC, which additionally
+// configures the interface and BGP sessions of the MCR when it is ordered from
+// one, or selects the vNIC of the MVE it is ordered from.
+func resourceMegaportVxcAEndElem() *schema.Resource {
+	r := resourceMegaportVxcEndElem()
+	r.Schema["vnic_index"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ForceNew:     true,
+		ValidateFunc: validation.IntBetween(0, 4),
+	}
+	r.Schema["mcr_config"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem:     resourceMegaportVxcMcrConfigElem(),
+	}
+	return r
+}
+
+func resourceMegaportVxcMcrConfigElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"ip_addresses": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsCIDR,
+				},
+			},
+			"nat_ip_addresses": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsIPAddress,
+				},
+			},
+			"bfd": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tx_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      300,
+							ValidateFunc: validation.IntBetween(300, 9000),
+						},
+						"rx_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      300,
+							ValidateFunc: validation.IntBetween(300, 9000),
+						},
+						"multiplier": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							ValidateFunc: validation.IntBetween(3, 20),
+						},
+					},
+				},
+			},
+			"bgp_connection": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     resourceMegaportVxcMcrBgpConnectionElem(),
+			},
+		},
+	}
+}
+
+func resourceMegaportVxcMcrBgpConnectionElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"peer_asn": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"local_ip_address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			"peer_ip_address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"shutdown": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"bfd_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"med_in": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"med_out": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"as_path_prepend_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 10),
+			},
+			"import_whitelist": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"import_blacklist": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"export_whitelist": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"export_blacklist": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+		},
+	}
+}
+
 func validateAwsBGPAuthKey(v interface{}, k string) (warns []string, errs []error) {
 	vv, ok := v.(string)
 	if !ok {
@@ -72,6 +526,140 @@ func flattenVxcEnd(v api.ProductAssociatedVxcEnd) []interface{} {
 	}}
 }
 
+// flattenVxcAEnd flattens the A-End of a VXC, along with the configuration of
+// the MCR when the A-End is one. The BGP passwords are never reported by the
+// API, so they are kept from the configured A-End.
+func flattenVxcAEnd(config []interface{}, v *api.ProductAssociatedVxc) []interface{} {
+	a := flattenVxcEnd(v.AEnd)[0].(map[string]interface{})
+	a["vnic_index"] = int(v.AEnd.VnicIndex)
+	a["mcr_config"] = []interface{}{}
+	cc := v.Resources.GetVRouterCspConnection("a_csp_connection")
+	if cc == nil || len(cc.Interfaces) == 0 || len(cc.Interfaces[0].IpAddresses) == 0 {
+		return []interface{}{a}
+	}
+	passwords := map[string]interface{}{}
+	if len(config) > 0 && config[0] != nil {
+		if mc := config[0].(map[string]interface{})["mcr_config"].([]interface{}); len(mc) > 0 && mc[0] != nil {
+			for _, c := range mc[0].(map[string]interface{})["bgp_connection"].([]interface{}) {
+				c := c.(map[string]interface{})
+				passwords[c["peer_ip_address"].(string)] = c["password"]
+			}
+		}
+	}
+	i := cc.Interfaces[0]
+	mc := map[string]interface{}{
+		"ip_addresses":     i.IpAddresses,
+		"nat_ip_addresses": i.NatIpAddresses,
+		"bfd":              []interface{}{},
+		"bgp_connection":   []interface{}{},
+	}
+	if i.Bfd != nil {
+		mc["bfd"] = []interface{}{map[string]interface{}{
+			"tx_interval": int(i.Bfd.TxInterval),
+			"rx_interval": int(i.Bfd.RxInterval),
+			"multiplier":  int(i.Bfd.Multiplier),
+		}}
+	}
+	bgp := make([]interface{}, len(i.BgpConnections))
+	for j, c := range i.BgpConnections {
+		password := interface{}(c.Password)
+		if v, ok := passwords[c.PeerIpAddress]; ok && c.Password == "" {
+			password = v
+		}
+		bgp[j] = map[string]interface{}{
+			"peer_asn":              int(c.PeerAsn),
+			"local_ip_address":      c.LocalIpAddress,
+			"peer_ip_address":       c.PeerIpAddress,
+			"password":              password,
+			"description":           c.Description,
+			"shutdown":              c.Shutdown,
+			"bfd_enabled":           c.BfdEnabled,
+			"med_in":                int(c.MedIn),
+			"med_out":               int(c.MedOut),
+			"as_path_prepend_count": int(c.AsPathPrependCount),
+			"import_whitelist":      int(c.ImportWhitelist),
+			"import_blacklist":      int(c.ImportBlacklist),
+			"export_whitelist":      int(c.ExportWhitelist),
+			"export_blacklist":      int(c.ExportBlacklist),
+		}
+	}
+	mc["bgp_connection"] = bgp
+	a["mcr_config"] = []interface{}{mc}
+	return []interface{}{a}
+}
+
+// expandVxcAEndMcrConfig returns the partner configuration of the A-End of a
+// VXC, or nil when the A-End has no mcr_config. When clear is set, an empty
+// configuration is returned instead of nil, so that removing mcr_config also
+// removes the configuration from the MCR.
+func expandVxcAEndMcrConfig(a map[string]interface{}, clear bool) api.PartnerConfig {
+	mc := a["mcr_config"].([]interface{})
+	if len(mc) == 0 || mc[0] == nil {
+		if clear {
+			return &api.PartnerConfigVRouter{}
+		}
+		return nil
+	}
+	m := mc[0].(map[string]interface{})
+	i := &api.PartnerConfigVRouterInterface{
+		IpAddresses:    expandStrings(m["ip_addresses"].([]interface{})),
+		NatIpAddresses: expandStrings(m["nat_ip_addresses"].([]interface{})),
+	}
+	if bfd := m["bfd"].([]interface{}); len(bfd) > 0 && bfd[0] != nil {
+		b := bfd[0].(map[string]interface{})
+		i.Bfd = &api.PartnerConfigVRouterBfd{
+			Multiplier: api.Uint64FromInt(b["multiplier"]),
+			RxInterval: api.Uint64FromInt(b["rx_interval"]),
+			TxInterval: api.Uint64FromInt(b["tx_interval"]),
+		}
+	}
+	for _, c := range m["bgp_connection"].([]interface{}) {
+		c := c.(map[string]interface{})
+		bc := &api.PartnerConfigVRouterBgpConnection{
+			BfdEnabled:     api.Bool(c["bfd_enabled"].(bool)),
+			LocalIpAddress: api.String(c["local_ip_address"]),
+			PeerAsn:        api.Uint64FromInt(c["peer_asn"]),
+			PeerIpAddress:  api.String(c["peer_ip_address"]),
+			Shutdown:       api.Bool(c["shutdown"].(bool)),
+		}
+		if v := c["password"].(string); v != "" {
+			bc.Password = api.String(v)
+		}
+		if v := c["description"].(string); v != "" {
+			bc.Description = api.String(v)
+		}
+		for k, f := range map[string]**uint64{
+			"med_in":                &bc.MedIn,
+			"med_out":               &bc.MedOut,
+			"as_path_prepend_count": &bc.AsPathPrependCount,
+			"import_whitelist":      &bc.ImportWhitelist,
+			"import_blacklist":      &bc.ImportBlacklist,
+			"export_whitelist":      &bc.ExportWhitelist,
+			"export_blacklist":      &bc.ExportBlacklist,
+		} {
+			if v := c[k].(int); v != 0 {
+				*f = api.Uint64FromInt(v)
+			}
+		}
+		i.BgpConnections = append(i.BgpConnections, bc)
+	}
+	return &api.PartnerConfigVRouter{Interfaces: []*api.PartnerConfigVRouterInterface{i}}
+}
+
+func expandStrings(l []interface{}) []string {
+	s := make([]string, len(l))
+	for i, v := range l {
+		s[i] = v.(string)
+	}
+	return s
+}
+
+// dataSourceListId returns the id of a data source that lists items, which
+// changes whenever the items that match change.
+func dataSourceListId(ids []string) string {
+	return strconv.Itoa(schema.HashString(strings.Join(ids, ",")))
+}
+
 func isResourceDeleted(provisioningStatus string) bool {
 	switch provisioningStatus {
 	case api.ProductStatusCancelled:
@@ -97,7 +685,7 @@ func waitUntilVxcIsConfigured(ctx context.Context, client *api.Client, productUi
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetVxc(productUid)
+			v, err := client.GetVxc(ctx, productUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve VXC while waiting for setup to finish: %v", err)
 				return nil, "", err
@@ -117,47 +705,98 @@ func waitUntilVxcIsConfigured(ctx context.Context, client *api.Client, productUi
 }
 
 func waitUntilVxcIsDeleted(ctx context.Context, client *api.Client, productUid string, timeout time.Duration) error {
-	initial, err := client.GetVxc(productUid)
+	initial, err := client.GetVxc(ctx, productUid)
 	if err != nil {
+		if api.IsNotFound(err) {
+			return nil
+		}
 		log.Printf("[ERROR] Could not retrieve VXC while waiting for deletion to finish: %v", err)
 		return err
 	}
+	return waitUntilDeleted(ctx, "VXC", productUid, timeout, func() (interface{}, string, error) {
+		if initial.AEnd.Vlan > 0 {
+			ok, err := client.GetPortVlanIdAvailable(ctx, initial.AEnd.ProductUid, initial.AEnd.Vlan)
+			if err != nil || !ok {
+				return initial, "", err
+			}
+		}
+		if initial.BEnd.Vlan > 0 && initial.Type() == api.VxcTypePrivate {
+			ok, err := client.GetPortVlanIdAvailable(ctx, initial.BEnd.ProductUid, initial.BEnd.Vlan)
+			if err != nil || !ok {
+				return initial, "", err
+			}
+		}
+		v, err := client.GetVxc(ctx, productUid)
+		if err != nil {
+			return nil, "", err
+		}
+		return v, v.ProvisioningStatus, nil
+	})
+}
+
+// waitUntilProductIsDeleted waits until a port, MCR or MVE has been cancelled
+// and all of its VXCs and IXs have been released, so that dependent resources
+// can be recreated straight away. The product is retrieved with get, which is
+// the getter of the client for its type.
+func waitUntilProductIsDeleted(ctx context.Context, get func(context.Context, string) (*api.Product, error), productUid string, timeout time.Duration) error {
+	return waitUntilDeleted(ctx, "product", productUid, timeout, func() (interface{}, string, error) {
+		v, err := get(ctx, productUid)
+		if err != nil {
+			return nil, "", err
+		}
+		if v.CancellationPending() {
+			return v, "", nil
+		}
+		for _, x := range v.AssociatedVxcs {
+			if !isResourceDeleted(x.ProvisioningStatus) {
+				return v, "", nil
+			}
+		}
+		for _, x := range v.AssociatedIxs {
+			if !isResourceDeleted(x.ProvisioningStatus) {
+				return v, "", nil
+			}
+		}
+		return v, v.ProvisioningStatus, nil
+	})
+}
+
+// deletionDelay and deletionMinTimeout are the delay before the first refresh
+// and the minimum time between refreshes of waitUntilDeleted.
+var (
+	deletionDelay      = 5 * time.Second
+	deletionMinTimeout = 10 * time.Second
+)
+
+// waitUntilDeleted waits until refresh returns a status for which
+// isResourceDeleted holds, or an error for a product that is not found. Any
+// other status, including the empty one, keeps it waiting.
+func waitUntilDeleted(ctx context.Context, kind, productUid string, timeout time.Duration, refresh resource.StateRefreshFunc) error {
 	scc := &resource.StateChangeConf{
-		Target: []string{api.ProductStatusDecommissioned},
+		Target: []string{
+			api.ProductStatusCancelled,
+			api.ProductStatusCancelledParent,
+			api.ProductStatusDecommissioned,
+		},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetVxc(productUid)
+			v, status, err := refresh()
 			if err != nil {
-				log.Printf("[ERROR] Could not retrieve VXC while waiting for deletion to finish: %v", err)
-				return nil, "", err
-			}
-			if v == nil {
-				return nil, "", nil
-			}
-			if initial.AEnd.Vlan > 0 {
-				ok, err := client.GetPortVlanIdAvailable(initial.AEnd.ProductUid, initial.AEnd.Vlan)
-				if err != nil {
-					return v, "", err
-				}
-				if !ok {
-					return v, "", nil
+				if api.IsNotFound(err) {
+					return productUid, api.ProductStatusDecommissioned, nil
 				}
+				log.Printf("[ERROR] Could not retrieve %s while waiting for deletion to finish: %v", kind, err)
+				return nil, "", err
 			}
-			if initial.BEnd.Vlan > 0 && initial.Type() == api.VxcTypePrivate {
-				ok, err := client.GetPortVlanIdAvailable(initial.BEnd.ProductUid, initial.BEnd.Vlan)
-				if err != nil {
-					return v, "", err
-				}
-				if !ok {
-					return v, "", nil
-				}
+			if !isResourceDeleted(status) {
+				return v, "", nil
 			}
-			return v, v.ProvisioningStatus, nil
+			return v, status, nil
 		},
 		Timeout:    timeout,
-		MinTimeout: 10 * time.Second,
-		Delay:      5 * time.Second,
+		MinTimeout: deletionMinTimeout,
+		Delay:      deletionDelay,
 	}
-	log.Printf("[INFO] Waiting for VXC (%s) to be deleted", productUid)
-	_, err = scc.WaitForStateContext(ctx)
+	log.Printf("[INFO] Waiting for %s (%s) to be deleted", kind, productUid)
+	_, err := scc.WaitForStateContext(ctx)
 	return err
 }
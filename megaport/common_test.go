@@ -1,13 +1,25 @@
 package megaport
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
@@ -37,6 +49,40 @@ func TestValidateAwsBGPAuthKey(t *testing.T) {
 	}
 }
 
+func TestFlattenVxcAEnd(t *testing.T) {
+	v := &api.ProductAssociatedVxc{}
+	if err := json.Unmarshal([]byte(`{"aEnd":{"productUid":"a","vlan":100},"resources":{"csp_connection":[{"connectType":"VROUTER","resource_name":"a_csp_connection","interfaces":[{"ipAddresses":["10.0.0.1/30"],"bgpConnections":[{"peerAsn":64512,"localIpAddress":"10.0.0.1","peerIpAddress":"10.0.0.2"}]}]}]}}`), v); err != nil {
+		t.Fatalf("TestFlattenVxcAEnd: %v", err)
+	}
+	config := []interface{}{map[string]interface{}{
+		"mcr_config": []interface{}{map[string]interface{}{
+			"bgp_connection": []interface{}{map[string]interface{}{
+				"peer_ip_address": "10.0.0.2",
+				"password":        "foo",
+			}},
+		}},
+	}}
+	a := flattenVxcAEnd(config, v)[0].(map[string]interface{})
+	if a["product_uid"] != "a" || a["vlan"] != 100 || a["vnic_index"] != 0 {
+		t.Errorf("TestFlattenVxcAEnd: unexpected A-End %#v", a)
+	}
+	mc := a["mcr_config"].([]interface{})
+	if len(mc) != 1 {
+		t.Fatalf("TestFlattenVxcAEnd: expected 1 mcr_config but got %d", len(mc))
+	}
+	bgp := mc[0].(map[string]interface{})["bgp_connection"].([]interface{})
+	if len(bgp) != 1 {
+		t.Fatalf("TestFlattenVxcAEnd: expected 1 bgp_connection but got %d", len(bgp))
+	}
+	if c := bgp[0].(map[string]interface{}); c["peer_asn"] != 64512 || c["password"] != "foo" {
+		t.Errorf("TestFlattenVxcAEnd: unexpected bgp_connection %#v", c)
+	}
+	a = flattenVxcAEnd(nil, &api.ProductAssociatedVxc{})[0].(map[string]interface{})
+	if mc := a["mcr_config"].([]interface{}); len(mc) != 0 {
+		t.Errorf("TestFlattenVxcAEnd: expected no mcr_config but got %#v", mc)
+	}
+}
+
 func testAccCheckResourceExists(n string, o interface{}) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		cfg := testAccProvider.Meta().(*Config)
@@ -46,17 +92,33 @@ func testAccCheckResourceExists(n string, o interface{}) resource.TestCheckFunc
 		}
 		switch t := o.(type) {
 		case *api.Product:
-			v, err := cfg.Client.GetPort(rs.Primary.ID)
+			v, err := cfg.Client.GetPort(context.Background(), rs.Primary.ID)
 			if err != nil {
 				return err
 			}
 			*(o.(*api.Product)) = *v
 		case *api.ProductAssociatedVxc:
-			v, err := cfg.Client.GetVxc(rs.Primary.ID)
+			v, err := cfg.Client.GetVxc(context.Background(), rs.Primary.ID)
 			if err != nil {
 				return err
 			}
 			*(o.(*api.ProductAssociatedVxc)) = *v
+		case *api.ProductAssociatedIx:
+			v, err := cfg.Client.GetIx(context.Background(), rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			*(o.(*api.ProductAssociatedIx)) = *v
+		case *api.McrPrefixFilterList:
+			id, err := strconv.ParseUint(rs.Primary.ID, 10, 64)
+			if err != nil {
+				return err
+			}
+			v, err := cfg.Client.GetMcrPrefixFilterList(context.Background(), rs.Primary.Attributes["mcr_id"], id)
+			if err != nil {
+				return err
+			}
+			*(o.(*api.McrPrefixFilterList)) = *v
 		default:
 			return fmt.Errorf("testAccCheckResourceExists: not implemented, cannot check %q of type %s", n, t)
 		}
@@ -72,7 +134,7 @@ func testAccCheckResourceDestroy(s *terraform.State) error {
 		}
 		switch rs.Type {
 		case "megaport_port":
-			v, err := cfg.Client.GetPort(rs.Primary.ID)
+			v, err := cfg.Client.GetPort(context.Background(), rs.Primary.ID)
 			if err != nil {
 				return err
 			}
@@ -80,7 +142,35 @@ func testAccCheckResourceDestroy(s *terraform.State) error {
 				return fmt.Errorf("testAccCheckResourceDestroy: %q (%s) has not been destroyed", n, rs.Primary.ID)
 			}
 		case "megaport_mcr":
-			v, err := cfg.Client.GetMcr(rs.Primary.ID)
+			v, err := cfg.Client.GetMcr(context.Background(), rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			if v != nil && !isResourceDeleted(v.ProvisioningStatus) {
+				return fmt.Errorf("testAccCheckResourceDestroy: %q (%s) has not been destroyed", n, rs.Primary.ID)
+			}
+		case "megaport_mve":
+			v, err := cfg.Client.GetMve(context.Background(), rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			if v != nil && !isResourceDeleted(v.ProvisioningStatus) {
+				return fmt.Errorf("testAccCheckResourceDestroy: %q (%s) has not been destroyed", n, rs.Primary.ID)
+			}
+		case "megaport_mcr_prefix_filter_list":
+			id, err := strconv.ParseUint(rs.Primary.ID, 10, 64)
+			if err != nil {
+				return err
+			}
+			_, err = cfg.Client.GetMcrPrefixFilterList(context.Background(), rs.Primary.Attributes["mcr_id"], id)
+			if err == nil {
+				return fmt.Errorf("testAccCheckResourceDestroy: %q (%s) has not been destroyed", n, rs.Primary.ID)
+			}
+			if !api.IsNotFound(err) {
+				return err
+			}
+		case "megaport_ix":
+			v, err := cfg.Client.GetIx(context.Background(), rs.Primary.ID)
 			if err != nil {
 				return err
 			}
@@ -89,10 +179,14 @@ func testAccCheckResourceDestroy(s *terraform.State) error {
 			}
 		case "megaport_aws_vxc":
 			fallthrough
+		case "megaport_azure_vxc":
+			fallthrough
 		case "megaport_gcp_vxc":
 			fallthrough
+		case "megaport_oracle_vxc":
+			fallthrough
 		case "megaport_private_vxc":
-			v, err := cfg.Client.GetVxc(rs.Primary.ID)
+			v, err := cfg.Client.GetVxc(context.Background(), rs.Primary.ID)
 			if err != nil {
 				return err
 			}
@@ -106,6 +200,18 @@ func testAccCheckResourceDestroy(s *terraform.State) error {
 	return nil
 }
 
+func testAccCheckProductCancellationPending(uid string, pending bool) error {
+	cfg := testAccProvider.Meta().(*Config)
+	v, err := cfg.Client.GetPort(context.Background(), uid)
+	if err != nil {
+		return err
+	}
+	if v.CancellationPending() != pending {
+		return fmt.Errorf("testAccCheckProductCancellationPending: expected %s to be pending cancellation: %t, got status %s", uid, pending, v.ProvisioningStatus)
+	}
+	return nil
+}
+
 func testAccVxcSweeper(vxcType string) func(string) error {
 	return func(region string) error {
 		c, err := sharedClientForRegion(region)
@@ -113,21 +219,21 @@ func testAccVxcSweeper(vxcType string) func(string) error {
 			return fmt.Errorf("Error getting client: %s", err)
 		}
 		client := c.(*api.Client)
-		ports, err := client.ListPorts()
+		ports, err := client.ListPorts(context.Background())
 		if err != nil {
 			return err
 		}
 		for _, p := range ports {
 			for _, v := range p.AssociatedVxcs {
 				if strings.HasPrefix(v.ProductName, "terraform_acctest_") && !client.IsResourceDeleted(v.ProvisioningStatus) {
-					vxc, err := client.GetVxc(v.ProductUid)
+					vxc, err := client.GetVxc(context.Background(), v.ProductUid)
 					if err != nil {
 						return err
 					}
 					if vxc.Type() != vxcType {
 						continue
 					}
-					if err := client.DeleteVxc(vxc.ProductUid); err != nil {
+					if err := client.DeleteVxc(context.Background(), vxc.ProductUid); err != nil {
 						log.Printf("[ERROR] Could not destroy VXC %q (%s) during sweep: %s", vxc.ProductName, vxc.ProductUid, err)
 					}
 				}
@@ -136,3 +242,307 @@ func testAccVxcSweeper(vxcType string) func(string) error {
 		return nil
 	}
 }
+
+// testResourceRead runs the Read function of a resource against a local API
+// server and checks that the resource is only removed from state when the
+// product no longer exists, and that products that still exist are read into
+// the attributes. The live response must contain the provisioning status
+// "LIVE", which is replaced to simulate deleted products.
+func testResourceRead(t *testing.T, r *schema.Resource, live string, attributes map[string]string) {
+	testCases := []struct {
+		status int
+		body   string
+		gone   bool
+		err    bool
+	}{
+		{http.StatusOK, live, false, false},
+		{http.StatusOK, strings.Replace(live, `"LIVE"`, `"`+api.ProductStatusConfigured+`"`, 1), false, false},
+		{http.StatusOK, strings.Replace(live, `"LIVE"`, `"`+api.ProductStatusCancelled+`"`, 1), true, false},
+		{http.StatusOK, strings.Replace(live, `"LIVE"`, `"`+api.ProductStatusCancelledParent+`"`, 1), true, false},
+		{http.StatusOK, strings.Replace(live, `"LIVE"`, `"`+api.ProductStatusDecommissioned+`"`, 1), true, false},
+		{http.StatusNotFound, `{"message":"Not Found"}`, true, false},
+		{http.StatusUnauthorized, `{"message":"Invalid token"}`, false, true},
+		{http.StatusInternalServerError, `{"message":"Internal Server Error"}`, false, true},
+		{http.StatusBadGateway, `<html>Bad Gateway</html>`, false, true},
+	}
+	for i, tc := range testCases {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/v2/product/"+testResourceReadUid {
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(tc.status)
+			fmt.Fprint(w, tc.body)
+		}))
+		client := api.NewClient(s.URL)
+		client.MaxRetries = 0
+		d := r.TestResourceData()
+		d.SetId(testResourceReadUid)
+		diags := r.ReadContext(context.Background(), d, &Config{Client: client})
+		s.Close()
+		if diags.HasError() != tc.err {
+			t.Errorf("test case #%d: unexpected diagnostics: %#v", i, diags)
+		}
+		if (d.Id() == "") != tc.gone {
+			t.Errorf("test case #%d: unexpected id: got %q, expected the resource to be removed: %t", i, d.Id(), tc.gone)
+		}
+		if tc.gone || tc.err {
+			continue
+		}
+		state := d.State()
+		for k, v := range attributes {
+			if state.Attributes[k] != v {
+				t.Errorf("test case #%d: unexpected %s: got %q, expected %q", i, k, state.Attributes[k], v)
+			}
+		}
+	}
+}
+
+const testResourceReadUid = "e9ee2a96-9d59-4a1b-a5c5-ab3a1e8b0c1d"
+
+func TestCustomizeDiffValidateOrder(t *testing.T) {
+	mcrState := &terraform.InstanceState{
+		ID: testResourceReadUid,
+		Attributes: map[string]string{
+			"id":                   testResourceReadUid,
+			"location_id":          "1",
+			"name":                 "foo",
+			"rate_limit":           "1000",
+			"asn":                  "133937",
+			"cancellation_mode":    "now",
+			"cancellation_pending": "false",
+		},
+	}
+	mcrConfig := map[string]interface{}{"location_id": 1, "name": "foo", "rate_limit": 1000}
+	vxcConfig := func(vlan int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":       "foo",
+			"rate_limit": 100,
+			"a_end":      []interface{}{map[string]interface{}{"product_uid": "c", "vlan": vlan}},
+			"b_end":      []interface{}{map[string]interface{}{"product_uid": "b", "vlan": 200}},
+		}
+	}
+	const vlanInUse = `{"message":"Validation failed","data":[{"field":"aEnd.vlan","message":"VLAN 100 is already in use"}]}`
+	testCases := []struct {
+		resource  *schema.Resource
+		state     *terraform.InstanceState
+		config    map[string]interface{}
+		body      string
+		holder    string
+		validated bool
+		errors    []string
+		path      cty.Path
+	}{
+		{ // 0: a single error is reported against its attribute
+			resource:  resourceMegaportMcr(),
+			config:    mcrConfig,
+			body:      `{"message":"Validation failed","data":[{"field":"portSpeed","message":"MCR speed 1000 is not available"}]}`,
+			validated: true,
+			errors:    []string{"MCR speed 1000 is not available"},
+			path:      cty.GetAttrPath("rate_limit"),
+		},
+		{ // 1: all errors are returned, with or without an attribute
+			resource:  resourceMegaportMcr(),
+			config:    mcrConfig,
+			body:      `{"message":"Validation failed","data":[{"field":"portSpeed","message":"MCR speed 1000 is not available"},{"field":"productType","message":"Product type is not supported"},"Company is locked"]}`,
+			validated: true,
+			errors: []string{
+				"rate_limit: MCR speed 1000 is not available",
+				"invalid order: productType: Product type is not supported",
+				"invalid order: Company is locked",
+			},
+		},
+		{ // 2: errors without any fields fail the plan too
+			resource:  resourceMegaportMcr(),
+			config:    mcrConfig,
+			body:      `{"message":"Validation failed"}`,
+			validated: true,
+			errors:    []string{"invalid order: megaport-api (400): Validation failed"},
+		},
+		{ // 3: existing products are not validated
+			resource: resourceMegaportMcr(),
+			state:    mcrState,
+			config:   mcrConfig,
+		},
+		{ // 4: replacements are not validated until they are planned as new products
+			resource: resourceMegaportMcr(),
+			state:    mcrState,
+			config:   mergeMaps(mcrConfig, map[string]interface{}{"location_id": 2}),
+		},
+		{ // 5: VLANs held by a VXC of the same name, which is being replaced, are not in use
+			resource:  resourceMegaportPrivateVxc(),
+			config:    vxcConfig(100),
+			body:      vlanInUse,
+			holder:    `{"productUid":"v","productName":"foo","provisioningStatus":"LIVE","aEnd":{"productUid":"c","vlan":100}}`,
+			validated: true,
+		},
+		{ // 6: VLANs held by a VXC of another name are in use
+			resource:  resourceMegaportPrivateVxc(),
+			config:    vxcConfig(100),
+			body:      vlanInUse,
+			holder:    `{"productUid":"v","productName":"bar","provisioningStatus":"LIVE","aEnd":{"productUid":"c","vlan":100}}`,
+			validated: true,
+			errors:    []string{"VLAN 100 is already in use"},
+			path:      cty.GetAttrPath("a_end").IndexInt(0).GetAttr("vlan"),
+		},
+		{ // 7: VLANs that differ from those of a VXC of the same name are in use
+			resource:  resourceMegaportPrivateVxc(),
+			config:    vxcConfig(100),
+			body:      vlanInUse,
+			holder:    `{"productUid":"v","productName":"foo","provisioningStatus":"LIVE","aEnd":{"productUid":"c","vlan":300}}`,
+			validated: true,
+			errors:    []string{"VLAN 100 is already in use"},
+			path:      cty.GetAttrPath("a_end").IndexInt(0).GetAttr("vlan"),
+		},
+	}
+	cfg := &Config{}
+	for i, tc := range testCases {
+		validated := false
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tc.holder != "" && r.Method == http.MethodGet {
+				switch r.URL.Path {
+				case "/v2/product/c":
+					fmt.Fprintf(w, `{"data":{"productUid":"c","associatedVxcs":[%s]}}`, tc.holder)
+					return
+				case "/v2/product/v":
+					fmt.Fprintf(w, `{"data":%s}`, tc.holder)
+					return
+				}
+			}
+			if r.Method != http.MethodPost || r.URL.Path != "/v2/networkdesign/validate" {
+				t.Errorf("TestCustomizeDiffValidateOrder (#%d): unexpected request: %s %s", i, r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			validated = true
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, tc.body)
+		}))
+		cfg.Client = api.NewClient(s.URL)
+		cfg.Client.MaxRetries = 0
+		// Plans are made with SimpleDiff, which customizes the diff only once
+		_, err := tc.resource.SimpleDiff(context.Background(), tc.state, terraform.NewResourceConfigRaw(tc.config), cfg)
+		s.Close()
+		if validated != tc.validated {
+			t.Errorf("TestCustomizeDiffValidateOrder (#%d): expected the order to be validated: %t", i, tc.validated)
+		}
+		var errs []error
+		if me := (&multierror.Error{}); errors.As(err, &me) {
+			errs = me.Errors
+		} else if err != nil {
+			errs = []error{err}
+		}
+		msgs := make([]string, len(errs))
+		for j, e := range errs {
+			msgs[j] = e.Error()
+		}
+		if diff := cmp.Diff(tc.errors, msgs, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("TestCustomizeDiffValidateOrder (#%d): unexpected errors:\n%s", i, diff)
+		}
+		if tc.path == nil {
+			continue
+		}
+		pe := cty.PathError{}
+		if !errors.As(err, &pe) || !pe.Path.Equals(tc.path) {
+			t.Errorf("TestCustomizeDiffValidateOrder (#%d): expected an error for %#v, got %#v", i, tc.path, err)
+		}
+	}
+}
+
+func TestWaitUntilProductIsDeleted(t *testing.T) {
+	deletionDelay, deletionMinTimeout = 0, 0
+	defer func() { deletionDelay, deletionMinTimeout = 5*time.Second, 10*time.Second }()
+	terminateDate := time.Now().Add(24*time.Hour).UnixNano() / int64(time.Millisecond)
+	testCases := []struct {
+		responses []string
+		requests  int
+		err       bool
+	}{
+		{[]string{""}, 1, false},
+		{[]string{`{"provisioningStatus":"CANCELLED"}`}, 1, false},
+		{[]string{`{"provisioningStatus":"DECOMMISSIONED"}`}, 1, false},
+		{[]string{`{"provisioningStatus":"LIVE"}`, `{"provisioningStatus":"CANCELLED_PARENT"}`}, 2, false},
+		{[]string{`{"provisioningStatus":"LIVE"}`, ""}, 2, false},
+		{[]string{
+			fmt.Sprintf(`{"provisioningStatus":"CANCELLED","terminateDate":%d}`, terminateDate),
+			`{"provisioningStatus":"DECOMMISSIONED"}`,
+		}, 2, false},
+		{[]string{
+			`{"provisioningStatus":"CANCELLED","associatedVxcs":[{"provisioningStatus":"DECOMMISSIONED"},{"provisioningStatus":"LIVE"}]}`,
+			`{"provisioningStatus":"CANCELLED","associatedVxcs":[{"provisioningStatus":"DECOMMISSIONED"},{"provisioningStatus":"CANCELLED"}]}`,
+		}, 2, false},
+		{[]string{
+			`{"provisioningStatus":"CANCELLED","associatedIxs":[{"provisioningStatus":"CONFIGURED"}]}`,
+			`{"provisioningStatus":"CANCELLED","associatedIxs":[{"provisioningStatus":"DECOMMISSIONED"}]}`,
+		}, 2, false},
+		{[]string{fmt.Sprintf(`{"provisioningStatus":"CANCELLED","terminateDate":%d}`, terminateDate)}, 0, true},
+		{[]string{`{"provisioningStatus":"CANCELLED","associatedVxcs":[{"provisioningStatus":"LIVE"}]}`}, 0, true},
+	}
+	for i, tc := range testCases {
+		requests := 0
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/v2/product/"+testResourceReadUid {
+				t.Errorf("TestWaitUntilProductIsDeleted (#%d): unexpected request: %s %s", i, r.Method, r.URL.Path)
+			}
+			body := tc.responses[len(tc.responses)-1]
+			if requests < len(tc.responses) {
+				body = tc.responses[requests]
+			}
+			requests++
+			if body == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message":"Not Found"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":%s}`, body)
+		}))
+		client := api.NewClient(s.URL)
+		client.MaxRetries = 0
+		err := waitUntilProductIsDeleted(context.Background(), client.GetPort, testResourceReadUid, time.Second)
+		s.Close()
+		if (err != nil) != tc.err {
+			t.Errorf("TestWaitUntilProductIsDeleted (#%d): unexpected error: %v", i, err)
+		}
+		// Products that never get deleted are retrieved until the timeout
+		if !tc.err && requests != tc.requests {
+			t.Errorf("TestWaitUntilProductIsDeleted (#%d): expected %d requests, got %d", i, tc.requests, requests)
+		}
+	}
+}
+
+func TestWaitUntilVxcIsDeleted(t *testing.T) {
+	deletionDelay, deletionMinTimeout = 0, 0
+	defer func() { deletionDelay, deletionMinTimeout = 5*time.Second, 10*time.Second }()
+	testCases := []struct {
+		status int
+		body   string
+		vlans  string
+		err    bool
+	}{
+		{http.StatusNotFound, `{"message":"Not Found"}`, "", false},
+		{http.StatusOK, `{"data":{"provisioningStatus":"CANCELLED","aEnd":{"productUid":"a","vlan":100}}}`, `{"data":[100]}`, false},
+		{http.StatusOK, `{"data":{"provisioningStatus":"DECOMMISSIONED","aEnd":{"productUid":"a","vlan":100}}}`, `{"data":[]}`, true},
+		{http.StatusOK, `{"data":{"provisioningStatus":"LIVE","aEnd":{"productUid":"a"}}}`, "", true},
+		{http.StatusInternalServerError, `{"message":"Internal Server Error"}`, "", true},
+	}
+	for i, tc := range testCases {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/v2/product/"+testResourceReadUid:
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			case r.Method == http.MethodGet && r.URL.Path == "/v2/product/port/a/vlan" && tc.vlans != "":
+				fmt.Fprint(w, tc.vlans)
+			default:
+				t.Errorf("TestWaitUntilVxcIsDeleted (#%d): unexpected request: %s %s", i, r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		client := api.NewClient(s.URL)
+		client.MaxRetries = 0
+		err := waitUntilVxcIsDeleted(context.Background(), client, testResourceReadUid, time.Second)
+		s.Close()
+		if (err != nil) != tc.err {
+			t.Errorf("TestWaitUntilVxcIsDeleted (#%d): unexpected error: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportInternetExchange() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportInternetExchangeRead,
+
+		Schema: map[string]*schema.Schema{
+			"location_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"asn": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ecix": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"group_metro": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"network_region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceMegaportInternetExchangeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	ixs, err := cfg.Client.GetInternetExchanges(ctx, uint64(d.Get("location_id").(int)))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	nr := regexp.MustCompile(d.Get("name_regex").(string))
+	var filtered []*api.InternetExchange
+	for _, ix := range ixs {
+		if nr.MatchString(ix.Name) {
+			filtered = append(filtered, ix)
+		}
+	}
+	if len(filtered) < 1 {
+		return diag.FromErr(fmt.Errorf("No Internet Exchanges were found."))
+	}
+	if len(filtered) > 1 {
+		return diag.FromErr(fmt.Errorf("Multiple Internet Exchanges were found. Please use a more specific query."))
+	}
+	ix := filtered[0]
+	d.SetId(ix.Name)
+	if err := d.Set("name", ix.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("asn", int(ix.ASN)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("description", ix.Description); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ecix", ix.ECIX); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_metro", ix.GroupMetro); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("network_region", ix.NetworkRegion); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", ix.State); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
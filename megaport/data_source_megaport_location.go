@@ -6,6 +6,7 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -14,10 +15,6 @@ import (
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
 
-var (
-	megaportLocations []*api.Location
-)
-
 func dataSourceMegaportLocation() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceMegaportLocationRead,
@@ -25,43 +22,266 @@ func dataSourceMegaportLocation() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"name_regex": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
 				ForceNew:     true,
 				ValidateFunc: validation.StringIsValidRegExp,
 			},
+			"metro": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"country": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"market": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"site_code": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			// mcr_available is a string rather than a bool, so that filtering
+			// on false can be told apart from not filtering at all
+			"mcr_available": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"true", "false"}, false),
+			},
+			"port_speed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"campus": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"network_region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"latitude": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"longitude": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"live_date": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"address": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportLocationAddress(),
+			},
+			"products": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportLocationProducts(),
+			},
 		},
 	}
 }
 
-func dataSourceUpdateLocations(c *api.Client) error {
-	megaportMutexKV.Lock("locations")
-	defer megaportMutexKV.Unlock("locations")
-	if megaportLocations != nil {
-		return nil
+func dataSourceMegaportLocationAddress() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"street": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"suburb": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"city": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"postcode": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"country": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceMegaportLocationProducts() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"mcr": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"mcr_version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"mcr1_speeds": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			"mcr2_speeds": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			"port_speeds": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+		},
 	}
-	log.Printf("[INFO] Updating location list")
-	loc, err := c.GetLocations()
+}
+
+func dataSourceUpdateLocations(ctx context.Context, cfg *Config) ([]*api.Location, error) {
+	v, err := cfg.cache.get(cacheKeyLocations, func() (interface{}, error) {
+		log.Printf("[INFO] Updating location list")
+		return cfg.Client.GetLocations(ctx)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	megaportLocations = loc
-	return nil
+	return v.([]*api.Location), nil
 }
 
-func dataSourceMegaportLocationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	cfg := m.(*Config)
-	if err := dataSourceUpdateLocations(cfg.Client); err != nil {
-		return diag.FromErr(err)
+// filterLocations returns the locations that match all of the filters set in
+// d. String filters are case insensitive, apart from name_regex.
+func filterLocations(d *schema.ResourceData, locations []*api.Location) []*api.Location {
+	var nr *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nr = regexp.MustCompile(v.(string))
+	}
+	strFilters := map[string]func(*api.Location) string{
+		"metro":     func(l *api.Location) string { return l.Metro },
+		"country":   func(l *api.Location) string { return l.Country },
+		"market":    func(l *api.Location) string { return l.Market },
+		"site_code": func(l *api.Location) string { return l.SiteCode },
+		"status":    func(l *api.Location) string { return l.Status },
 	}
-	var filtered []*api.Location
-	if nameRegex, ok := d.GetOk("name_regex"); ok {
-		nr := regexp.MustCompile(nameRegex.(string))
-		for _, loc := range megaportLocations {
-			if nr.MatchString(loc.Name) {
-				filtered = append(filtered, loc)
+	filtered := []*api.Location{}
+	for _, loc := range locations {
+		if nr != nil && !nr.MatchString(loc.Name) {
+			continue
+		}
+		match := true
+		for k, f := range strFilters {
+			if v, ok := d.GetOk(k); ok && !strings.EqualFold(v.(string), f(loc)) {
+				match = false
 			}
 		}
+		if v, ok := d.GetOk("mcr_available"); ok && v.(string) != strconv.FormatBool(loc.VRouterAvailable) {
+			match = false
+		}
+		if v, ok := d.GetOk("port_speed"); ok && !locationHasPortSpeed(loc, uint64(v.(int))) {
+			match = false
+		}
+		if match {
+			filtered = append(filtered, loc)
+		}
+	}
+	return filtered
+}
+
+// locationPortSpeeds returns the port speeds available at loc in Mbps, like
+// the speed of megaport_port, as Megaport lists them in Gbps.
+func locationPortSpeeds(loc *api.Location) []uint64 {
+	speeds := make([]uint64, len(loc.Products.Megaport))
+	for i, s := range loc.Products.Megaport {
+		speeds[i] = s * 1000
 	}
+	return speeds
+}
+
+func locationHasPortSpeed(loc *api.Location, speed uint64) bool {
+	for _, s := range locationPortSpeeds(loc) {
+		if s == speed {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenLocation(loc *api.Location) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           loc.Name,
+		"metro":          loc.Metro,
+		"country":        loc.Country,
+		"market":         loc.Market,
+		"site_code":      loc.SiteCode,
+		"status":         loc.Status,
+		"mcr_available":  loc.VRouterAvailable,
+		"campus":         loc.Campus,
+		"network_region": loc.NetworkRegion,
+		"latitude":       loc.Latitude,
+		"longitude":      loc.Longitude,
+		"live_date":      int(loc.LiveDate),
+		"address": []interface{}{map[string]interface{}{
+			"street":   loc.Address.Street,
+			"suburb":   loc.Address.Suburb,
+			"city":     loc.Address.City,
+			"state":    loc.Address.State,
+			"postcode": loc.Address.Postcode,
+			"country":  loc.Address.Country,
+		}},
+		"products": []interface{}{map[string]interface{}{
+			"mcr":         loc.Products.Mcr,
+			"mcr_version": int(loc.Products.McrVersion),
+			"mcr1_speeds": flattenBandwidths(loc.Products.Mcr1),
+			"mcr2_speeds": flattenBandwidths(loc.Products.Mcr2),
+			"port_speeds": flattenBandwidths(locationPortSpeeds(loc)),
+		}},
+	}
+}
+
+func dataSourceMegaportLocationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	locations, err := dataSourceUpdateLocations(ctx, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	filtered := filterLocations(d, locations)
 	if len(filtered) < 1 {
 		return diag.FromErr(fmt.Errorf("No locations were found."))
 	}
@@ -69,5 +289,13 @@ func dataSourceMegaportLocationRead(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(fmt.Errorf("Multiple locations were found. Please use a more specific query."))
 	}
 	d.SetId(strconv.FormatUint(filtered[0].Id, 10))
+	for k, v := range flattenLocation(filtered[0]) {
+		if k == "mcr_available" {
+			v = strconv.FormatBool(v.(bool))
+		}
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return nil
 }
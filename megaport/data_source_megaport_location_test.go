@@ -0,0 +1,96 @@
+package megaport
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func TestAccDataSourceMegaportLocation_basic(t *testing.T) {
+	testAccCassette(t)
+	configValues := map[string]interface{}{
+		"location":     "Telehouse North$",
+		"mcrAvailable": true,
+	}
+	cfg, err := newTestAccConfig("megaport_location", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgNoMatch, err := newTestAccConfig("megaport_location", mergeMaps(configValues, map[string]interface{}{"mcrAvailable": false}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgMultipleMatches, err := newTestAccConfig("megaport_location", mergeMaps(configValues, map[string]interface{}{"location": "."}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.megaport_location.foo", "id"),
+					resource.TestCheckResourceAttr("data.megaport_location.foo", "name", "Telehouse North"),
+					resource.TestCheckResourceAttr("data.megaport_location.foo", "metro", "London"),
+					resource.TestCheckResourceAttr("data.megaport_location.foo", "mcr_available", "true"),
+					resource.TestCheckResourceAttr("data.megaport_location.foo", "address.#", "1"),
+					resource.TestCheckResourceAttrSet("data.megaport_location.foo", "site_code"),
+					resource.TestCheckResourceAttrSet("data.megaport_location.foo", "products.0.port_speeds.#"),
+				),
+			},
+			{
+				PreConfig:   func() { cfgNoMatch.log() },
+				Config:      cfgNoMatch.Config,
+				ExpectError: regexp.MustCompile("No locations were found."),
+			},
+			{
+				PreConfig:   func() { cfgMultipleMatches.log() },
+				Config:      cfgMultipleMatches.Config,
+				ExpectError: regexp.MustCompile("Multiple locations were found."),
+			},
+		},
+	})
+}
+
+func TestFilterLocations(t *testing.T) {
+	locations := []*api.Location{
+		{Id: 1, Name: "foo", Metro: "London", VRouterAvailable: true, Products: api.LocationProducts{Megaport: []uint64{1, 10}}},
+		{Id: 2, Name: "bar", Metro: "London", VRouterAvailable: false, Products: api.LocationProducts{Megaport: []uint64{1}}},
+		{Id: 3, Name: "baz", Metro: "Dublin", VRouterAvailable: true, Products: api.LocationProducts{Megaport: []uint64{1}}},
+	}
+	testCases := []struct {
+		filters  map[string]interface{}
+		expected []uint64
+	}{
+		{map[string]interface{}{}, []uint64{1, 2, 3}},
+		{map[string]interface{}{"mcr_available": "true"}, []uint64{1, 3}},
+		{map[string]interface{}{"mcr_available": "false"}, []uint64{2}},
+		{map[string]interface{}{"metro": "london"}, []uint64{1, 2}},
+		{map[string]interface{}{"metro": "london", "mcr_available": "true"}, []uint64{1}},
+		{map[string]interface{}{"name_regex": "^ba", "mcr_available": "false"}, []uint64{2}},
+		{map[string]interface{}{"port_speed": 10000}, []uint64{1}},
+	}
+	// megaport_location and megaport_locations share the filters, but only the
+	// former exports them too
+	for _, r := range []*schema.Resource{dataSourceMegaportLocation(), dataSourceMegaportLocations()} {
+		for i, tc := range testCases {
+			d := schema.TestResourceDataRaw(t, r.Schema, tc.filters)
+			ids := []uint64{}
+			for _, loc := range filterLocations(d, locations) {
+				ids = append(ids, loc.Id)
+			}
+			if diff := cmp.Diff(tc.expected, ids); diff != "" {
+				t.Errorf("TestFilterLocations (#%d): unexpected locations (-want +got):\n%s", i, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package megaport
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportLocations() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"ids": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+		},
+		"locations": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     dataSourceMegaportLocationsElem(),
+		},
+	}
+	// The filters are the same as those of megaport_location
+	for _, k := range []string{"name_regex", "metro", "country", "market", "site_code", "status", "mcr_available", "port_speed"} {
+		f := *dataSourceMegaportLocation().Schema[k]
+		f.Computed = false
+		s[k] = &f
+	}
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportLocationsRead,
+
+		Schema: s,
+	}
+}
+
+// dataSourceMegaportLocationsElem returns the attributes exported by
+// megaport_location, along with the id of the location.
+func dataSourceMegaportLocationsElem() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+	}
+	for k := range flattenLocation(&api.Location{}) {
+		a := *dataSourceMegaportLocation().Schema[k]
+		a.Optional, a.ForceNew, a.Computed = false, false, true
+		if k == "mcr_available" {
+			a.Type, a.ValidateFunc = schema.TypeBool, nil
+		}
+		s[k] = &a
+	}
+	return &schema.Resource{
+		Schema: s,
+	}
+}
+
+func dataSourceMegaportLocationsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	all, err := dataSourceUpdateLocations(ctx, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	filtered := filterLocations(d, all)
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Name != filtered[j].Name {
+			return filtered[i].Name < filtered[j].Name
+		}
+		return filtered[i].Id < filtered[j].Id
+	})
+	ids := make([]int, len(filtered))
+	keys := make([]string, len(filtered))
+	locations := make([]interface{}, len(filtered))
+	for i, loc := range filtered {
+		ids[i] = int(loc.Id)
+		keys[i] = strconv.FormatUint(loc.Id, 10)
+		l := flattenLocation(loc)
+		l["id"] = int(loc.Id)
+		locations[i] = l
+	}
+	d.SetId(dataSourceListId(keys))
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("locations", locations); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
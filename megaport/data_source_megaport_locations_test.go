@@ -0,0 +1,61 @@
+package megaport
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccDataSourceMegaportLocations_basic(t *testing.T) {
+	testAccCassette(t)
+	cfg, err := newTestAccConfig("megaport_locations", map[string]interface{}{
+		"metro": "London",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.megaport_locations.foo", "locations.0.id"),
+					resource.TestCheckResourceAttr("data.megaport_locations.foo", "locations.0.metro", "London"),
+					resource.TestCheckResourceAttrPair("data.megaport_locations.foo", "ids.0", "data.megaport_locations.foo", "locations.0.id"),
+					resource.TestCheckResourceAttrPair("data.megaport_locations.foo", "ids.#", "data.megaport_locations.foo", "locations.#"),
+					resource.TestCheckResourceAttrSet("data.megaport_locations.no_mcr", "locations.0.id"),
+					testAccCheckLocationsMcrAvailable("data.megaport_locations.no_mcr", false),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckLocationsMcrAvailable checks that mcr_available is v for all of
+// the locations returned by the megaport_locations data source n.
+func testAccCheckLocationsMcrAvailable(n string, v bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("testAccCheckLocationsMcrAvailable: cannot find %q", n)
+		}
+		c, err := strconv.Atoi(rs.Primary.Attributes["locations.#"])
+		if err != nil {
+			return err
+		}
+		for i := 0; i < c; i++ {
+			k := fmt.Sprintf("locations.%d.mcr_available", i)
+			if rs.Primary.Attributes[k] != strconv.FormatBool(v) {
+				return fmt.Errorf("testAccCheckLocationsMcrAvailable: expected %s of %q to be %t but got %q", k, n, v, rs.Primary.Attributes[k])
+			}
+		}
+		return nil
+	}
+}
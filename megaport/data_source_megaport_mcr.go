@@ -0,0 +1,127 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportMcr() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportMcrRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"asn": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"provisioning_status": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rate_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"vxcs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportAssociatedVxcElem(),
+			},
+		},
+	}
+}
+
+func isMcr(p *api.Product) bool {
+	return p.ProductType == api.ProductTypeMcr2 || (p.ProductType == api.ProductTypeMcr1 && p.Virtual)
+}
+
+func dataSourceMegaportMcrRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	products, err := dataSourceUpdatePorts(ctx, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	nr := regexp.MustCompile(d.Get("name_regex").(string))
+	var filtered []*api.Product
+	for _, p := range products {
+		if !isMcr(p) || !nr.MatchString(p.ProductName) {
+			continue
+		}
+		if v, ok := d.GetOk("provisioning_status"); ok {
+			if !strings.EqualFold(p.ProvisioningStatus, v.(string)) {
+				continue
+			}
+		} else if isResourceDeleted(p.ProvisioningStatus) {
+			continue
+		}
+		if v, ok := d.GetOk("location_id"); ok && p.LocationId != uint64(v.(int)) {
+			continue
+		}
+		if v, ok := d.GetOk("asn"); ok && p.Resources.VirtualRouter.McrASN != uint64(v.(int)) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	if len(filtered) < 1 {
+		return diag.FromErr(fmt.Errorf("No MCRs were found."))
+	}
+	if len(filtered) > 1 {
+		return diag.FromErr(fmt.Errorf("Multiple MCRs were found. Please use a more specific query."))
+	}
+	p := filtered[0]
+	d.SetId(p.ProductUid)
+	if err := d.Set("name", p.ProductName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("location_id", int(p.LocationId)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("asn", int(p.Resources.VirtualRouter.McrASN)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rate_limit", int(p.PortSpeed)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("provisioning_status", p.ProvisioningStatus); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vxcs", flattenAssociatedVxcs(p.AssociatedVxcs)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
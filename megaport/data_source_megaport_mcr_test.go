@@ -0,0 +1,67 @@
+package megaport
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceMegaportMcr_basic(t *testing.T) {
+	testAccCassette(t)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	asn := testAccRandIntRange(64512, 65534)
+	configValues := map[string]interface{}{
+		"uid":       rName,
+		"location":  "Telehouse North$",
+		"asn":       asn,
+		"nameRegex": "^terraform_acctest_.*_" + rName + "$",
+		"asnFilter": asn,
+	}
+	cfg, err := newTestAccConfig("megaport_mcr", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgNoMatch, err := newTestAccConfig("megaport_mcr", mergeMaps(configValues, map[string]interface{}{"asnFilter": asn + 1}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgMultipleMatches, err := newTestAccConfig("megaport_mcr", mergeMaps(configValues, map[string]interface{}{"asnFilter": nil}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.megaport_mcr.foo", "id", "megaport_mcr.foo", "id"),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "name", "terraform_acctest_foo_"+rName),
+					resource.TestCheckResourceAttrPair("data.megaport_mcr.foo", "location_id", "data.megaport_location.foo", "id"),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "asn", strconv.Itoa(asn)),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "rate_limit", "1000"),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "invoice_reference", rName),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "provisioning_status", "LIVE"),
+					resource.TestCheckResourceAttr("data.megaport_mcr.foo", "vxcs.#", "0"),
+				),
+			},
+			{
+				PreConfig:   func() { cfgNoMatch.log() },
+				Config:      cfgNoMatch.Config,
+				ExpectError: regexp.MustCompile("No MCRs were found."),
+			},
+			{
+				PreConfig:   func() { cfgMultipleMatches.log() },
+				Config:      cfgMultipleMatches.Config,
+				ExpectError: regexp.MustCompile("Multiple MCRs were found."),
+			},
+		},
+	})
+}
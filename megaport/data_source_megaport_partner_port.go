@@ -15,10 +15,6 @@ import (
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
 
-var (
-	megaportPartnerPorts []*api.Megaport
-)
-
 func dataSourceMegaportPartnerPort() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceMegaportPartnerPortRead,
@@ -34,23 +30,37 @@ func dataSourceMegaportPartnerPort() *schema.Resource {
 				Type:         schema.TypeList,
 				MaxItems:     1,
 				Optional:     true,
-				ExactlyOneOf: []string{"aws", "gcp", "marketplace"},
+				ExactlyOneOf: []string{"aws", "azure", "gcp", "marketplace", "oracle"},
 				Elem:         dataSourceMegaportPartnerPortMarketplace(),
 			},
+			"azure": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ExactlyOneOf: []string{"aws", "azure", "gcp", "marketplace", "oracle"},
+				Elem:         dataSourceMegaportPartnerPortAzure(),
+			},
 			"gcp": {
 				Type:         schema.TypeList,
 				MaxItems:     1,
 				Optional:     true,
-				ExactlyOneOf: []string{"aws", "gcp", "marketplace"},
+				ExactlyOneOf: []string{"aws", "azure", "gcp", "marketplace", "oracle"},
 				Elem:         dataSourceMegaportPartnerPortGcp(),
 			},
 			"marketplace": {
 				Type:         schema.TypeList,
 				MaxItems:     1,
 				Optional:     true,
-				ExactlyOneOf: []string{"aws", "gcp", "marketplace"},
+				ExactlyOneOf: []string{"aws", "azure", "gcp", "marketplace", "oracle"},
 				Elem:         dataSourceMegaportPartnerPortMarketplace(),
 			},
+			"oracle": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ExactlyOneOf: []string{"aws", "azure", "gcp", "marketplace", "oracle"},
+				Elem:         dataSourceMegaportPartnerPortOracle(),
+			},
 			"bandwidths": {
 				Type: schema.TypeList,
 				Elem: &schema.Schema{
@@ -80,6 +90,25 @@ func dataSourceMegaportPartnerPortMarketplace() *schema.Resource {
 	}
 }
 
+func dataSourceMegaportPartnerPortAzure() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"service_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"primary", "secondary"}, false),
+			},
+		},
+	}
+}
+
 func dataSourceMegaportPartnerPortGcp() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
@@ -93,29 +122,47 @@ func dataSourceMegaportPartnerPortGcp() *schema.Resource {
 	}
 }
 
-func dataSourceUpdatePartnerPorts(c *api.Client) error {
-	megaportMutexKV.Lock("partner_ports")
-	defer megaportMutexKV.Unlock("partner_ports")
-	if megaportPartnerPorts != nil {
-		return nil
+func dataSourceMegaportPartnerPortOracle() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"virtual_circuit_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateOracleVirtualCircuitId,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"primary", "secondary"}, false),
+			},
+		},
 	}
-	log.Printf("[INFO] Updating partner port list")
-	pp, err := c.GetMegaports() // TODO: rename in api
+}
+
+var validateOracleVirtualCircuitId = validation.StringMatch(regexp.MustCompile(`^ocid1\.virtualcircuit\.[\w-]+\.[\w-]*\.\w+$`), "Invalid OCI virtual circuit OCID format")
+
+func dataSourceUpdatePartnerPorts(ctx context.Context, cfg *Config) ([]*api.Megaport, error) {
+	v, err := cfg.cache.get(cacheKeyPartnerPorts, func() (interface{}, error) {
+		log.Printf("[INFO] Updating partner port list")
+		return cfg.Client.GetMegaports(ctx) // TODO: rename in api
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	megaportPartnerPorts = pp
-	return nil
+	return v.([]*api.Megaport), nil
 }
 
 func dataSourceMegaportPartnerPortRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
 	nameRegex := d.Get("name_regex").(string)
 	if v, ok := d.GetOk("aws"); ok {
-		if err := dataSourceUpdatePartnerPorts(cfg.Client); err != nil {
+		ports, err := dataSourceUpdatePartnerPorts(ctx, cfg)
+		if err != nil {
 			return diag.FromErr(err)
 		}
-		p, err := filterPartnerPorts(megaportPartnerPorts, "AWS", nameRegex, expandFilters(v))
+		p, err := filterPartnerPorts(ports, "AWS", nameRegex, expandFilters(v))
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -126,10 +173,11 @@ func dataSourceMegaportPartnerPortRead(ctx context.Context, d *schema.ResourceDa
 		return nil
 	}
 	if v, ok := d.GetOk("marketplace"); ok {
-		if err := dataSourceUpdatePartnerPorts(cfg.Client); err != nil {
+		ports, err := dataSourceUpdatePartnerPorts(ctx, cfg)
+		if err != nil {
 			return diag.FromErr(err)
 		}
-		p, err := filterPartnerPorts(megaportPartnerPorts, "DEFAULT", nameRegex, expandFilters(v))
+		p, err := filterPartnerPorts(ports, "DEFAULT", nameRegex, expandFilters(v))
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -151,19 +199,47 @@ func dataSourceMegaportPartnerPortRead(ctx context.Context, d *schema.ResourceDa
 			return diag.FromErr(err)
 		}
 		pk = randomUUID + "/" + strings.SplitN(pk, "/", 2)[1]
-		ports, bandwidths, err := cfg.Client.GetMegaportsForGcpPairingKey(pk)
+		ports, bandwidths, err := cfg.Client.GetMegaportsForGcpPairingKey(ctx, pk)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		p, err := filterCloudPartnerPorts(ports, nameRegex)
+		p, err := filterCloudPartnerPorts(ports, nameRegex, "")
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		bw := make([]int, len(bandwidths))
-		for i, v := range bandwidths {
-			bw[i] = int(v)
+		if err := d.Set("bandwidths", flattenBandwidths(bandwidths)); err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(p.ProductUid)
+		return nil
+	}
+	if v, ok := d.GetOk("azure"); ok {
+		f := expandFilters(v)
+		ports, bandwidths, err := cfg.Client.GetMegaportsForAzureServiceKey(ctx, f["service_key"].(string))
+		if err != nil {
+			return diag.FromErr(err)
 		}
-		if err := d.Set("bandwidths", bw); err != nil {
+		p, err := filterCloudPartnerPorts(ports, nameRegex, f["type"].(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("bandwidths", flattenBandwidths(bandwidths)); err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(p.ProductUid)
+		return nil
+	}
+	if v, ok := d.GetOk("oracle"); ok {
+		f := expandFilters(v)
+		ports, bandwidths, err := cfg.Client.GetMegaportsForOracleVirtualCircuit(ctx, f["virtual_circuit_id"].(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		p, err := filterCloudPartnerPorts(ports, nameRegex, f["type"].(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("bandwidths", flattenBandwidths(bandwidths)); err != nil {
 			return diag.FromErr(err)
 		}
 		d.SetId(p.ProductUid)
@@ -172,6 +248,14 @@ func dataSourceMegaportPartnerPortRead(ctx context.Context, d *schema.ResourceDa
 	return nil
 }
 
+func flattenBandwidths(bandwidths []uint64) []int {
+	bw := make([]int, len(bandwidths))
+	for i, v := range bandwidths {
+		bw[i] = int(v)
+	}
+	return bw
+}
+
 func expandFilters(v interface{}) map[string]interface{} {
 	return v.([]interface{})[0].(map[string]interface{})
 }
@@ -219,11 +303,13 @@ func filterPartnerPorts(ports []*api.Megaport, connectType, nameRegex string, d
 	return filtered[0], nil
 }
 
-func filterCloudPartnerPorts(ports []*api.MegaportCloud, nameRegex string) (*api.MegaportCloud, error) {
+// filterCloudPartnerPorts returns the only port whose name matches nameRegex
+// and, unless it is empty, whose type is portType.
+func filterCloudPartnerPorts(ports []*api.MegaportCloud, nameRegex, portType string) (*api.MegaportCloud, error) {
 	filtered := []*api.MegaportCloud{}
 	nr := regexp.MustCompile(nameRegex)
 	for _, port := range ports {
-		if nr.MatchString(port.Name) {
+		if nr.MatchString(port.Name) && (portType == "" || strings.EqualFold(port.Type, portType)) {
 			filtered = append(filtered, port)
 		}
 	}
@@ -0,0 +1,183 @@
+package megaport
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportPartnerPorts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportPartnerPortsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"connect_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"company_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"metro": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"vxc_permitted": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				ForceNew: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"partner_ports": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportPartnerPortsElem(),
+			},
+		},
+	}
+}
+
+func dataSourceMegaportPartnerPortsElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"connect_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"company_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"company_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"speed": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"rank": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"vxc_permitted": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"lag_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceMegaportPartnerPortsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	partnerPorts, err := dataSourceUpdatePartnerPorts(ctx, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	metros := map[uint64]string{}
+	if _, ok := d.GetOk("metro"); ok {
+		locations, err := dataSourceUpdateLocations(ctx, cfg)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, loc := range locations {
+			metros[loc.Id] = loc.Metro
+		}
+	}
+	nr := regexp.MustCompile(d.Get("name_regex").(string))
+	filtered := []*api.Megaport{}
+	for _, p := range partnerPorts {
+		if !nr.MatchString(p.Title) || p.VxcPermitted != d.Get("vxc_permitted").(bool) {
+			continue
+		}
+		if v, ok := d.GetOk("connect_type"); ok && !strings.EqualFold(p.ConnectType, v.(string)) {
+			continue
+		}
+		if v, ok := d.GetOk("company_name"); ok && !strings.EqualFold(p.CompanyName, v.(string)) {
+			continue
+		}
+		if v, ok := d.GetOk("location_id"); ok && p.LocationId != uint64(v.(int)) {
+			continue
+		}
+		if v, ok := d.GetOk("metro"); ok && !strings.EqualFold(metros[p.LocationId], v.(string)) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Title != filtered[j].Title {
+			return filtered[i].Title < filtered[j].Title
+		}
+		return filtered[i].ProductUid < filtered[j].ProductUid
+	})
+	ids := make([]string, len(filtered))
+	ports := make([]interface{}, len(filtered))
+	for i, p := range filtered {
+		ids[i] = p.ProductUid
+		ports[i] = map[string]interface{}{
+			"id":            p.ProductUid,
+			"name":          p.Title,
+			"connect_type":  p.ConnectType,
+			"company_name":  p.CompanyName,
+			"company_uid":   p.CompanyUid,
+			"location_id":   int(p.LocationId),
+			"speed":         int(p.Speed),
+			"rank":          int(p.Rank),
+			"vxc_permitted": p.VxcPermitted,
+			"lag_id":        int(p.LagId),
+		}
+	}
+	d.SetId(dataSourceListId(ids))
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("partner_ports", ports); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package megaport
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceMegaportPartnerPorts_basic(t *testing.T) {
+	testAccCassette(t)
+	cfg, err := newTestAccConfig("megaport_partner_ports", map[string]interface{}{
+		"metro": "London",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.megaport_partner_ports.aws", "partner_ports.0.id"),
+					resource.TestCheckResourceAttr("data.megaport_partner_ports.aws", "partner_ports.0.connect_type", "AWS"),
+					resource.TestCheckResourceAttr("data.megaport_partner_ports.aws", "partner_ports.0.vxc_permitted", "true"),
+					resource.TestCheckResourceAttrPair("data.megaport_partner_ports.aws", "ids.0", "data.megaport_partner_ports.aws", "partner_ports.0.id"),
+				),
+			},
+		},
+	})
+}
@@ -13,10 +13,6 @@ import (
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
 
-var (
-	megaportPorts []*api.Product
-)
-
 func dataSourceMegaportPort() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceMegaportPortRead,
@@ -32,30 +28,27 @@ func dataSourceMegaportPort() *schema.Resource {
 	}
 }
 
-func dataSourceUpdatePorts(c *api.Client) error {
-	megaportMutexKV.Lock("ports")
-	defer megaportMutexKV.Unlock("ports")
-	if megaportPorts != nil {
-		return nil
-	}
-	log.Printf("[INFO] Updating port list")
-	pp, err := c.ListPorts()
+func dataSourceUpdatePorts(ctx context.Context, cfg *Config) ([]*api.Product, error) {
+	v, err := cfg.cache.get(cacheKeyProducts, func() (interface{}, error) {
+		log.Printf("[INFO] Updating port list")
+		return cfg.Client.ListPorts(ctx)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	megaportPorts = pp
-	return nil
+	return v.([]*api.Product), nil
 }
 
 func dataSourceMegaportPortRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	if err := dataSourceUpdatePorts(cfg.Client); err != nil {
+	ports, err := dataSourceUpdatePorts(ctx, cfg)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 	var filtered []*api.Product
 	if nameRegex, ok := d.GetOk("name_regex"); ok {
 		nr := regexp.MustCompile(nameRegex.(string))
-		for _, port := range megaportPorts {
+		for _, port := range ports {
 			if nr.MatchString(port.ProductName) {
 				filtered = append(filtered, port)
 			}
@@ -0,0 +1,149 @@
+package megaport
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportPorts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportPortsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"speed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ports": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportPortsElem(),
+			},
+		},
+	}
+}
+
+func dataSourceMegaportPortsElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"speed": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"term": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"marketplace_visibility": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"lag_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"provisioning_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceMegaportPortsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	products, err := dataSourceUpdatePorts(ctx, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	nr := regexp.MustCompile(d.Get("name_regex").(string))
+	filtered := []*api.Product{}
+	for _, p := range products {
+		if p.ProductType != api.ProductTypePort || p.Virtual || isResourceDeleted(p.ProvisioningStatus) || !nr.MatchString(p.ProductName) {
+			continue
+		}
+		if v, ok := d.GetOk("location_id"); ok && p.LocationId != uint64(v.(int)) {
+			continue
+		}
+		if v, ok := d.GetOk("speed"); ok && p.PortSpeed != uint64(v.(int)) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].ProductName != filtered[j].ProductName {
+			return filtered[i].ProductName < filtered[j].ProductName
+		}
+		return filtered[i].ProductUid < filtered[j].ProductUid
+	})
+	ids := make([]string, len(filtered))
+	ports := make([]interface{}, len(filtered))
+	for i, p := range filtered {
+		ids[i] = p.ProductUid
+		visibility := "private"
+		if p.MarketplaceVisibility {
+			visibility = "public"
+		}
+		ports[i] = map[string]interface{}{
+			"id":                     p.ProductUid,
+			"name":                   p.ProductName,
+			"location_id":            int(p.LocationId),
+			"speed":                  int(p.PortSpeed),
+			"term":                   int(p.ContractTermMonths),
+			"marketplace_visibility": visibility,
+			"invoice_reference":      p.CostCentre,
+			"lag_id":                 int(p.LagId),
+			"provisioning_status":    p.ProvisioningStatus,
+		}
+	}
+	d.SetId(dataSourceListId(ids))
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ports", ports); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package megaport
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceMegaportPorts_basic(t *testing.T) {
+	testAccCassette(t)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	cfg, err := newTestAccConfig("megaport_ports", map[string]interface{}{
+		"uid":      rName,
+		"location": "Telehouse North$",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.megaport_ports.foo", "ports.#", "1"),
+					resource.TestCheckResourceAttrPair("data.megaport_ports.foo", "ids.0", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttrPair("data.megaport_ports.foo", "ports.0.id", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttrPair("data.megaport_ports.foo", "ports.0.location_id", "megaport_port.foo", "location_id"),
+					resource.TestCheckResourceAttr("data.megaport_ports.foo", "ports.0.speed", "1000"),
+					resource.TestCheckResourceAttr("data.megaport_ports.speed", "ports.#", "0"),
+					resource.TestCheckResourceAttr("data.megaport_ports.speed", "ids.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,333 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func dataSourceMegaportVxc() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"product_uid": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"product_uid", "name_regex"},
+		},
+		"name_regex": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringIsValidRegExp,
+			ExactlyOneOf: []string{"product_uid", "name_regex"},
+		},
+		"invoice_reference": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"aws": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"vif_id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"aws_connection_name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"aws_account_id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"aws_ip_address": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"customer_asn": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"customer_ip_address": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"type": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"azure": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"service_key": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"gcp": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"pairing_key": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"oracle": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"virtual_circuit_id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+	// The remaining attributes are the same as those of the vxcs of
+	// megaport_mcr
+	for k, v := range dataSourceMegaportAssociatedVxcElem().Schema {
+		if k != "id" {
+			s[k] = v
+		}
+	}
+	return &schema.Resource{
+		ReadContext: dataSourceMegaportVxcRead,
+
+		Schema: s,
+	}
+}
+
+// dataSourceMegaportAssociatedVxcElem is a VXC connected to a product, as
+// returned along with the product by the API.
+func dataSourceMegaportAssociatedVxcElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rate_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"provisioning_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"a_end": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportVxcEndElem(),
+			},
+			"b_end": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     dataSourceMegaportVxcEndElem(),
+			},
+		},
+	}
+}
+
+// dataSourceMegaportVxcEndElem is an end of a VXC. When the end is an MCR,
+// bgp_status maps the peers of its BGP connections to their status.
+func dataSourceMegaportVxcEndElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"product_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"product_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"owner_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"location_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"vlan": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"bgp_status": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+		},
+	}
+}
+
+func flattenAssociatedVxc(v *api.ProductAssociatedVxc) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  v.ProductUid,
+		"name":                v.ProductName,
+		"type":                v.Type(),
+		"rate_limit":          int(v.RateLimit),
+		"provisioning_status": v.ProvisioningStatus,
+		"a_end":               flattenAssociatedVxcEnd(v.AEnd, v.Resources.GetVRouterCspConnection("a_csp_connection")),
+		"b_end":               flattenAssociatedVxcEnd(v.BEnd, v.Resources.GetVRouterCspConnection("b_csp_connection")),
+	}
+}
+
+func flattenAssociatedVxcs(vxcs []api.ProductAssociatedVxc) []interface{} {
+	ret := make([]interface{}, len(vxcs))
+	for i := range vxcs {
+		ret[i] = flattenAssociatedVxc(&vxcs[i])
+	}
+	return ret
+}
+
+func flattenAssociatedVxcEnd(e api.ProductAssociatedVxcEnd, cc *api.ProductAssociatedVxcResourcesCspConnectionVRouter) []interface{} {
+	status := map[string]interface{}{}
+	if cc != nil {
+		for k, v := range cc.BGPStatus {
+			status[k] = int(v)
+		}
+	}
+	return []interface{}{map[string]interface{}{
+		"product_uid":  e.ProductUid,
+		"product_name": e.ProductName,
+		"owner_uid":    e.OwnerUid,
+		"location_id":  int(e.LocationId),
+		"vlan":         int(e.Vlan),
+		"bgp_status":   status,
+	}}
+}
+
+// flattenVxcCspConnections returns the details of the connection of the VXC
+// to a cloud provider, keyed by the attribute they are exported as.
+func flattenVxcCspConnections(v *api.ProductAssociatedVxc) map[string][]interface{} {
+	ret := map[string][]interface{}{
+		"aws":    []interface{}{},
+		"azure":  []interface{}{},
+		"gcp":    []interface{}{},
+		"oracle": []interface{}{},
+	}
+	if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAws).(*api.ProductAssociatedVxcResourcesCspConnectionAws); ok {
+		ret["aws"] = []interface{}{map[string]interface{}{
+			"vif_id":              cc.VifId,
+			"aws_connection_name": cc.Name,
+			"aws_account_id":      cc.OwnerAccount,
+			"aws_ip_address":      cc.AmazonIpAddress,
+			"customer_asn":        int(cc.Asn),
+			"customer_ip_address": cc.CustomerIpAddress,
+			"type":                strings.ToLower(cc.Type),
+		}}
+	}
+	if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAzure).(*api.ProductAssociatedVxcResourcesCspConnectionAzure); ok {
+		ret["azure"] = []interface{}{map[string]interface{}{
+			"service_key": cc.ServiceKey,
+		}}
+	}
+	if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeGoogle).(*api.ProductAssociatedVxcResourcesCspConnectionGcp); ok {
+		ret["gcp"] = []interface{}{map[string]interface{}{
+			"pairing_key": cc.PairingKey,
+		}}
+	}
+	if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeOracle).(*api.ProductAssociatedVxcResourcesCspConnectionOracle); ok {
+		ret["oracle"] = []interface{}{map[string]interface{}{
+			"virtual_circuit_id": cc.VirtualCircuitId,
+		}}
+	}
+	return ret
+}
+
+// dataSourceMegaportVxcFind returns the VXC with the given uid, or the single
+// VXC of the company's products whose name matches nameRegex. VXCs between two
+// of the company's products are listed by both, and are only counted once.
+func dataSourceMegaportVxcFind(ctx context.Context, cfg *Config, uid, nameRegex string) (*api.ProductAssociatedVxc, error) {
+	if uid != "" {
+		v, err := cfg.Client.GetVxc(ctx, uid)
+		if api.IsNotFound(err) {
+			return nil, fmt.Errorf("No VXCs were found.")
+		}
+		return v, err
+	}
+	products, err := dataSourceUpdatePorts(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	nr := regexp.MustCompile(nameRegex)
+	seen := map[string]bool{}
+	var filtered []*api.ProductAssociatedVxc
+	for _, p := range products {
+		for i := range p.AssociatedVxcs {
+			v := &p.AssociatedVxcs[i]
+			if seen[v.ProductUid] || isResourceDeleted(v.ProvisioningStatus) || !nr.MatchString(v.ProductName) {
+				continue
+			}
+			seen[v.ProductUid] = true
+			filtered = append(filtered, v)
+		}
+	}
+	if len(filtered) < 1 {
+		return nil, fmt.Errorf("No VXCs were found.")
+	}
+	if len(filtered) > 1 {
+		return nil, fmt.Errorf("Multiple VXCs were found. Please use a more specific query.")
+	}
+	return filtered[0], nil
+}
+
+func dataSourceMegaportVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	v, err := dataSourceMegaportVxcFind(ctx, cfg, d.Get("product_uid").(string), d.Get("name_regex").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(v.ProductUid)
+	for k, val := range flattenAssociatedVxc(v) {
+		if k == "id" {
+			continue
+		}
+		if err := d.Set(k, val); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	for k, val := range flattenVxcCspConnections(v) {
+		if err := d.Set(k, val); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("invoice_reference", v.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package megaport
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func TestAccDataSourceMegaportVxc_basic(t *testing.T) {
+	testAccCassette(t)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	missingUid, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValues := map[string]interface{}{
+		"uid":       rName,
+		"location":  "Telehouse North$",
+		"nameRegex": "^terraform_acctest_bar_" + rName + "$",
+	}
+	cfg, err := newTestAccConfig("megaport_vxc", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgMissingUid, err := newTestAccConfig("megaport_vxc", mergeMaps(configValues, map[string]interface{}{"productUid": missingUid}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgMissingName, err := newTestAccConfig("megaport_vxc", mergeMaps(configValues, map[string]interface{}{"nameRegex": "^terraform_acctest_baz_" + rName + "$"}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgAmbiguousName, err := newTestAccConfig("megaport_vxc", mergeMaps(configValues, map[string]interface{}{"nameRegex": "^terraform_acctest_.*_" + rName + "$"}), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.id", "id", "megaport_private_vxc.foo", "id"),
+					resource.TestCheckResourceAttr("data.megaport_vxc.id", "name", "terraform_acctest_foo_"+rName),
+					resource.TestCheckResourceAttr("data.megaport_vxc.id", "type", "private"),
+					resource.TestCheckResourceAttr("data.megaport_vxc.id", "rate_limit", "100"),
+					resource.TestCheckResourceAttr("data.megaport_vxc.id", "invoice_reference", rName),
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.id", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.id", "a_end.0.vlan", "megaport_private_vxc.foo", "a_end.0.vlan"),
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.id", "b_end.0.product_uid", "megaport_port.bar", "id"),
+					resource.TestCheckResourceAttr("data.megaport_vxc.id", "aws.#", "0"),
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.name", "id", "megaport_private_vxc.bar", "id"),
+					resource.TestCheckResourceAttr("data.megaport_vxc.name", "rate_limit", "200"),
+					resource.TestCheckResourceAttrPair("data.megaport_vxc.name", "b_end.0.product_uid", "megaport_port.bar", "id"),
+				),
+			},
+			{
+				PreConfig:   func() { cfgMissingUid.log() },
+				Config:      cfgMissingUid.Config,
+				ExpectError: regexp.MustCompile("No VXCs were found."),
+			},
+			{
+				PreConfig:   func() { cfgMissingName.log() },
+				Config:      cfgMissingName.Config,
+				ExpectError: regexp.MustCompile("No VXCs were found."),
+			},
+			{
+				PreConfig:   func() { cfgAmbiguousName.log() },
+				Config:      cfgAmbiguousName.Config,
+				ExpectError: regexp.MustCompile("Multiple VXCs were found."),
+			},
+		},
+	})
+}
+
+func TestFlattenAssociatedVxc(t *testing.T) {
+	v := &api.ProductAssociatedVxc{}
+	if err := json.Unmarshal([]byte(`{"productUid":"v","productName":"foo","rateLimit":100,"provisioningStatus":"LIVE","aEnd":{"productUid":"a","ownerUid":"x","locationId":1,"vlan":100},"bEnd":{"productUid":"b","productName":"bar","ownerUid":"y"},"resources":{"csp_connection":[{"connectType":"VROUTER","resource_name":"a_csp_connection","bgp_status":{"10.0.0.2":1}},{"connectType":"GOOGLE","pairingKey":"k"}]}}`), v); err != nil {
+		t.Fatalf("TestFlattenAssociatedVxc: %v", err)
+	}
+	expected := map[string]interface{}{
+		"id":                  "v",
+		"name":                "foo",
+		"type":                api.VxcTypeGcp,
+		"rate_limit":          100,
+		"provisioning_status": "LIVE",
+		"a_end": []interface{}{map[string]interface{}{
+			"product_uid":  "a",
+			"product_name": "",
+			"owner_uid":    "x",
+			"location_id":  1,
+			"vlan":         100,
+			"bgp_status":   map[string]interface{}{"10.0.0.2": 1},
+		}},
+		"b_end": []interface{}{map[string]interface{}{
+			"product_uid":  "b",
+			"product_name": "bar",
+			"owner_uid":    "y",
+			"location_id":  0,
+			"vlan":         0,
+			"bgp_status":   map[string]interface{}{},
+		}},
+	}
+	if diff := cmp.Diff(expected, flattenAssociatedVxc(v)); diff != "" {
+		t.Errorf("TestFlattenAssociatedVxc: unexpected VXC:\n%s", diff)
+	}
+	expectedCspConnections := map[string][]interface{}{
+		"aws":    []interface{}{},
+		"azure":  []interface{}{},
+		"gcp":    []interface{}{map[string]interface{}{"pairing_key": "k"}},
+		"oracle": []interface{}{},
+	}
+	if diff := cmp.Diff(expectedCspConnections, flattenVxcCspConnections(v)); diff != "" {
+		t.Errorf("TestFlattenAssociatedVxc: unexpected CSP connections:\n%s", diff)
+	}
+}
@@ -1,21 +1,29 @@
 package megaport
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/time/rate"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
-	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/mutexkv"
-)
-
-var (
-	megaportMutexKV = mutexkv.NewMutexKV()
 )
 
 type Config struct {
 	Client *api.Client
+
+	cache *dataSourceCache
+}
+
+// invalidateProducts drops the cached lists that a product created, updated or
+// deleted by a resource would appear in.
+func (c *Config) invalidateProducts() {
+	c.cache.invalidate(cacheKeyProducts, cacheKeyPartnerPorts)
 }
 
 func Provider() *schema.Provider {
@@ -27,6 +35,60 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
 					"MEGAPORT_TOKEN",
 				}, nil),
+				ConflictsWith: []string{"username", "client_id"},
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_USERNAME",
+				}, nil),
+				RequiredWith:  []string{"password"},
+				ConflictsWith: []string{"token", "client_id"},
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_PASSWORD",
+				}, nil),
+				RequiredWith: []string{"username"},
+			},
+			"totp_secret": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_TOTP_SECRET",
+				}, nil),
+				RequiredWith: []string{"username"},
+			},
+			"client_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_CLIENT_ID",
+				}, nil),
+				RequiredWith:  []string{"client_secret"},
+				ConflictsWith: []string{"token", "username"},
+			},
+			"client_secret": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_CLIENT_SECRET",
+				}, nil),
+				RequiredWith: []string{"client_id"},
+			},
+			"token_endpoint": {
+				Type:     schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"MEGAPORT_TOKEN_ENDPOINT",
+				}, nil),
+				ValidateFunc: validation.IsURLWithHTTPS,
 			},
 			"api_endpoint": {
 				Type:     schema.TypeString,
@@ -36,31 +98,125 @@ func Provider() *schema.Provider {
 				}, api.EndpointProduction),
 				ValidateFunc: validation.IsURLWithHTTPS,
 			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      api.DefaultMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(api.DefaultRetryMaxWait / time.Second),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"request_burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"cache_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      300,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"megaport_port":        resourceMegaportPort(),
-			"megaport_mcr":         resourceMegaportMcr(),
-			"megaport_aws_vxc":     resourceMegaportAwsVxc(),
-			"megaport_gcp_vxc":     resourceMegaportGcpVxc(),
-			"megaport_private_vxc": resourceMegaportPrivateVxc(),
+			"megaport_port":                   resourceMegaportPort(),
+			"megaport_ix":                     resourceMegaportIx(),
+			"megaport_mcr":                    resourceMegaportMcr(),
+			"megaport_mcr_prefix_filter_list": resourceMegaportMcrPrefixFilterList(),
+			"megaport_mve":                    resourceMegaportMve(),
+			"megaport_aws_vxc":                resourceMegaportAwsVxc(),
+			"megaport_azure_vxc":              resourceMegaportAzureVxc(),
+			"megaport_gcp_vxc":                resourceMegaportGcpVxc(),
+			"megaport_oracle_vxc":             resourceMegaportOracleVxc(),
+			"megaport_private_vxc":            resourceMegaportPrivateVxc(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"megaport_location":     dataSourceMegaportLocation(),
-			"megaport_partner_port": dataSourceMegaportPartnerPort(),
-			"megaport_port":         dataSourceMegaportPort(),
+			"megaport_internet_exchange": dataSourceMegaportInternetExchange(),
+			"megaport_location":          dataSourceMegaportLocation(),
+			"megaport_locations":         dataSourceMegaportLocations(),
+			"megaport_mcr":               dataSourceMegaportMcr(),
+			"megaport_partner_port":      dataSourceMegaportPartnerPort(),
+			"megaport_partner_ports":     dataSourceMegaportPartnerPorts(),
+			"megaport_port":              dataSourceMegaportPort(),
+			"megaport_ports":             dataSourceMegaportPorts(),
+			"megaport_vxc":               dataSourceMegaportVxc(),
 		},
 
-		ConfigureFunc: func(d *schema.ResourceData) (interface{}, error) {
-			client := api.NewClient(d.Get("api_endpoint").(string))
-			log.Printf("[INFO] Initialised megaport api client at %s", client.BaseURL)
-			if v, ok := d.GetOk("token"); ok { // TODO: is it an error if not found?
-				client.Token = v.(string)
-			}
-			return &Config{
-				Client: client,
-			}, nil
-		},
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	client := api.NewClient(d.Get("api_endpoint").(string))
+	log.Printf("[INFO] Initialised megaport api client at %s", client.BaseURL)
+	client.MaxRetries = d.Get("max_retries").(int)
+	client.RetryMaxWait = time.Duration(d.Get("retry_max_wait").(int)) * time.Second
+	if v := d.Get("requests_per_second").(float64); v > 0 {
+		client.RateLimiter = rate.NewLimiter(rate.Limit(v), d.Get("request_burst").(int))
+	}
+	// Credentials sourced from the environment are not covered by
+	// ConflictsWith, which only applies to the configuration
+	n := 0
+	for _, k := range []string{"token", "username", "client_id"} {
+		if _, ok := d.GetOk(k); ok {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, diag.FromErr(fmt.Errorf("only one of token, username and client_id can be set, including through their environment variables"))
+	}
+	if v, ok := d.GetOk("client_id"); ok {
+		auth := &api.ClientCredentialsAuthenticator{
+			TokenURL:     tokenEndpoint(d),
+			ClientID:     v.(string),
+			ClientSecret: d.Get("client_secret").(string),
+		}
+		log.Printf("[INFO] Authenticating to the Megaport API with client id %s at %s", auth.ClientID, auth.TokenURL)
+		if _, err := auth.Token(ctx, client); err != nil {
+			return nil, diag.FromErr(fmt.Errorf("could not obtain an access token: %w", err))
+		}
+		client.Authenticator = auth
+	} else if v, ok := d.GetOk("username"); ok {
+		auth := &api.LoginAuthenticator{
+			Username:   v.(string),
+			Password:   d.Get("password").(string),
+			TOTPSecret: d.Get("totp_secret").(string),
+		}
+		log.Printf("[INFO] Logging in to the Megaport API as %s", auth.Username)
+		if _, err := auth.Token(ctx, client); err != nil {
+			return nil, diag.FromErr(fmt.Errorf("could not log in to the Megaport API: %w", err))
+		}
+		client.Authenticator = auth
+	} else if v, ok := d.GetOk("token"); ok { // TODO: is it an error if not found?
+		client.Authenticator = &api.StaticTokenAuthenticator{Token: v.(string)}
+	}
+	return &Config{
+		Client: client,
+		cache:  newDataSourceCache(time.Duration(d.Get("cache_ttl").(int)) * time.Second),
+	}, nil
+}
+
+// tokenEndpoint returns the configured OAuth2 token endpoint, or the one that
+// matches the configured API endpoint.
+func tokenEndpoint(d *schema.ResourceData) string {
+	if v, ok := d.GetOk("token_endpoint"); ok {
+		return v.(string)
+	}
+	if d.Get("api_endpoint").(string) == api.EndpointStaging {
+		return api.TokenEndpointStaging
 	}
+	return api.TokenEndpointProduction
 }
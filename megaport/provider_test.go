@@ -2,22 +2,56 @@ package megaport
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api/fake"
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api/recorder"
 )
 
 var (
 	testAccProviders map[string]*schema.Provider
 	testAccProvider  *schema.Provider
+
+	// testAccFakeServer is shared by all acceptance tests when they run
+	// against the fake API
+	testAccFakeServer     *fake.Server
+	testAccFakeServerOnce sync.Once
+
+	// testAccRecorder records or replays the traffic of the running
+	// acceptance test, when MEGAPORT_CASSETTE_MODE is set
+	testAccRecorder *recorder.Recorder
 )
 
 func init() {
 	testAccProvider = Provider()
+	configure := testAccProvider.ConfigureContextFunc
+	testAccProvider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		m, diags := configure(ctx, d)
+		if testAccFakeServer != nil && m != nil {
+			// trust the certificate of the fake API
+			m.(*Config).Client.HTTPClient = testAccFakeServer.Client()
+		}
+		if testAccRecorder != nil && m != nil {
+			testAccRecorder.Transport = m.(*Config).Client.HTTPClient.Transport
+			m.(*Config).Client.HTTPClient = &http.Client{Transport: testAccRecorder}
+		}
+		return m, diags
+	}
 	testAccProviders = map[string]*schema.Provider{
 		"megaport": testAccProvider,
 	}
@@ -42,22 +76,257 @@ func TestProvider(t *testing.T) {
 	if cfg.Client == nil {
 		t.Fatalf("Config does not include a valid Client")
 	}
-	if cfg.Client.BaseURL != api.EndpointStaging {
+	if cfg.Client.BaseURL != os.Getenv("MEGAPORT_API_ENDPOINT") {
 		t.Fatalf("Unexpected Provider endpoint: %s", cfg.Client.BaseURL)
 	}
-	if cfg.Client.Token != os.Getenv("MEGAPORT_TOKEN") {
+	if auth, ok := cfg.Client.Authenticator.(*api.StaticTokenAuthenticator); ok && auth.Token != os.Getenv("MEGAPORT_TOKEN") {
 		t.Fatalf("Provider token does not match the environment variable MEGAPORT_TOKEN")
 	}
 }
 
 func testAccPreCheck(t *testing.T) {
-	if os.Getenv("MEGAPORT_TOKEN") == "" {
-		t.Fatal("MEGAPORT_TOKEN must be set for acceptance tests")
+	endpoint := api.EndpointStaging
+	if os.Getenv("MEGAPORT_CASSETTE_MODE") == string(recorder.ModeReplay) {
+		testAccPreCheckReplay(t)
+	} else if os.Getenv("MEGAPORT_FAKE_API") != "" {
+		endpoint = testAccPreCheckFake(t)
+	}
+	if os.Getenv("MEGAPORT_TOKEN") == "" && os.Getenv("MEGAPORT_USERNAME") == "" && os.Getenv("MEGAPORT_CLIENT_ID") == "" {
+		t.Fatal("MEGAPORT_TOKEN, MEGAPORT_USERNAME or MEGAPORT_CLIENT_ID must be set for acceptance tests")
 	}
-	if err := os.Setenv("MEGAPORT_API_ENDPOINT", api.EndpointStaging); err != nil {
+	if err := os.Setenv("MEGAPORT_API_ENDPOINT", endpoint); err != nil {
 		t.Fatal(err)
 	}
 	if err := testAccProvider.Configure(context.TODO(), terraform.NewResourceConfigRaw(nil)); err != nil {
 		t.Fatal(err)
 	}
 }
+
+// testAccPreCheckFake starts the fake Megaport API, if necessary, and sets up
+// the environment so that the provider uses it instead of staging. Any other
+// credentials are cleared, so that they are never sent anywhere.
+func testAccPreCheckFake(t *testing.T) string {
+	testAccFakeServerOnce.Do(func() {
+		testAccFakeServer = fake.NewServer()
+	})
+	for _, k := range []string{"MEGAPORT_USERNAME", "MEGAPORT_CLIENT_ID"} {
+		if err := os.Unsetenv(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Setenv("MEGAPORT_TOKEN", testAccFakeServer.Token); err != nil {
+		t.Fatal(err)
+	}
+	return testAccFakeServer.URL
+}
+
+// testAccPreCheckReplay sets up the environment so that the provider does not
+// need credentials to replay a cassette.
+func testAccPreCheckReplay(t *testing.T) {
+	for _, k := range []string{"MEGAPORT_USERNAME", "MEGAPORT_CLIENT_ID"} {
+		if err := os.Unsetenv(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Setenv("MEGAPORT_TOKEN", "replay"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAccCassette must be called first thing by acceptance tests. If
+// MEGAPORT_CASSETTE_MODE is "record", the traffic of the test is recorded to
+// testdata/cassettes/<test name>.json and if it is "replay" it is served from
+// there instead of the API. Tests without a cassette are skipped when
+// replaying.
+//
+// So that replayed tests send the same requests as they did when they were
+// recorded, math/rand is seeded from the name of the test.
+func testAccCassette(t *testing.T) {
+	mode := recorder.Mode(os.Getenv("MEGAPORT_CASSETTE_MODE"))
+	if mode == "" || os.Getenv("TF_ACC") == "" {
+		return
+	}
+	if mode == recorder.ModeRecord && os.Getenv("MEGAPORT_FAKE_API") != "" {
+		t.Fatalf("%s: cassettes must be recorded against Megaport, not the fake API", t.Name())
+	}
+	h := fnv.New64a()
+	h.Write([]byte(t.Name())) // nolint: errcheck
+	rand.Seed(int64(h.Sum64()))
+	r, err := recorder.New(filepath.Join("testdata", "cassettes", t.Name()+".json"), mode)
+	if os.IsNotExist(err) {
+		t.Skipf("no cassette to replay for %s", t.Name())
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	testAccRecorder = r
+	t.Cleanup(func() {
+		testAccRecorder = nil
+		if err := r.Stop(); err != nil {
+			t.Errorf("%s: cannot save cassette: %v", t.Name(), err)
+		}
+	})
+}
+
+// testAccRandIntRange returns a random integer between min (inclusive) and max
+// (exclusive). Unlike acctest.RandIntRange, it is deterministic once math/rand
+// has been seeded by testAccCassette.
+func testAccRandIntRange(min, max int) int {
+	return rand.Intn(max-min) + min
+}
+
+// testUnsetCredentialsEnv clears the credentials that acceptance tests may
+// have set in the environment, restoring them once the test is done.
+func testUnsetCredentialsEnv(t *testing.T) {
+	for _, k := range []string{"MEGAPORT_TOKEN", "MEGAPORT_USERNAME", "MEGAPORT_CLIENT_ID"} {
+		k := k
+		v, ok := os.LookupEnv(k)
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(k, v) // nolint: errcheck
+			} else {
+				os.Unsetenv(k) // nolint: errcheck
+			}
+		})
+		if err := os.Unsetenv(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestProvider_timeouts checks that every resource can be given timeouts for
+// the operations it has, since they all wait on Megaport.
+func TestProvider_timeouts(t *testing.T) {
+	for name, r := range Provider().ResourcesMap {
+		if r.Timeouts == nil {
+			t.Errorf("TestProvider_timeouts: %s does not declare timeouts", name)
+			continue
+		}
+		if r.Timeouts.Create == nil {
+			t.Errorf("TestProvider_timeouts: %s does not declare a create timeout", name)
+		}
+		if r.UpdateContext != nil && r.Timeouts.Update == nil {
+			t.Errorf("TestProvider_timeouts: %s does not declare an update timeout", name)
+		}
+		if r.Timeouts.Delete == nil {
+			t.Errorf("TestProvider_timeouts: %s does not declare a delete timeout", name)
+		}
+	}
+}
+
+func TestProviderConfigure_login(t *testing.T) {
+	testUnsetCredentialsEnv(t)
+	token := uuid.New().String()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/login" {
+			t.Errorf("TestProviderConfigure_login: unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("TestProviderConfigure_login: %v", err)
+		}
+		if r.Form.Get("username") != "foo" || r.Form.Get("password") != "bar" {
+			t.Errorf("TestProviderConfigure_login: unexpected credentials: %s", r.Form.Encode())
+		}
+		if otp := r.Form.Get("oneTimePassword"); len(otp) != 6 {
+			t.Errorf("TestProviderConfigure_login: unexpected one-time password: %q", otp)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"data":{"token":"%s"}}`, token)
+	}))
+	defer s.Close()
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"api_endpoint": s.URL,
+		"username":     "foo",
+		"password":     "bar",
+		"totp_secret":  "JBSWY3DPEHPK3PXP",
+	})
+	m, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("TestProviderConfigure_login: %#v", diags)
+	}
+	auth, ok := m.(*Config).Client.Authenticator.(*api.LoginAuthenticator)
+	if !ok {
+		t.Fatalf("TestProviderConfigure_login: unexpected authenticator: %T", m.(*Config).Client.Authenticator)
+	}
+	if v, err := auth.Token(context.Background(), m.(*Config).Client); err != nil || v != token {
+		t.Errorf("TestProviderConfigure_login: unexpected token: got '%s' (%v), expected '%s'", v, err, token)
+	}
+}
+
+func TestProviderConfigure_clientCredentials(t *testing.T) {
+	testUnsetCredentialsEnv(t)
+	token := uuid.New().String()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth2/token" {
+			t.Errorf("TestProviderConfigure_clientCredentials: unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "foo" || secret != "bar" {
+			t.Errorf("TestProviderConfigure_clientCredentials: unexpected credentials: %s:%s", id, secret)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"access_token":"%s","expires_in":3600}`, token)
+	}))
+	defer s.Close()
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"api_endpoint":   s.URL,
+		"token_endpoint": s.URL + "/oauth2/token",
+		"client_id":      "foo",
+		"client_secret":  "bar",
+	})
+	m, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("TestProviderConfigure_clientCredentials: %#v", diags)
+	}
+	auth, ok := m.(*Config).Client.Authenticator.(*api.ClientCredentialsAuthenticator)
+	if !ok {
+		t.Fatalf("TestProviderConfigure_clientCredentials: unexpected authenticator: %T", m.(*Config).Client.Authenticator)
+	}
+	if v, err := auth.Token(context.Background(), m.(*Config).Client); err != nil || v != token {
+		t.Errorf("TestProviderConfigure_clientCredentials: unexpected token: got '%s' (%v), expected '%s'", v, err, token)
+	}
+}
+
+func TestProvider_conflictingCredentials(t *testing.T) {
+	testUnsetCredentialsEnv(t)
+	p := Provider()
+	diags := p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"token":         "foo",
+		"client_id":     "bar",
+		"client_secret": "baz",
+	}))
+	if !diags.HasError() {
+		t.Errorf("TestProvider_conflictingCredentials: expected token and client_id to conflict")
+	}
+	if err := os.Setenv("MEGAPORT_TOKEN", "foo"); err != nil {
+		t.Fatal(err)
+	}
+	d := schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"client_id":     "bar",
+		"client_secret": "baz",
+	})
+	if _, diags := providerConfigure(context.Background(), d); !diags.HasError() {
+		t.Errorf("TestProvider_conflictingCredentials: expected a token from the environment to conflict with client_id")
+	}
+}
+
+func TestProviderConfigure_cache(t *testing.T) {
+	testUnsetCredentialsEnv(t)
+	configure := func() *Config {
+		d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+			"token":     "foo",
+			"cache_ttl": 60,
+		})
+		m, diags := providerConfigure(context.Background(), d)
+		if diags.HasError() {
+			t.Fatalf("TestProviderConfigure_cache: %#v", diags)
+		}
+		return m.(*Config)
+	}
+	a, b := configure(), configure()
+	if a.cache == nil || a.cache.ttl != time.Minute {
+		t.Errorf("TestProviderConfigure_cache: expected a cache with a ttl of a minute, got %#v", a.cache)
+	}
+	if a.cache == b.cache {
+		t.Errorf("TestProviderConfigure_cache: expected every configured provider to have its own cache")
+	}
+}
@@ -26,6 +26,20 @@ func resourceMegaportAwsVxc() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffValidateOrder(resourceMegaportAwsVxc, vxcOrderFieldsWith(map[string]string{
+			"partnerConfigs.asn":          "b_end.0.customer_asn",
+			"partnerConfigs.ownerAccount": "b_end.0.aws_account_id",
+			"partnerConfigs.type":         "b_end.0.type",
+		}), func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+			return c.ValidateCloudVxc(ctx, expandAwsVxcCreateInput(d))
+		}),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -39,7 +53,7 @@ func resourceMegaportAwsVxc() *schema.Resource {
 				Type:     schema.TypeList,
 				Required: true,
 				MaxItems: 1,
-				Elem:     resourceMegaportVxcEndElem(),
+				Elem:     resourceMegaportVxcAEndElem(),
 			},
 			"b_end": {
 				Type:     schema.TypeList,
@@ -168,13 +182,17 @@ func expandVxcEndAws(e map[string]interface{}) *api.PartnerConfigAws {
 
 func resourceMegaportAwsVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	p, err := cfg.Client.GetVxc(d.Id())
+	p, err := cfg.Client.GetVxc(ctx, d.Id())
 	if err != nil {
-		log.Printf("[ERROR] Could not get VXC information: %v", err)
-		d.SetId("")
-		return nil
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] VXC (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
-	if p.ProvisioningStatus == api.ProductStatusDecommissioned {
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] VXC (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
 		d.SetId("")
 		return nil
 	}
@@ -184,7 +202,7 @@ func resourceMegaportAwsVxcRead(ctx context.Context, d *schema.ResourceData, m i
 	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("a_end", flattenVxcEnd(p.AEnd)); err != nil {
+	if err := d.Set("a_end", flattenVxcAEnd(d.Get("a_end").([]interface{}), p)); err != nil {
 		return diag.FromErr(err)
 	}
 	puid := ""
@@ -202,26 +220,14 @@ func resourceMegaportAwsVxcRead(ctx context.Context, d *schema.ResourceData, m i
 
 func resourceMegaportAwsVxcCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	defer cfg.invalidateProducts()
 	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
-	input := &api.CloudVxcCreateInput{
-		ProductUidA:   api.String(a["product_uid"]),
-		ProductUidB:   api.String(b["product_uid"]),
-		Name:          api.String(d.Get("name")),
-		PartnerConfig: expandVxcEndAws(b),
-		RateLimit:     api.Uint64FromInt(d.Get("rate_limit")),
-	}
 	if v := b["aws_prefixes"].(*schema.Set).List(); len(v) > 0 && b["type"].(string) != "public" {
 		return diag.FromErr(fmt.Errorf("cannot specify 'aws_prefixes' for a private VXC"))
 	}
-	if v, ok := d.GetOk("invoice_reference"); ok {
-		input.InvoiceReference = api.String(v)
-	}
-	if v := a["vlan"].(int); v != 0 {
-		input.VlanA = api.Uint64FromInt(v)
-	}
-	if *input.VlanA > 0 {
-		ok, err := cfg.Client.GetPortVlanIdAvailable(*input.ProductUidA, *input.VlanA)
+	input := expandAwsVxcCreateInput(d)
+	if input.VlanA != nil {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, *input.ProductUidA, *input.VlanA)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -229,26 +235,51 @@ func resourceMegaportAwsVxcCreate(ctx context.Context, d *schema.ResourceData, m
 			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, *input.ProductUidA))
 		}
 	}
-	uid, err := cfg.Client.CreateCloudVxc(input)
+	uid, err := cfg.Client.CreateCloudVxc(ctx, input)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.SetId(*uid)
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportAwsVxcRead(ctx, d, m)
 }
 
+func expandAwsVxcCreateInput(d resourceGetter) *api.CloudVxcCreateInput {
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:    api.String(a["product_uid"]),
+		ProductUidB:    api.String(b["product_uid"]),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  expandVxcEndAws(b),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, false),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if v := a["vnic_index"].(int); v != 0 {
+		input.VnicIndexA = api.Uint64FromInt(v)
+	}
+	return input
+}
+
 func resourceMegaportAwsVxcUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
 	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
 	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
 	input := &api.CloudVxcUpdateInput{
-		Name:          api.String(d.Get("name")),
-		PartnerConfig: expandVxcEndAws(b),
-		ProductUid:    api.String(d.Id()),
-		RateLimit:     api.Uint64FromInt(d.Get("rate_limit")),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  expandVxcEndAws(b),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, d.HasChange("a_end.0.mcr_config")),
+		ProductUid:     api.String(d.Id()),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
 	}
 	if v, ok := d.GetOk("invoice_reference"); ok {
 		input.InvoiceReference = api.String(v)
@@ -256,8 +287,8 @@ func resourceMegaportAwsVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 	if v := a["vlan"].(int); v != 0 {
 		input.VlanA = api.Uint64FromInt(v)
 	}
-	if *input.VlanA > 0 {
-		ok, err := cfg.Client.GetPortVlanIdAvailable(a["product_uid"].(string), *input.VlanA)
+	if input.VlanA != nil && d.HasChange("a_end.0.vlan") {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, a["product_uid"].(string), *input.VlanA)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -265,13 +296,13 @@ func resourceMegaportAwsVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, a["product_uid"].(string)))
 		}
 	}
-	if err := cfg.Client.UpdateCloudVxc(input); err != nil {
+	if err := cfg.Client.UpdateCloudVxc(ctx, input); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilAwsVxcIsUpdated(ctx, cfg.Client, input, 5*time.Minute); err != nil {
+	if err := waitUntilAwsVxcIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportAwsVxcRead(ctx, d, m)
@@ -279,15 +310,16 @@ func resourceMegaportAwsVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 
 func resourceMegaportAwsVxcDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	err := cfg.Client.DeleteVxc(d.Id())
-	if err != nil && err != api.ErrNotFound {
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteVxc(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
-	if err == api.ErrNotFound {
+	if api.IsNotFound(err) {
 		log.Printf("[DEBUG] VXC (%s) not found, deleting from state anyway", d.Id())
 		return nil
 	}
-	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
@@ -297,7 +329,7 @@ func waitUntilAwsVxcIsUpdated(ctx context.Context, client *api.Client, input *ap
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetVxc(*input.ProductUid)
+			v, err := client.GetVxc(ctx, *input.ProductUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve VXC while waiting for update to finish: %v", err)
 				return nil, "", err
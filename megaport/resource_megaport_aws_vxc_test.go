@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
@@ -25,12 +24,12 @@ func init() {
 }
 
 func TestAccMegaportAwsVxc_basic(t *testing.T) {
+	testAccCassette(t)
 	var (
 		vxc, vxcUpdated, vxcNew api.ProductAssociatedVxc
 		port                    api.Product
 	)
 	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
-	rand.Seed(time.Now().UnixNano())
 	n := &net.IPNet{
 		IP:   net.IPv4(169, 254, byte(rand.Intn(255)), byte(rand.Intn(63)*4+1)),
 		Mask: net.CIDRMask(30, 32),
@@ -42,7 +41,7 @@ func TestAccMegaportAwsVxc_basic(t *testing.T) {
 		"uid":                 rName,
 		"location":            "Equinix LD5",
 		"aws_account_id":      acctest.RandStringFromCharSet(12, "012346789"),
-		"customer_asn":        acctest.RandIntRange(1, 65536),
+		"customer_asn":        testAccRandIntRange(1, 65536),
 		"aws_ip_address":      ipA,
 		"customer_ip_address": ipB,
 		"type":                "private",
@@ -53,7 +52,7 @@ func TestAccMegaportAwsVxc_basic(t *testing.T) {
 	}
 	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
 		"aws_account_id": acctest.RandStringFromCharSet(12, "012346789"),
-		"customer_asn":   acctest.RandIntRange(1, 65536),
+		"customer_asn":   testAccRandIntRange(1, 65536),
 	})
 	cfgUpdate, err := newTestAccConfig("megaport_aws_vxc_full", configValuesUpdate, 1)
 	if err != nil {
@@ -169,12 +168,12 @@ func TestAccMegaportAwsVxc_basic(t *testing.T) {
 }
 
 func TestAccMegaportAwsVxc_basicPublic(t *testing.T) {
+	testAccCassette(t)
 	var (
 		vxc  api.ProductAssociatedVxc
 		port api.Product
 	)
 	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
-	rand.Seed(time.Now().UnixNano())
 	n := &net.IPNet{
 		IP:   net.IPv4(88, byte(rand.Intn(255)), byte(rand.Intn(255)), byte(rand.Intn(63)*4+1)),
 		Mask: net.CIDRMask(30, 32),
@@ -189,7 +188,7 @@ func TestAccMegaportAwsVxc_basicPublic(t *testing.T) {
 		"uid":                 rName,
 		"location":            "Equinix LD5",
 		"aws_account_id":      acctest.RandStringFromCharSet(12, "012346789"),
-		"customer_asn":        acctest.RandIntRange(1, 65536),
+		"customer_asn":        testAccRandIntRange(1, 65536),
 		"aws_ip_address":      ipA,
 		"customer_ip_address": ipB,
 		"prefixes":            []string{prefix},
@@ -256,3 +255,15 @@ func TestAccMegaportAwsVxc_basicPublic(t *testing.T) {
 		},
 	})
 }
+
+func TestResourceMegaportAwsVxcRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportAwsVxc(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"VXC","provisioningStatus":"LIVE","rateLimit":100,"aEnd":{"ownerUid":"a","productUid":"b","vlan":100},"bEnd":{"ownerUid":"d","productUid":"c"},"resources":{"csp_connection":{"connectType":"AWS","ownerAccount":"123456789012","asn":64512,"type":"private"}}}}`, map[string]string{
+		"name":                          "foo",
+		"rate_limit":                    "100",
+		"a_end.0.vlan":                  "100",
+		"b_end.0.connected_product_uid": "c",
+		"b_end.0.aws_account_id":        "123456789012",
+		"b_end.0.customer_asn":          "64512",
+		"b_end.0.type":                  "private",
+	})
+}
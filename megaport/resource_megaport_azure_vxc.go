@@ -0,0 +1,417 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+const (
+	azurePeeringPrivate   = "private"
+	azurePeeringMicrosoft = "microsoft"
+)
+
+func resourceMegaportAzureVxc() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMegaportAzureVxcCreate,
+		ReadContext:   resourceMegaportAzureVxcRead,
+		UpdateContext: resourceMegaportAzureVxcUpdate,
+		DeleteContext: resourceMegaportAzureVxcDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffValidateOrder(resourceMegaportAzureVxc, vxcOrderFieldsWith(map[string]string{
+			"partnerConfigs.serviceKey": "b_end.0.service_key",
+		}), func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+			return c.ValidateCloudVxc(ctx, expandAzureVxcCreateInput(d))
+		}),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"rate_limit": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"a_end": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcAEndElem(),
+			},
+			"b_end": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcAzureEndElem(),
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportVxcAzureEndElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"product_uid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old == "" && d.Get("b_end.0.connected_product_uid").(string) != ""
+				},
+			},
+			"connected_product_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"service_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			"private_peering": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcAzurePeeringElem(false),
+			},
+			"microsoft_peering": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcAzurePeeringElem(true),
+			},
+		},
+	}
+}
+
+func resourceMegaportVxcAzurePeeringElem(microsoft bool) *schema.Resource {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"peer_asn": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"primary_subnet": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"secondary_subnet": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"shared_key": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"vlan": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(2, 4093),
+			},
+		},
+	}
+	if microsoft {
+		r.Schema["prefixes"] = &schema.Schema{
+			Type:     schema.TypeSet,
+			Required: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.IsCIDR,
+			},
+		}
+	}
+	return r
+}
+
+// flattenVxcEndAzure returns the B End of an Azure VXC. Megaport only reports
+// the peerings of the circuits it manages, and never the shared keys, so these
+// are otherwise taken from the configuration, as is the service key while the
+// VXC has no Azure connection, e.g. while it is being provisioned.
+func flattenVxcEndAzure(config map[string]interface{}, v *api.ProductAssociatedVxc) []interface{} {
+	b := map[string]interface{}{
+		"product_uid":           config["product_uid"],
+		"connected_product_uid": v.BEnd.ProductUid,
+		"service_key":           config["service_key"],
+		"private_peering":       config["private_peering"],
+		"microsoft_peering":     config["microsoft_peering"],
+	}
+	cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAzure).(*api.ProductAssociatedVxcResourcesCspConnectionAzure)
+	if !ok {
+		return []interface{}{b}
+	}
+	b["service_key"] = cc.ServiceKey
+	if len(cc.Peers) == 0 {
+		return []interface{}{b}
+	}
+	b["private_peering"] = []interface{}{}
+	b["microsoft_peering"] = []interface{}{}
+	for _, p := range cc.Peers {
+		k := strings.ToLower(p.Type) + "_peering"
+		if _, ok := b[k]; !ok {
+			continue
+		}
+		asn, _ := strconv.Atoi(p.PeerAsn)
+		peering := map[string]interface{}{
+			"peer_asn":         asn,
+			"primary_subnet":   p.PrimarySubnet,
+			"secondary_subnet": p.SecondarySubnet,
+			"shared_key":       p.SharedKey,
+			"vlan":             int(p.Vlan),
+		}
+		if p.SharedKey == "" {
+			if c, ok := config[k].([]interface{}); ok && len(c) > 0 && c[0] != nil {
+				peering["shared_key"] = c[0].(map[string]interface{})["shared_key"]
+			}
+		}
+		if strings.EqualFold(p.Type, azurePeeringMicrosoft) {
+			var prefixes []string
+			if p.Prefixes != "" {
+				prefixes = strings.Split(p.Prefixes, ",")
+			}
+			peering["prefixes"] = prefixes
+		}
+		b[k] = []interface{}{peering}
+	}
+	return []interface{}{b}
+}
+
+func expandVxcEndAzure(e map[string]interface{}) *api.PartnerConfigAzure {
+	pc := &api.PartnerConfigAzure{
+		ServiceKey: api.String(e["service_key"]),
+	}
+	for _, t := range []string{azurePeeringPrivate, azurePeeringMicrosoft} {
+		v := e[t+"_peering"].([]interface{})
+		if len(v) == 0 || v[0] == nil {
+			continue
+		}
+		p := v[0].(map[string]interface{})
+		peer := &api.PartnerConfigAzurePeer{
+			PeerASN:         api.Uint64FromInt(p["peer_asn"]),
+			PrimarySubnet:   api.String(p["primary_subnet"]),
+			SecondarySubnet: api.String(p["secondary_subnet"]),
+			Type:            api.String(t),
+			Vlan:            api.Uint64FromInt(p["vlan"]),
+		}
+		if v := p["shared_key"]; v != "" {
+			peer.SharedKey = api.String(v)
+		}
+		if v, ok := p["prefixes"]; ok {
+			for _, vv := range v.(*schema.Set).List() {
+				peer.Prefixes = append(peer.Prefixes, vv.(string))
+			}
+		}
+		pc.Peers = append(pc.Peers, peer)
+	}
+	return pc
+}
+
+func resourceMegaportAzureVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	p, err := cfg.Client.GetVxc(ctx, d.Id())
+	if err != nil {
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] VXC (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] VXC (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
+		d.SetId("")
+		return nil
+	}
+	if err := d.Set("name", p.ProductName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("a_end", flattenVxcAEnd(d.Get("a_end").([]interface{}), p)); err != nil {
+		return diag.FromErr(err)
+	}
+	b := map[string]interface{}{"product_uid": ""}
+	if v := d.Get("b_end").([]interface{}); len(v) > 0 {
+		b = v[0].(map[string]interface{})
+	}
+	if err := d.Set("b_end", flattenVxcEndAzure(b, p)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceMegaportAzureVxcCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := expandAzureVxcCreateInput(d)
+	if input.VlanA != nil {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, *input.ProductUidA, *input.VlanA)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, *input.ProductUidA))
+		}
+	}
+	uid, err := cfg.Client.CreateCloudVxc(ctx, input)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*uid)
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportAzureVxcRead(ctx, d, m)
+}
+
+func expandAzureVxcCreateInput(d resourceGetter) *api.CloudVxcCreateInput {
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:    api.String(a["product_uid"]),
+		ProductUidB:    api.String(b["product_uid"]),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  expandVxcEndAzure(b),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, false),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if v := a["vnic_index"].(int); v != 0 {
+		input.VnicIndexA = api.Uint64FromInt(v)
+	}
+	return input
+}
+
+func resourceMegaportAzureVxcUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcUpdateInput{
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  expandVxcEndAzure(b),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, d.HasChange("a_end.0.mcr_config")),
+		ProductUid:     api.String(d.Id()),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if input.VlanA != nil && d.HasChange("a_end.0.vlan") {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, a["product_uid"].(string), *input.VlanA)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, a["product_uid"].(string)))
+		}
+	}
+	if err := cfg.Client.UpdateCloudVxc(ctx, input); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilAzureVxcIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportAzureVxcRead(ctx, d, m)
+}
+
+func resourceMegaportAzureVxcDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteVxc(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if api.IsNotFound(err) {
+		log.Printf("[DEBUG] VXC (%s) not found, deleting from state anyway", d.Id())
+		return nil
+	}
+	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func waitUntilAzureVxcIsUpdated(ctx context.Context, client *api.Client, input *api.CloudVxcUpdateInput, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
+		Refresh: func() (interface{}, string, error) {
+			v, err := client.GetVxc(ctx, *input.ProductUid)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve VXC while waiting for update to finish: %v", err)
+				return nil, "", err
+			}
+			if v == nil {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.InvoiceReference, v.CostCentre) {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.Name, v.ProductName) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.RateLimit, v.RateLimit) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.VlanA, v.AEnd.Vlan) {
+				return nil, "", nil
+			}
+			pc := input.PartnerConfig.(*api.PartnerConfigAzure)
+			cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeAzure).(*api.ProductAssociatedVxcResourcesCspConnectionAzure)
+			if !ok || !compareNillableStrings(pc.ServiceKey, cc.ServiceKey) {
+				return nil, "", nil
+			}
+			return v, v.ProvisioningStatus, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for VXC (%s) to be updated", *input.ProductUid)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
@@ -0,0 +1,230 @@
+package megaport
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func init() {
+	resource.AddTestSweepers("megaport_azure_vxc", &resource.Sweeper{
+		Name: "megaport_azure_vxc",
+		F:    testAccVxcSweeper(api.VxcTypeAzure),
+	})
+}
+
+func TestAccMegaportAzureVxc_basic(t *testing.T) {
+	testAccCassette(t)
+	var (
+		vxc, vxcUpdated, vxcNew api.ProductAssociatedVxc
+		port                    api.Product
+	)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	rsk, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValues := map[string]interface{}{
+		"uid":        rName,
+		"nameRegex":  "London",
+		"portType":   "primary",
+		"serviceKey": rsk,
+		"rateLimit":  "data.megaport_partner_port.azure.bandwidths[0]",
+		"vlan":       456,
+	}
+	cfg, err := newTestAccConfig("megaport_azure_vxc_basic", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerAsn := testAccRandIntRange(64512, 65535)
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"rateLimit":       500,
+		"vlan":            567,
+		"peerAsn":         peerAsn,
+		"primarySubnet":   "10.0.0.0/30",
+		"secondarySubnet": "10.0.0.4/30",
+		"sharedKey":       acctest.RandString(16),
+		"peeringVlan":     100,
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_azure_vxc_full", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesForceNew := mergeMaps(configValuesUpdate, map[string]interface{}{
+		"portType": "secondary",
+	})
+	cfgForceNew, err := newTestAccConfig("megaport_azure_vxc_full", configValuesForceNew, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_azure_vxc.foo", &vxc),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "rate_limit", "data.megaport_partner_port.azure", "bandwidths.0"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "invoice_reference", ""),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "a_end.0.vlan", "456"),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.azure", "id"),
+					resource.TestCheckResourceAttrSet("megaport_azure_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.service_key", rsk),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.#", "0"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.microsoft_peering.#", "0"),
+				),
+			},
+			{
+				ResourceName:            "megaport_azure_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid"},
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_azure_vxc.foo", &vxcUpdated),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "rate_limit", "500"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "invoice_reference", rName),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "a_end.0.vlan", "567"),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.azure", "id"),
+					resource.TestCheckResourceAttrSet("megaport_azure_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.service_key", rsk),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.#", "1"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.0.peer_asn", strconv.Itoa(peerAsn)),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.0.primary_subnet", "10.0.0.0/30"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.0.secondary_subnet", "10.0.0.4/30"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.0.shared_key", configValuesUpdate["sharedKey"].(string)),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.0.vlan", "100"),
+				),
+			},
+			{
+				ResourceName:            "megaport_azure_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid", "b_end.0.private_peering.0.shared_key"},
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_azure_vxc.foo", &vxcNew),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "rate_limit", "500"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "invoice_reference", rName),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "a_end.0.vlan", "567"),
+					resource.TestCheckResourceAttrPair("megaport_azure_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.azure", "id"),
+					resource.TestCheckResourceAttrSet("megaport_azure_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.service_key", rsk),
+					resource.TestCheckResourceAttr("megaport_azure_vxc.foo", "b_end.0.private_peering.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "megaport_azure_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid", "b_end.0.private_peering.0.shared_key"},
+			},
+		},
+	})
+
+	if vxc.ProductUid != vxcUpdated.ProductUid {
+		t.Errorf("TestAccMegaportAzureVxc_basic: expected the VXC to be updated but the resource ids differ")
+	}
+	if vxc.ProductUid == vxcNew.ProductUid {
+		t.Errorf("TestAccMegaportAzureVxc_basic: expected the VXC to be recreated but the resource ids are identical")
+	}
+}
+
+func TestResourceMegaportAzureVxcRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportAzureVxc(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"VXC","provisioningStatus":"LIVE","rateLimit":100,"aEnd":{"ownerUid":"a","productUid":"b","vlan":100},"bEnd":{"ownerUid":"d","productUid":"c"},"resources":{"csp_connection":{"connectType":"AZURE","service_key":"foo","peers":[{"type":"private","peer_asn":"64512","primary_subnet":"10.0.0.0/30","secondary_subnet":"10.0.0.4/30","vlan":200}]}}}}`, map[string]string{
+		"name":                                     "foo",
+		"a_end.0.vlan":                             "100",
+		"b_end.0.connected_product_uid":            "c",
+		"b_end.0.service_key":                      "foo",
+		"b_end.0.private_peering.#":                "1",
+		"b_end.0.private_peering.0.peer_asn":       "64512",
+		"b_end.0.private_peering.0.primary_subnet": "10.0.0.0/30",
+		"b_end.0.private_peering.0.vlan":           "200",
+		"b_end.0.microsoft_peering.#":              "0",
+	})
+}
+
+func TestFlattenVxcEndAzure(t *testing.T) {
+	config := map[string]interface{}{
+		"product_uid": "b",
+		"service_key": "foo",
+		"private_peering": []interface{}{map[string]interface{}{
+			"peer_asn":         64512,
+			"primary_subnet":   "10.0.0.0/30",
+			"secondary_subnet": "10.0.0.4/30",
+			"shared_key":       "bar",
+			"vlan":             200,
+		}},
+		"microsoft_peering": []interface{}{},
+	}
+	testCases := []struct {
+		vxc      string
+		expected map[string]interface{}
+	}{
+		{ // 0: without an Azure connection, e.g. while provisioning, the configuration is kept
+			vxc:      `{"bEnd":{"productUid":"c"}}`,
+			expected: mergeMaps(config, map[string]interface{}{"connected_product_uid": "c"}),
+		},
+		{ // 1: the peerings of circuits that are not managed by Megaport are kept
+			vxc:      `{"bEnd":{"productUid":"c"},"resources":{"csp_connection":{"connectType":"AZURE","service_key":"baz"}}}`,
+			expected: mergeMaps(config, map[string]interface{}{"connected_product_uid": "c", "service_key": "baz"}),
+		},
+		{ // 2: managed peerings are read, with the shared keys of those that are configured
+			vxc: `{"bEnd":{"productUid":"c"},"resources":{"csp_connection":{"connectType":"AZURE","service_key":"foo","peers":[{"type":"Private","peer_asn":"64512","primary_subnet":"10.0.1.0/30","secondary_subnet":"10.0.1.4/30","vlan":200},{"type":"Microsoft","peer_asn":"64513","primary_subnet":"192.0.2.0/30","secondary_subnet":"192.0.2.4/30","prefixes":"192.0.2.128/25","vlan":300}]}}}`,
+			expected: mergeMaps(config, map[string]interface{}{
+				"connected_product_uid": "c",
+				"private_peering": []interface{}{map[string]interface{}{
+					"peer_asn":         64512,
+					"primary_subnet":   "10.0.1.0/30",
+					"secondary_subnet": "10.0.1.4/30",
+					"shared_key":       "bar",
+					"vlan":             200,
+				}},
+				"microsoft_peering": []interface{}{map[string]interface{}{
+					"peer_asn":         64513,
+					"primary_subnet":   "192.0.2.0/30",
+					"secondary_subnet": "192.0.2.4/30",
+					"shared_key":       "",
+					"vlan":             300,
+					"prefixes":         []string{"192.0.2.128/25"},
+				}},
+			}),
+		},
+	}
+	for i, tc := range testCases {
+		v := &api.ProductAssociatedVxc{}
+		if err := json.Unmarshal([]byte(tc.vxc), v); err != nil {
+			t.Fatalf("TestFlattenVxcEndAzure (#%d): %v", i, err)
+		}
+		b := flattenVxcEndAzure(config, v)
+		if diff := cmp.Diff([]interface{}{tc.expected}, b); diff != "" {
+			t.Errorf("TestFlattenVxcEndAzure (#%d): unexpected B-End:\n%s", i, diff)
+		}
+	}
+}
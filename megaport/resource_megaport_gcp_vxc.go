@@ -24,6 +24,18 @@ func resourceMegaportGcpVxc() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffValidateOrder(resourceMegaportGcpVxc, vxcOrderFieldsWith(map[string]string{
+			"partnerConfigs.pairingKey": "b_end.0.pairing_key",
+		}), func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+			return c.ValidateCloudVxc(ctx, expandGcpVxcCreateInput(d))
+		}),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -37,7 +49,7 @@ func resourceMegaportGcpVxc() *schema.Resource {
 				Type:     schema.TypeList,
 				Required: true,
 				MaxItems: 1,
-				Elem:     resourceMegaportVxcEndElem(),
+				Elem:     resourceMegaportVxcAEndElem(),
 			},
 			"b_end": {
 				Type:     schema.TypeList,
@@ -91,13 +103,17 @@ func flattenVxcEndGcp(configProductUid string, v *api.ProductAssociatedVxc) []in
 
 func resourceMegaportGcpVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	p, err := cfg.Client.GetVxc(d.Id())
+	p, err := cfg.Client.GetVxc(ctx, d.Id())
 	if err != nil {
-		log.Printf("[ERROR] Could not get VXC information: %v", err)
-		d.SetId("")
-		return nil
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] VXC (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
-	if p.ProvisioningStatus == api.ProductStatusDecommissioned {
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] VXC (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
 		d.SetId("")
 		return nil
 	}
@@ -107,7 +123,7 @@ func resourceMegaportGcpVxcRead(ctx context.Context, d *schema.ResourceData, m i
 	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("a_end", flattenVxcEnd(p.AEnd)); err != nil {
+	if err := d.Set("a_end", flattenVxcAEnd(d.Get("a_end").([]interface{}), p)); err != nil {
 		return diag.FromErr(err)
 	}
 	puid := ""
@@ -125,23 +141,10 @@ func resourceMegaportGcpVxcRead(ctx context.Context, d *schema.ResourceData, m i
 
 func resourceMegaportGcpVxcCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
-	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
-	input := &api.CloudVxcCreateInput{
-		ProductUidA:   api.String(a["product_uid"]),
-		ProductUidB:   api.String(b["product_uid"]),
-		Name:          api.String(d.Get("name")),
-		PartnerConfig: &api.PartnerConfigGcp{PairingKey: api.String(b["pairing_key"])},
-		RateLimit:     api.Uint64FromInt(d.Get("rate_limit")),
-	}
-	if v, ok := d.GetOk("invoice_reference"); ok {
-		input.InvoiceReference = api.String(v)
-	}
-	if v := a["vlan"].(int); v != 0 {
-		input.VlanA = api.Uint64FromInt(v)
-	}
-	if *input.VlanA > 0 {
-		ok, err := cfg.Client.GetPortVlanIdAvailable(*input.ProductUidA, *input.VlanA)
+	defer cfg.invalidateProducts()
+	input := expandGcpVxcCreateInput(d)
+	if input.VlanA != nil {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, *input.ProductUidA, *input.VlanA)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -149,26 +152,51 @@ func resourceMegaportGcpVxcCreate(ctx context.Context, d *schema.ResourceData, m
 			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, *input.ProductUidA))
 		}
 	}
-	uid, err := cfg.Client.CreateCloudVxc(input)
+	uid, err := cfg.Client.CreateCloudVxc(ctx, input)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.SetId(*uid)
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportGcpVxcRead(ctx, d, m)
 }
 
+func expandGcpVxcCreateInput(d resourceGetter) *api.CloudVxcCreateInput {
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:    api.String(a["product_uid"]),
+		ProductUidB:    api.String(b["product_uid"]),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  &api.PartnerConfigGcp{PairingKey: api.String(b["pairing_key"])},
+		PartnerConfigA: expandVxcAEndMcrConfig(a, false),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if v := a["vnic_index"].(int); v != 0 {
+		input.VnicIndexA = api.Uint64FromInt(v)
+	}
+	return input
+}
+
 func resourceMegaportGcpVxcUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
 	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
 	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
 	input := &api.CloudVxcUpdateInput{
-		Name:          api.String(d.Get("name")),
-		PartnerConfig: &api.PartnerConfigGcp{PairingKey: api.String(b["pairing_key"])},
-		ProductUid:    api.String(d.Id()),
-		RateLimit:     api.Uint64FromInt(d.Get("rate_limit")),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  &api.PartnerConfigGcp{PairingKey: api.String(b["pairing_key"])},
+		PartnerConfigA: expandVxcAEndMcrConfig(a, d.HasChange("a_end.0.mcr_config")),
+		ProductUid:     api.String(d.Id()),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
 	}
 	if v, ok := d.GetOk("invoice_reference"); ok {
 		input.InvoiceReference = api.String(v)
@@ -176,8 +204,8 @@ func resourceMegaportGcpVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 	if v := a["vlan"].(int); v != 0 {
 		input.VlanA = api.Uint64FromInt(v)
 	}
-	if *input.VlanA > 0 {
-		ok, err := cfg.Client.GetPortVlanIdAvailable(a["product_uid"].(string), *input.VlanA)
+	if input.VlanA != nil && d.HasChange("a_end.0.vlan") {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, a["product_uid"].(string), *input.VlanA)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -185,13 +213,13 @@ func resourceMegaportGcpVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, a["product_uid"].(string)))
 		}
 	}
-	if err := cfg.Client.UpdateCloudVxc(input); err != nil {
+	if err := cfg.Client.UpdateCloudVxc(ctx, input); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilGcpVxcIsUpdated(ctx, cfg.Client, input, 5*time.Minute); err != nil {
+	if err := waitUntilGcpVxcIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportGcpVxcRead(ctx, d, m)
@@ -199,15 +227,16 @@ func resourceMegaportGcpVxcUpdate(ctx context.Context, d *schema.ResourceData, m
 
 func resourceMegaportGcpVxcDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	err := cfg.Client.DeleteVxc(d.Id())
-	if err != nil && err != api.ErrNotFound {
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteVxc(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
-	if err == api.ErrNotFound {
+	if api.IsNotFound(err) {
 		log.Printf("[DEBUG] VXC (%s) not found, deleting from state anyway", d.Id())
 		return nil
 	}
-	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
@@ -217,7 +246,7 @@ func waitUntilGcpVxcIsUpdated(ctx context.Context, client *api.Client, input *ap
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetVxc(*input.ProductUid)
+			v, err := client.GetVxc(ctx, *input.ProductUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve VXC while waiting for update to finish: %v", err)
 				return nil, "", err
@@ -2,6 +2,7 @@ package megaport
 
 import (
 	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/go-uuid"
@@ -19,6 +20,7 @@ func init() {
 }
 
 func TestAccMegaportGcpVxc_basic(t *testing.T) {
+	testAccCassette(t)
 	var (
 		vxc, vxcUpdated, vxcNew api.ProductAssociatedVxc
 		port                    api.Product
@@ -28,7 +30,7 @@ func TestAccMegaportGcpVxc_basic(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	rpk = fmt.Sprintf("%s/europe-west1/%d", rpk, acctest.RandIntRange(1, 3))
+	rpk = fmt.Sprintf("%s/europe-west1/%d", rpk, testAccRandIntRange(1, 3))
 	configValues := map[string]interface{}{
 		"uid":        rName,
 		"nameRegex":  "London",
@@ -137,3 +139,113 @@ func TestAccMegaportGcpVxc_basic(t *testing.T) {
 		t.Errorf("TestAccMegaportGcpVxc_basic: expected the VXC to be recreated but the resource ids are identical")
 	}
 }
+
+func TestAccMegaportGcpVxc_mcr(t *testing.T) {
+	testAccCassette(t)
+	var (
+		vxc, vxcUpdated, vxcRemoved api.ProductAssociatedVxc
+		mcr                         api.Product
+	)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	rpk, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpk = fmt.Sprintf("%s/europe-west1/%d", rpk, testAccRandIntRange(1, 3))
+	peerAsn := testAccRandIntRange(64512, 65535)
+	configValues := map[string]interface{}{
+		"uid":           rName,
+		"nameRegex":     "London",
+		"pairingKey":    rpk,
+		"ipAddress":     "10.0.0.1",
+		"peerIpAddress": "10.0.0.2",
+		"peerAsn":       peerAsn,
+		"password":      acctest.RandString(16),
+		"bfdInterval":   300,
+		"medIn":         100,
+	}
+	cfg, err := newTestAccConfig("megaport_gcp_vxc_mcr", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"bfdInterval": 500,
+		"medIn":       200,
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_gcp_vxc_mcr", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesRemove := mergeMaps(configValues, map[string]interface{}{
+		"ipAddress": "",
+	})
+	cfgRemove, err := newTestAccConfig("megaport_gcp_vxc_mcr", configValuesRemove, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mcr.foo", &mcr),
+					testAccCheckResourceExists("megaport_gcp_vxc.foo", &vxc),
+					resource.TestCheckResourceAttrPair("megaport_gcp_vxc.foo", "a_end.0.product_uid", "megaport_mcr.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.#", "1"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.ip_addresses.0", "10.0.0.1/30"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bfd.0.tx_interval", "300"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.#", "1"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.peer_asn", strconv.Itoa(peerAsn)),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.local_ip_address", "10.0.0.1"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.peer_ip_address", "10.0.0.2"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.password", configValues["password"].(string)),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.bfd_enabled", "true"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.med_in", "100"),
+				),
+			},
+			{
+				ResourceName:            "megaport_gcp_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid", "a_end.0.mcr_config.0.bgp_connection.0.password"},
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_gcp_vxc.foo", &vxcUpdated),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bfd.0.tx_interval", "500"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bfd.0.rx_interval", "500"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.med_in", "200"),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.password", configValues["password"].(string)),
+				),
+			},
+			{
+				PreConfig: func() { cfgRemove.log() },
+				Config:    cfgRemove.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_gcp_vxc.foo", &vxcRemoved),
+					resource.TestCheckResourceAttr("megaport_gcp_vxc.foo", "a_end.0.mcr_config.#", "0"),
+				),
+			},
+		},
+	})
+
+	if vxc.ProductUid != vxcUpdated.ProductUid || vxc.ProductUid != vxcRemoved.ProductUid {
+		t.Errorf("TestAccMegaportGcpVxc_mcr: expected the VXC to be updated but the resource ids differ")
+	}
+}
+
+func TestResourceMegaportGcpVxcRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportGcpVxc(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"VXC","provisioningStatus":"LIVE","rateLimit":100,"aEnd":{"ownerUid":"a","productUid":"b","vlan":100},"bEnd":{"ownerUid":"d","productUid":"c"},"resources":{"csp_connection":{"connectType":"GOOGLE","pairingKey":"foo"}}}}`, map[string]string{
+		"name":                          "foo",
+		"a_end.0.vlan":                  "100",
+		"b_end.0.connected_product_uid": "c",
+		"b_end.0.pairing_key":           "foo",
+	})
+}
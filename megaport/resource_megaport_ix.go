@@ -0,0 +1,317 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func resourceMegaportIx() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMegaportIxCreate,
+		ReadContext:   resourceMegaportIxRead,
+		UpdateContext: resourceMegaportIxUpdate,
+		DeleteContext: resourceMegaportIxDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceMegaportIxImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"port_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"internet_exchange": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"vlan": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"asn": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"mac_address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsMACAddress,
+			},
+			"rate_limit": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ipv4_addresses": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ipv6_addresses": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceMegaportIxRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	p, err := cfg.Client.GetIx(ctx, d.Id())
+	if err != nil {
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] IX (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] IX (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
+		d.SetId("")
+		return nil
+	}
+	if err := d.Set("name", p.ProductName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("internet_exchange", p.NetworkServiceType); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vlan", int(p.Vlan)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("asn", int(p.Asn)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("mac_address", p.MacAddress); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ipv4_addresses", p.IpAddresses(4)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ipv6_addresses", p.IpAddresses(6)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceMegaportIxCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := &api.IxCreateInput{
+		Asn:              api.Uint64FromInt(d.Get("asn")),
+		InternetExchange: api.String(d.Get("internet_exchange")),
+		MacAddress:       api.String(d.Get("mac_address")),
+		Name:             api.String(d.Get("name")),
+		ProductUid:       api.String(d.Get("port_id")),
+		RateLimit:        api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v, ok := d.GetOk("vlan"); ok {
+		input.Vlan = api.Uint64FromInt(v)
+	}
+	if input.Vlan != nil {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, *input.ProductUid, *input.Vlan)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.Vlan, *input.ProductUid))
+		}
+	}
+	uid, err := cfg.Client.CreateIx(ctx, input)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*uid)
+	if err := waitUntilIxIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportIxRead(ctx, d, m)
+}
+
+func resourceMegaportIxUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := &api.IxUpdateInput{
+		Asn:        api.Uint64FromInt(d.Get("asn")),
+		MacAddress: api.String(d.Get("mac_address")),
+		Name:       api.String(d.Get("name")),
+		ProductUid: api.String(d.Id()),
+		RateLimit:  api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v, ok := d.GetOk("vlan"); ok {
+		input.Vlan = api.Uint64FromInt(v)
+	}
+	if input.Vlan != nil && d.HasChange("vlan") {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, d.Get("port_id").(string), *input.Vlan)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.Vlan, d.Get("port_id").(string)))
+		}
+	}
+	if err := cfg.Client.UpdateIx(ctx, input); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilIxIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportIxRead(ctx, d, m)
+}
+
+func resourceMegaportIxDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteIx(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if api.IsNotFound(err) {
+		log.Printf("[DEBUG] IX (%s) not found, deleting from state anyway", d.Id())
+		return nil
+	}
+	if err := waitUntilIxIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// resourceMegaportIxImport looks up the port of the IX, which is only listed
+// in the associated IXs of the port.
+func resourceMegaportIxImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	cfg := m.(*Config)
+	ports, err := cfg.Client.ListPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range ports {
+		for _, x := range p.AssociatedIxs {
+			if x.ProductUid == d.Id() {
+				if err := d.Set("port_id", p.ProductUid); err != nil {
+					return nil, err
+				}
+				return []*schema.ResourceData{d}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("could not find the port of IX %s", d.Id())
+}
+
+func waitUntilIxIsConfigured(ctx context.Context, client *api.Client, productUid string, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
+		Refresh: func() (interface{}, string, error) {
+			v, err := client.GetIx(ctx, productUid)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve IX while waiting for setup to finish: %v", err)
+				return nil, "", err
+			}
+			if v == nil {
+				return nil, "", nil
+			}
+			return v, v.ProvisioningStatus, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for IX (%s) to be configured", productUid)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
+
+func waitUntilIxIsUpdated(ctx context.Context, client *api.Client, input *api.IxUpdateInput, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
+		Refresh: func() (interface{}, string, error) {
+			v, err := client.GetIx(ctx, *input.ProductUid)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve IX while waiting for update to finish: %v", err)
+				return nil, "", err
+			}
+			if v == nil {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.Asn, v.Asn) {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.InvoiceReference, v.CostCentre) {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.MacAddress, v.MacAddress) {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.Name, v.ProductName) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.RateLimit, v.RateLimit) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.Vlan, v.Vlan) {
+				return nil, "", nil
+			}
+			return v, v.ProvisioningStatus, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for IX (%s) to be updated", *input.ProductUid)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
+
+func waitUntilIxIsDeleted(ctx context.Context, client *api.Client, productUid string, timeout time.Duration) error {
+	return waitUntilDeleted(ctx, "IX", productUid, timeout, func() (interface{}, string, error) {
+		v, err := client.GetIx(ctx, productUid)
+		if err != nil {
+			return nil, "", err
+		}
+		return v, v.ProvisioningStatus, nil
+	})
+}
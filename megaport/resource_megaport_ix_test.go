@@ -0,0 +1,156 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func init() {
+	resource.AddTestSweepers("megaport_ix", &resource.Sweeper{
+		Name: "megaport_ix",
+		F: func(region string) error {
+			c, err := sharedClientForRegion(region)
+			if err != nil {
+				return fmt.Errorf("Error getting client: %s", err)
+			}
+			client := c.(*api.Client)
+			ports, err := client.ListPorts(context.Background())
+			if err != nil {
+				return err
+			}
+			for _, p := range ports {
+				for _, x := range p.AssociatedIxs {
+					if strings.HasPrefix(x.ProductName, "terraform_acctest_") && !client.IsResourceDeleted(x.ProvisioningStatus) {
+						if err := client.DeleteIx(context.Background(), x.ProductUid); err != nil {
+							log.Printf("[ERROR] Could not destroy IX %q (%s) during sweep: %s", x.ProductName, x.ProductUid, err)
+						}
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
+
+func TestAccMegaportIx_basic(t *testing.T) {
+	testAccCassette(t)
+	var (
+		ix, ixUpdated, ixNew api.ProductAssociatedIx
+		port                 api.Product
+	)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	configValues := map[string]interface{}{
+		"uid":              rName,
+		"location":         "Equinix LD5",
+		"internetExchange": "LON1$",
+		"asn":              testAccRandIntRange(64512, 65535),
+		"macAddress":       "00:00:5e:00:53:01",
+	}
+	cfg, err := newTestAccConfig("megaport_ix_basic", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"vlan":       testAccRandIntRange(100, 4000),
+		"asn":        testAccRandIntRange(64512, 65535),
+		"macAddress": "00:00:5e:00:53:02",
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_ix_full", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesForceNew := mergeMaps(configValuesUpdate, map[string]interface{}{
+		"internetExchange": "LON2$",
+	})
+	cfgForceNew, err := newTestAccConfig("megaport_ix_full", configValuesForceNew, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Destroy:   false,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_ix.foo", &ix),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_ix.foo", "port_id", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttrPair("megaport_ix.foo", "internet_exchange", "data.megaport_internet_exchange.foo", "name"),
+					resource.TestCheckResourceAttrSet("megaport_ix.foo", "vlan"),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "asn", strconv.Itoa(configValues["asn"].(int))),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "mac_address", configValues["macAddress"].(string)),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "rate_limit", "500"),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "invoice_reference", ""),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "ipv4_addresses.#", "1"),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "ipv6_addresses.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "megaport_ix.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Destroy:   false,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_ix.foo", &ixUpdated),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "vlan", strconv.Itoa(configValuesUpdate["vlan"].(int))),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "asn", strconv.Itoa(configValuesUpdate["asn"].(int))),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "mac_address", configValuesUpdate["macAddress"].(string)),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "rate_limit", "1000"),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "invoice_reference", rName),
+				),
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Destroy:   false,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_ix.foo", &ixNew),
+					resource.TestCheckResourceAttrPair("megaport_ix.foo", "internet_exchange", "data.megaport_internet_exchange.foo", "name"),
+					resource.TestCheckResourceAttr("megaport_ix.foo", "vlan", strconv.Itoa(configValuesForceNew["vlan"].(int))),
+				),
+			},
+		},
+	})
+
+	if ix.ProductUid != ixUpdated.ProductUid {
+		t.Errorf("TestAccMegaportIx_basic: expected the IX to be updated but the resource ids differ")
+	}
+	if ix.ProductUid == ixNew.ProductUid {
+		t.Errorf("TestAccMegaportIx_basic: expected the IX to be recreated but the resource ids are identical")
+	}
+}
+
+func TestResourceMegaportIxRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportIx(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"IX","provisioningStatus":"LIVE","networkServiceType":"London IX","asn":64512,"macAddress":"00:11:22:33:44:55","rateLimit":500,"vlan":100,"resources":{"ip_address":[{"address":"192.0.2.10/24","version":4},{"address":"2001:db8::10/64","version":6}]}}}`, map[string]string{
+		"name":              "foo",
+		"internet_exchange": "London IX",
+		"asn":               "64512",
+		"mac_address":       "00:11:22:33:44:55",
+		"rate_limit":        "500",
+		"vlan":              "100",
+		"ipv4_addresses.#":  "1",
+		"ipv4_addresses.0":  "192.0.2.10/24",
+		"ipv6_addresses.#":  "1",
+		"ipv6_addresses.0":  "2001:db8::10/64",
+	})
+}
@@ -2,10 +2,12 @@ package megaport
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
@@ -20,9 +22,31 @@ func resourceMegaportMcr() *schema.Resource {
 		DeleteContext: resourceMegaportMcrDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceMegaportProductImport,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffRestoreProduct,
+			customizeDiffValidateOrder(resourceMegaportMcr, map[string]string{
+				"asn":         "asn",
+				"locationId":  "location_id",
+				"portSpeed":   "rate_limit",
+				"productName": "name",
+			}, func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+				// Nothing is ordered when a product is restored
+				if d.Get("restore_product_uid") != "" {
+					return nil
+				}
+				return c.ValidateMcr(ctx, expandMcrCreateInput(d))
+			}),
+		),
+
 		Schema: map[string]*schema.Schema{
 			"location_id": {
 				Type:     schema.TypeInt,
@@ -48,15 +72,26 @@ func resourceMegaportMcr() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"cancellation_mode":    resourceAttributeCancellationMode(),
+			"cancellation_pending": resourceAttributeCancellationPending(),
+			"restore_product_uid":  resourceAttributeRestoreProductUid(),
 		},
 	}
 }
 
 func resourceMegaportMcrRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	p, err := cfg.Client.GetMcr(d.Id())
+	p, err := cfg.Client.GetMcr(ctx, d.Id())
 	if err != nil {
-		log.Printf("resourceMegaportMcrRead: %v", err)
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] MCR (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) && !p.CancellationPending() {
+		log.Printf("[WARN] MCR (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
 		d.SetId("")
 		return nil
 	}
@@ -75,40 +110,77 @@ func resourceMegaportMcrRead(ctx context.Context, d *schema.ResourceData, m inte
 	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := d.Set("cancellation_pending", p.CancellationPending()); err != nil {
+		return diag.FromErr(err)
+	}
 	return nil
 }
 
 func resourceMegaportMcrCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	input := &api.Mcr2CreateInput{
-		LocationId:       api.Uint64FromInt(d.Get("location_id")),
-		Name:             api.String(d.Get("name")),
-		RateLimit:        api.Uint64FromInt(d.Get("rate_limit")),
-		Asn:              api.Uint64FromInt(d.Get("asn")),
-		InvoiceReference: api.String(d.Get("invoice_reference")),
-	}
-	uid, err := cfg.Client.CreateMcr(input)
+	defer cfg.invalidateProducts()
+	if v, ok := d.GetOk("restore_product_uid"); ok {
+		p, err := getProductPendingCancellation(ctx, cfg.Client.GetMcr, v.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		asn := uint64(d.Get("asn").(int))
+		if !isMcr(p) ||
+			p.LocationId != uint64(d.Get("location_id").(int)) ||
+			p.PortSpeed != uint64(d.Get("rate_limit").(int)) ||
+			(asn != 0 && p.Resources.VirtualRouter.McrASN != asn) {
+			return diag.FromErr(fmt.Errorf("MCR %s cannot be restored, as it does not match the location_id, rate_limit and asn of the resource", p.ProductUid))
+		}
+		log.Printf("[INFO] Restoring MCR (%s), which is pending cancellation", p.ProductUid)
+		if err := cfg.Client.RestoreMcr(ctx, p.ProductUid); err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(p.ProductUid)
+		return resourceMegaportMcrUpdate(ctx, d, m)
+	}
+	uid, err := cfg.Client.CreateMcr(ctx, expandMcrCreateInput(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.SetId(*uid)
-	if err := waitUntilMcrIsConfigured(ctx, cfg.Client, *uid, 5*time.Minute); err != nil {
+	if err := waitUntilMcrIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportMcrRead(ctx, d, m)
 }
 
+func expandMcrCreateInput(d resourceGetter) *api.Mcr2CreateInput {
+	return &api.Mcr2CreateInput{
+		LocationId:       api.Uint64FromInt(d.Get("location_id")),
+		Name:             api.String(d.Get("name")),
+		RateLimit:        api.Uint64FromInt(d.Get("rate_limit")),
+		Asn:              api.Uint64FromInt(d.Get("asn")),
+		InvoiceReference: api.String(d.Get("invoice_reference")),
+	}
+}
+
 func resourceMegaportMcrUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	if d.HasChange("cancellation_pending") && !d.IsNewResource() {
+		if err := cfg.Client.RestoreMcr(ctx, d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	// Products pending cancellation cannot be updated, so only the attributes
+	// that are not sent to Megaport can change while they stay cancelled
+	if d.Get("cancellation_pending").(bool) && !d.HasChanges("invoice_reference", "name") {
+		return resourceMegaportMcrRead(ctx, d, m)
+	}
 	input := &api.Mcr2UpdateInput{
 		InvoiceReference: api.String(d.Get("invoice_reference")),
 		Name:             api.String(d.Get("name")),
 		ProductUid:       api.String(d.Id()),
 	}
-	if err := cfg.Client.UpdateMcr(input); err != nil {
+	if err := cfg.Client.UpdateMcr(ctx, input); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilMcrIsConfigured(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilMcrIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportMcrRead(ctx, d, m)
@@ -116,12 +188,37 @@ func resourceMegaportMcrUpdate(ctx context.Context, d *schema.ResourceData, m in
 
 func resourceMegaportMcrDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	err := cfg.Client.DeleteMcr(d.Id())
-	if err != nil && err != api.ErrNotFound {
+	defer cfg.invalidateProducts()
+	if d.Get("cancellation_mode") == cancellationModeEndOfTerm {
+		if d.Get("cancellation_pending").(bool) {
+			return nil
+		}
+		p, err := cfg.Client.GetMcr(ctx, d.Id())
+		if err != nil {
+			if api.IsNotFound(err) {
+				return nil
+			}
+			return diag.FromErr(err)
+		}
+		if err := checkProductHasNoServices(p); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := cfg.Client.CancelMcr(ctx, d.Id()); err != nil && !api.IsNotFound(err) {
+			return diag.FromErr(err)
+		}
+		log.Printf("[INFO] MCR (%s) will be cancelled at the end of its term", d.Id())
+		return nil
+	}
+	err := cfg.Client.DeleteMcr(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
-	if err == api.ErrNotFound {
+	if api.IsNotFound(err) {
 		log.Printf("resourceMegaportMcrDelete: resource not found, deleting anyway")
+		return nil
+	}
+	if err := waitUntilProductIsDeleted(ctx, cfg.Client.GetMcr, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
 	}
 	return nil
 }
@@ -130,7 +227,7 @@ func waitUntilMcrIsConfigured(ctx context.Context, client *api.Client, productUi
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetMcr(productUid)
+			v, err := client.GetMcr(ctx, productUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve MCR while waiting for setup to finish: %v", err)
 				return nil, "", err
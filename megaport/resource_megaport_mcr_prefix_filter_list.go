@@ -0,0 +1,280 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func resourceMegaportMcrPrefixFilterList() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMegaportMcrPrefixFilterListCreate,
+		ReadContext:   resourceMegaportMcrPrefixFilterListRead,
+		UpdateContext: resourceMegaportMcrPrefixFilterListUpdate,
+		DeleteContext: resourceMegaportMcrPrefixFilterListDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceMegaportMcrPrefixFilterListImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffMcrPrefixFilterListEntries,
+
+		Schema: map[string]*schema.Schema{
+			"mcr_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"address_family": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					api.PrefixFilterListAddressFamilyIPv4,
+					api.PrefixFilterListAddressFamilyIPv6,
+				}, false),
+			},
+			"entry": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								api.PrefixFilterListActionPermit,
+								api.PrefixFilterListActionDeny,
+							}, false),
+						},
+						"prefix": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"ge": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 128),
+						},
+						"le": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 128),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceMegaportMcrPrefixFilterListRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	id, err := strconv.ParseUint(d.Id(), 10, 64)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	l, err := cfg.Client.GetMcrPrefixFilterList(ctx, d.Get("mcr_id").(string), id)
+	if err != nil {
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] MCR prefix filter list (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", l.Description); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("address_family", l.AddressFamily); err != nil {
+		return diag.FromErr(err)
+	}
+	entries := flattenMcrPrefixFilterListEntries(l.Entries, d.Get("entry").([]interface{}))
+	if err := d.Set("entry", entries); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceMegaportMcrPrefixFilterListCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	// Prefix filter lists are applied by the API right away, but requests
+	// that keep failing are only retried until the timeout expires
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	id, err := cfg.Client.CreateMcrPrefixFilterList(ctx, &api.McrPrefixFilterListCreateInput{
+		AddressFamily: api.String(d.Get("address_family")),
+		Description:   api.String(d.Get("name")),
+		Entries:       expandMcrPrefixFilterListEntries(d.Get("entry").([]interface{})),
+		McrUid:        api.String(d.Get("mcr_id")),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.FormatUint(*id, 10))
+	return resourceMegaportMcrPrefixFilterListRead(ctx, d, m)
+}
+
+func resourceMegaportMcrPrefixFilterListUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	id, err := strconv.ParseUint(d.Id(), 10, 64)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := cfg.Client.UpdateMcrPrefixFilterList(ctx, &api.McrPrefixFilterListUpdateInput{
+		AddressFamily: api.String(d.Get("address_family")),
+		Description:   api.String(d.Get("name")),
+		Entries:       expandMcrPrefixFilterListEntries(d.Get("entry").([]interface{})),
+		Id:            &id,
+		McrUid:        api.String(d.Get("mcr_id")),
+	}); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportMcrPrefixFilterListRead(ctx, d, m)
+}
+
+func resourceMegaportMcrPrefixFilterListDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	id, err := strconv.ParseUint(d.Id(), 10, 64)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = cfg.Client.DeleteMcrPrefixFilterList(ctx, d.Get("mcr_id").(string), id)
+	if err != nil && !api.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if api.IsNotFound(err) {
+		log.Printf("[DEBUG] MCR prefix filter list (%s) not found, deleting from state anyway", d.Id())
+	}
+	return nil
+}
+
+// resourceMegaportMcrPrefixFilterListImport imports prefix filter lists by
+// "<mcr_id>:<id>", since the lists can only be retrieved through their MCR.
+func resourceMegaportMcrPrefixFilterListImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("unexpected format of id %q, expected <mcr_id>:<id>", d.Id())
+	}
+	if _, err := strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("unexpected format of id %q, expected <mcr_id>:<id>", d.Id())
+	}
+	if err := d.Set("mcr_id", parts[0]); err != nil {
+		return nil, err
+	}
+	d.SetId(parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// customizeDiffMcrPrefixFilterListEntries checks that the prefix lengths
+// matched by every entry are within its prefix, which the API only reports
+// when the list is applied.
+func customizeDiffMcrPrefixFilterListEntries(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("address_family") || !d.NewValueKnown("entry") {
+		return nil
+	}
+	family := d.Get("address_family").(string)
+	for i, v := range d.Get("entry").([]interface{}) {
+		k := fmt.Sprintf("entry.%d.", i)
+		if !d.NewValueKnown(k+"prefix") || !d.NewValueKnown(k+"ge") || !d.NewValueKnown(k+"le") {
+			continue
+		}
+		e := v.(map[string]interface{})
+		ip, n, err := net.ParseCIDR(e["prefix"].(string))
+		if err != nil {
+			continue
+		}
+		path := cty.GetAttrPath("entry").IndexInt(i)
+		if (ip.To4() != nil) != (family == api.PrefixFilterListAddressFamilyIPv4) {
+			return path.GetAttr("prefix").NewErrorf("%s is not an %s prefix", e["prefix"], family)
+		}
+		length, max := n.Mask.Size()
+		ge, le := e["ge"].(int), e["le"].(int)
+		if ge != 0 && (ge < length || ge > max) {
+			return path.GetAttr("ge").NewErrorf("ge must be between %d and %d for %s, got %d", length, max, e["prefix"], ge)
+		}
+		if le != 0 && (le < length || le > max) {
+			return path.GetAttr("le").NewErrorf("le must be between %d and %d for %s, got %d", length, max, e["prefix"], le)
+		}
+		if ge != 0 && le != 0 && ge > le {
+			return path.GetAttr("le").NewErrorf("le must not be less than ge (%d), got %d", ge, le)
+		}
+	}
+	return nil
+}
+
+// flattenMcrPrefixFilterListEntries converts the entries of a list for the
+// state. Megaport fills in ge and le with the length of the prefix and the
+// length of an address when they are not set, so those values are left unset
+// unless they were set before, instead of showing a diff.
+func flattenMcrPrefixFilterListEntries(entries []*api.McrPrefixFilterListEntry, prev []interface{}) []interface{} {
+	l := make([]interface{}, len(entries))
+	for i, e := range entries {
+		ge, le := int(e.Ge), int(e.Le)
+		p := map[string]interface{}{}
+		if i < len(prev) && prev[i].(map[string]interface{})["prefix"] == e.Prefix {
+			p = prev[i].(map[string]interface{})
+		}
+		if _, n, err := net.ParseCIDR(e.Prefix); err == nil {
+			length, max := n.Mask.Size()
+			if ge == length && p["ge"] != ge {
+				ge = 0
+			}
+			if le == max && p["le"] != le {
+				le = 0
+			}
+		}
+		l[i] = map[string]interface{}{
+			"action": e.Action,
+			"prefix": e.Prefix,
+			"ge":     ge,
+			"le":     le,
+		}
+	}
+	return l
+}
+
+func expandMcrPrefixFilterListEntries(l []interface{}) []*api.McrPrefixFilterListEntryInput {
+	entries := make([]*api.McrPrefixFilterListEntryInput, len(l))
+	for i, v := range l {
+		e := v.(map[string]interface{})
+		entries[i] = &api.McrPrefixFilterListEntryInput{
+			Action: api.String(e["action"]),
+			Prefix: api.String(e["prefix"]),
+		}
+		if v := e["ge"].(int); v != 0 {
+			entries[i].Ge = api.Uint64FromInt(v)
+		}
+		if v := e["le"].(int); v != 0 {
+			entries[i].Le = api.Uint64FromInt(v)
+		}
+	}
+	return entries
+}
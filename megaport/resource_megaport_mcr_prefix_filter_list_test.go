@@ -0,0 +1,262 @@
+package megaport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func TestAccMegaportMcrPrefixFilterList_basic(t *testing.T) {
+	testAccCassette(t)
+	var list, listUpdated, listNew api.McrPrefixFilterList
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	rpk, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpk = fmt.Sprintf("%s/europe-west1/%d", rpk, testAccRandIntRange(1, 3))
+	configValues := map[string]interface{}{
+		"uid":           rName,
+		"addressFamily": "IPv4",
+		"prefix":        "10.0.0.0/8",
+		"le":            24,
+	}
+	cfg, err := newTestAccConfig("megaport_mcr_prefix_filter_list_basic", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"pairingKey": rpk,
+		"peerAsn":    testAccRandIntRange(64512, 65535),
+		"ge":         16,
+		"le":         28,
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_mcr_prefix_filter_list_full", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesForceNew := mergeMaps(configValues, map[string]interface{}{
+		"addressFamily": "IPv6",
+		"prefix":        "2001:db8::/32",
+		"le":            48,
+	})
+	cfgForceNew, err := newTestAccConfig("megaport_mcr_prefix_filter_list_basic", configValuesForceNew, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mcr_prefix_filter_list.foo", &list),
+					resource.TestCheckResourceAttrPair("megaport_mcr_prefix_filter_list.foo", "mcr_id", "megaport_mcr.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "address_family", "IPv4"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.#", "1"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.action", "permit"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.prefix", "10.0.0.0/8"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.ge", "0"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.le", "24"),
+				),
+			},
+			{
+				ResourceName:      "megaport_mcr_prefix_filter_list.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccMcrPrefixFilterListImportStateId("megaport_mcr_prefix_filter_list.foo"),
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mcr_prefix_filter_list.foo", &listUpdated),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "name", "terraform_acctest_"+rName+"_updated"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.#", "2"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.ge", "16"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.le", "28"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.1.action", "deny"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.1.prefix", "0.0.0.0/0"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.1.le", "32"),
+					resource.TestCheckResourceAttrPair("megaport_gcp_vxc.foo", "a_end.0.mcr_config.0.bgp_connection.0.import_whitelist", "megaport_mcr_prefix_filter_list.foo", "id"),
+				),
+			},
+			{
+				ResourceName:      "megaport_mcr_prefix_filter_list.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccMcrPrefixFilterListImportStateId("megaport_mcr_prefix_filter_list.foo"),
+				// Ranges set to their defaults cannot be told apart from
+				// those left unset when they are imported
+				ImportStateVerifyIgnore: []string{"entry.1.le"},
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mcr_prefix_filter_list.foo", &listNew),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "address_family", "IPv6"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.#", "1"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.prefix", "2001:db8::/32"),
+					resource.TestCheckResourceAttr("megaport_mcr_prefix_filter_list.foo", "entry.0.le", "48"),
+				),
+			},
+		},
+	})
+
+	if list.Id != listUpdated.Id {
+		t.Errorf("TestAccMegaportMcrPrefixFilterList_basic: expected the prefix filter list to be updated but the ids differ")
+	}
+	if list.Id == listNew.Id {
+		t.Errorf("TestAccMegaportMcrPrefixFilterList_basic: expected the prefix filter list to be recreated but the ids are identical")
+	}
+}
+
+func testAccMcrPrefixFilterListImportStateId(n string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return "", fmt.Errorf("testAccMcrPrefixFilterListImportStateId: cannot find %q", n)
+		}
+		return rs.Primary.Attributes["mcr_id"] + ":" + rs.Primary.ID, nil
+	}
+}
+
+func TestResourceMegaportMcrPrefixFilterListImport(t *testing.T) {
+	testCases := []struct {
+		id    string
+		mcrId string
+		err   bool
+	}{
+		{"foo:12", "foo", false},
+		{"foo", "", true},
+		{":12", "", true},
+		{"foo:bar", "", true},
+		{"foo:12:13", "", true},
+	}
+	for i, tc := range testCases {
+		d := resourceMegaportMcrPrefixFilterList().TestResourceData()
+		d.SetId(tc.id)
+		r, err := resourceMegaportMcrPrefixFilterListImport(context.Background(), d, nil)
+		if tc.err {
+			if err == nil {
+				t.Errorf("TestResourceMegaportMcrPrefixFilterListImport #%d: expected an error for %q", i, tc.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestResourceMegaportMcrPrefixFilterListImport #%d: %v", i, err)
+			continue
+		}
+		if len(r) != 1 || r[0].Id() != "12" || r[0].Get("mcr_id") != tc.mcrId {
+			t.Errorf("TestResourceMegaportMcrPrefixFilterListImport #%d: unexpected id %q and mcr_id %q", i, r[0].Id(), r[0].Get("mcr_id"))
+		}
+	}
+}
+
+func TestCustomizeDiffMcrPrefixFilterListEntries(t *testing.T) {
+	entry := func(prefix string, ge, le int) map[string]interface{} {
+		e := map[string]interface{}{"action": "permit", "prefix": prefix}
+		if ge != 0 {
+			e["ge"] = ge
+		}
+		if le != 0 {
+			e["le"] = le
+		}
+		return e
+	}
+	testCases := []struct {
+		family string
+		entry  map[string]interface{}
+		path   cty.Path
+	}{
+		{"IPv4", entry("10.0.0.0/8", 0, 0), nil},
+		{"IPv4", entry("10.0.0.0/8", 8, 32), nil},
+		{"IPv4", entry("10.0.0.0/8", 16, 16), nil},
+		{"IPv6", entry("2001:db8::/32", 48, 128), nil},
+		{"IPv4", entry("10.0.0.0/8", 4, 0), cty.GetAttrPath("entry").IndexInt(1).GetAttr("ge")},
+		{"IPv4", entry("10.0.0.0/8", 33, 0), cty.GetAttrPath("entry").IndexInt(1).GetAttr("ge")},
+		{"IPv4", entry("10.0.0.0/8", 0, 4), cty.GetAttrPath("entry").IndexInt(1).GetAttr("le")},
+		{"IPv4", entry("10.0.0.0/8", 0, 48), cty.GetAttrPath("entry").IndexInt(1).GetAttr("le")},
+		{"IPv4", entry("10.0.0.0/8", 24, 16), cty.GetAttrPath("entry").IndexInt(1).GetAttr("le")},
+		{"IPv6", entry("2001:db8::/32", 24, 0), cty.GetAttrPath("entry").IndexInt(1).GetAttr("ge")},
+		{"IPv6", entry("10.0.0.0/8", 0, 0), cty.GetAttrPath("entry").IndexInt(1).GetAttr("prefix")},
+		{"IPv4", entry("2001:db8::/32", 0, 0), cty.GetAttrPath("entry").IndexInt(1).GetAttr("prefix")},
+	}
+	for i, tc := range testCases {
+		first := entry("10.0.0.0/8", 0, 0)
+		if tc.family == "IPv6" {
+			first = entry("2001:db8::/32", 0, 0)
+		}
+		config := map[string]interface{}{
+			"mcr_id":         "foo",
+			"name":           "foo",
+			"address_family": tc.family,
+			"entry":          []interface{}{first, tc.entry},
+		}
+		_, err := resourceMegaportMcrPrefixFilterList().SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(config), nil)
+		if tc.path == nil {
+			if err != nil {
+				t.Errorf("TestCustomizeDiffMcrPrefixFilterListEntries (#%d): %v", i, err)
+			}
+			continue
+		}
+		pe := cty.PathError{}
+		if !errors.As(err, &pe) || !pe.Path.Equals(tc.path) {
+			t.Errorf("TestCustomizeDiffMcrPrefixFilterListEntries (#%d): expected an error for %#v, got %#v", i, tc.path, err)
+		}
+	}
+}
+
+func TestFlattenMcrPrefixFilterListEntries(t *testing.T) {
+	entries := []*api.McrPrefixFilterListEntry{
+		{Action: "permit", Prefix: "10.0.0.0/8", Ge: 8, Le: 32},
+		{Action: "permit", Prefix: "10.0.0.0/8", Ge: 8, Le: 32},
+		{Action: "deny", Prefix: "2001:db8::/32", Ge: 32, Le: 128},
+		{Action: "deny", Prefix: "192.168.0.0/16", Ge: 16, Le: 32},
+		{Action: "deny", Prefix: "192.168.0.0/16", Ge: 24, Le: 28},
+	}
+	prev := []interface{}{
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 8, "le": 32},
+		map[string]interface{}{"action": "deny", "prefix": "2001:db8::/32", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "172.16.0.0/12", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "192.168.0.0/16", "ge": 0, "le": 0},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 8, "le": 32},
+		map[string]interface{}{"action": "deny", "prefix": "2001:db8::/32", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "192.168.0.0/16", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "192.168.0.0/16", "ge": 24, "le": 28},
+	}
+	if diff := cmp.Diff(expected, flattenMcrPrefixFilterListEntries(entries, prev)); diff != "" {
+		t.Errorf("TestFlattenMcrPrefixFilterListEntries: unexpected entries:\n%s", diff)
+	}
+	// Lists that are imported leave the values filled in by Megaport unset
+	expected = []interface{}{
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "permit", "prefix": "10.0.0.0/8", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "2001:db8::/32", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "192.168.0.0/16", "ge": 0, "le": 0},
+		map[string]interface{}{"action": "deny", "prefix": "192.168.0.0/16", "ge": 24, "le": 28},
+	}
+	if diff := cmp.Diff(expected, flattenMcrPrefixFilterListEntries(entries, nil)); diff != "" {
+		t.Errorf("TestFlattenMcrPrefixFilterListEntries: unexpected imported entries:\n%s", diff)
+	}
+}
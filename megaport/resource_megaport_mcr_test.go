@@ -1,15 +1,21 @@
 package megaport
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
@@ -19,7 +25,9 @@ func init() {
 		Name: "megaport_mcr",
 		Dependencies: []string{
 			"megaport_aws_vxc",
+			"megaport_azure_vxc",
 			"megaport_gcp_vxc",
+			"megaport_oracle_vxc",
 			"megaport_private_vxc",
 		},
 		F: func(region string) error {
@@ -28,13 +36,13 @@ func init() {
 				return fmt.Errorf("Error getting client: %s", err)
 			}
 			client := c.(*api.Client)
-			mcrs, err := client.ListMcrs()
+			mcrs, err := client.ListMcrs(context.Background())
 			if err != nil {
 				return err
 			}
 			for _, m := range mcrs {
 				if strings.HasPrefix(m.ProductName, "terraform_acctest_") && !client.IsResourceDeleted(m.ProvisioningStatus) {
-					if err := client.DeleteMcr(m.ProductUid); err != nil {
+					if err := client.DeleteMcr(context.Background(), m.ProductUid); err != nil {
 						log.Printf("[ERROR] Could not destroy mcr %q (%s) during sweep: %s", m.ProductName, m.ProductUid, err)
 					}
 				}
@@ -45,6 +53,7 @@ func init() {
 }
 
 func TestAccMegaportMcr2_basic(t *testing.T) {
+	testAccCassette(t)
 	var mcr, mcrUpdated, mcrNew api.Product
 	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
 	configValues := map[string]interface{}{
@@ -60,7 +69,7 @@ func TestAccMegaportMcr2_basic(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	rAsn := acctest.RandIntRange(1, math.MaxInt32)
+	rAsn := testAccRandIntRange(1, math.MaxInt32)
 	configValuesNew := mergeMaps(configValues, map[string]interface{}{
 		"asn":        rAsn,
 		"rate_limit": 2500,
@@ -136,3 +145,82 @@ func TestAccMegaportMcr2_basic(t *testing.T) {
 		t.Errorf("TestAccMegaportMcr_basic: expected the MCR to be recreated but the resource ids are identical")
 	}
 }
+
+func TestAccMegaportMcr2_validation(t *testing.T) {
+	testAccCassette(t)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	cfg, err := newTestAccConfig("megaport_mcr_basic", map[string]interface{}{
+		"uid":        rName,
+		"location":   "Global Switch London East",
+		"rate_limit": 1234,
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig:   func() { cfg.log() },
+				Config:      cfg.Config,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`(?s)Error: MCR speed 1234 is not available.*rate_limit\s+= 1234`),
+			},
+		},
+	})
+}
+
+func TestResourceMegaportMcrRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportMcr(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"MCR2","provisioningStatus":"LIVE","locationId":1,"portSpeed":1000,"resources":{"virtual_router":{"mcrAsn":133937}}}}`, map[string]string{
+		"name":                 "foo",
+		"location_id":          "1",
+		"rate_limit":           "1000",
+		"asn":                  "133937",
+		"cancellation_pending": "false",
+	})
+}
+
+func TestResourceMegaportMcrCreate_timeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/networkdesign/validate":
+			fmt.Fprint(w, `{"message":"Validation passed"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/networkdesign/buy":
+			fmt.Fprint(w, `{"data":[{"technicalServiceUid":"`+testResourceReadUid+`"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/product/"+testResourceReadUid:
+			fmt.Fprint(w, `{"data":{"productUid":"`+testResourceReadUid+`","provisioningStatus":"DEPLOYABLE"}}`)
+		default:
+			t.Errorf("TestResourceMegaportMcrCreate_timeout: unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	cfg := &Config{Client: api.NewClient(s.URL)}
+	cfg.Client.MaxRetries = 0
+	r := resourceMegaportMcr()
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"location_id": 1,
+		"name":        "foo",
+		"rate_limit":  1000,
+		"timeouts": map[string]interface{}{
+			"create": "1s",
+		},
+	}), cfg)
+	if err != nil {
+		t.Fatalf("TestResourceMegaportMcrCreate_timeout: unexpected error: %v", err)
+	}
+	start := time.Now()
+	state, diags := r.Apply(context.Background(), nil, diff, cfg)
+	if !diags.HasError() {
+		t.Errorf("TestResourceMegaportMcrCreate_timeout: expected the create to time out")
+	}
+	if state.ID != testResourceReadUid {
+		t.Errorf("TestResourceMegaportMcrCreate_timeout: expected the MCR to be saved in the state, got %q", state.ID)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("TestResourceMegaportMcrCreate_timeout: expected the create to be aborted after 1s, took %s", d)
+	}
+}
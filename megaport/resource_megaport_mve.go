@@ -0,0 +1,455 @@
+package megaport
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+var mveVendors = []string{
+	api.MveVendorCisco,
+	api.MveVendorFortinet,
+	api.MveVendorPaloAlto,
+	api.MveVendorVersa,
+}
+
+func resourceMegaportMve() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMegaportMveCreate,
+		ReadContext:   resourceMegaportMveRead,
+		UpdateContext: resourceMegaportMveUpdate,
+		DeleteContext: resourceMegaportMveDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"location_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"term": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntInSlice([]int{1, 12, 24, 36}),
+			},
+			"image_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"size": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{api.MveSizeSmall, api.MveSizeMedium, api.MveSizeLarge}, false),
+			},
+			"vnic": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+				MaxItems: 5,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"description": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"cisco": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: mveVendors,
+				Elem:         resourceMegaportMveCiscoElem(),
+			},
+			"fortinet": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: mveVendors,
+				Elem:         resourceMegaportMveFortinetElem(),
+			},
+			"palo_alto": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: mveVendors,
+				Elem:         resourceMegaportMvePaloAltoElem(),
+			},
+			"versa": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: mveVendors,
+				Elem:         resourceMegaportMveVersaElem(),
+			},
+			"vendor": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportMveCiscoElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"admin_ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"cloud_init": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"manage_locally": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+			},
+			"fmc_ip_address": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			"fmc_nat_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"fmc_registration_key": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportMveFortinetElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"admin_ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"license_data": {
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportMvePaloAltoElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"admin_ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"ssh_public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"admin_password_hash": {
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+			"license_data": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportMveVersaElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"director_address": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"controller_address": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"local_auth": {
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+			"remote_auth": {
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+			"serial_number": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportMveRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	p, err := cfg.Client.GetMve(ctx, d.Id())
+	if err != nil {
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] MVE (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] MVE (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
+		d.SetId("")
+		return nil
+	}
+	if err := d.Set("location_id", int(p.LocationId)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", p.ProductName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("term", int(p.ContractTermMonths)); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(p.Resources.VirtualMachine) > 0 {
+		if err := d.Set("image_id", int(p.Resources.VirtualMachine[0].Image.Id)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("size", p.MveSize); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vnic", flattenMveVnics(p.Vnics)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vendor", p.Vendor); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceMegaportMveCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := &api.MveCreateInput{
+		InvoiceReference: api.String(d.Get("invoice_reference")),
+		LocationId:       api.Uint64FromInt(d.Get("location_id")),
+		Name:             api.String(d.Get("name")),
+		Term:             api.Uint64FromInt(d.Get("term")),
+		VendorConfig:     expandMveVendorConfig(d),
+	}
+	for _, v := range d.Get("vnic").([]interface{}) {
+		input.VnicDescriptions = append(input.VnicDescriptions, v.(map[string]interface{})["description"].(string))
+	}
+	uid, err := cfg.Client.CreateMve(ctx, input)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*uid)
+	if err := waitUntilMveIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportMveRead(ctx, d, m)
+}
+
+func resourceMegaportMveUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := &api.MveUpdateInput{
+		InvoiceReference: api.String(d.Get("invoice_reference")),
+		Name:             api.String(d.Get("name")),
+		ProductUid:       api.String(d.Id()),
+	}
+	if err := cfg.Client.UpdateMve(ctx, input); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilMveIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportMveRead(ctx, d, m)
+}
+
+func resourceMegaportMveDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteMve(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if api.IsNotFound(err) {
+		log.Printf("resourceMegaportMveDelete: resource not found, deleting anyway")
+		return nil
+	}
+	if err := waitUntilProductIsDeleted(ctx, cfg.Client.GetMve, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func flattenMveVnics(vnics []api.ProductVnic) []interface{} {
+	l := make([]interface{}, len(vnics))
+	for i, v := range vnics {
+		l[i] = map[string]interface{}{"description": v.Description}
+	}
+	return l
+}
+
+// expandMveVendorConfig returns the configuration of the vendor block that is
+// set, along with the image and size of the MVE.
+func expandMveVendorConfig(d *schema.ResourceData) api.MveVendorConfig {
+	imageId := api.Uint64FromInt(d.Get("image_id"))
+	size := api.String(d.Get("size"))
+	if v, ok := d.GetOk("cisco"); ok {
+		c := v.([]interface{})[0].(map[string]interface{})
+		vc := &api.MveVendorConfigCisco{
+			AdminSshPublicKey: api.String(c["admin_ssh_public_key"]),
+			ImageId:           imageId,
+			ProductSize:       size,
+			SshPublicKey:      api.String(c["ssh_public_key"]),
+		}
+		if s := c["cloud_init"].(string); s != "" {
+			vc.CloudInit = api.String(s)
+		}
+		if c["manage_locally"].(bool) {
+			vc.ManageLocally = api.Bool(true)
+		}
+		if s := c["fmc_ip_address"].(string); s != "" {
+			vc.FmcIpAddress = api.String(s)
+		}
+		if s := c["fmc_nat_id"].(string); s != "" {
+			vc.FmcNatId = api.String(s)
+		}
+		if s := c["fmc_registration_key"].(string); s != "" {
+			vc.FmcRegistrationKey = api.String(s)
+		}
+		return vc
+	}
+	if v, ok := d.GetOk("fortinet"); ok {
+		c := v.([]interface{})[0].(map[string]interface{})
+		return &api.MveVendorConfigFortinet{
+			AdminSshPublicKey: api.String(c["admin_ssh_public_key"]),
+			ImageId:           imageId,
+			LicenseData:       api.String(c["license_data"]),
+			ProductSize:       size,
+			SshPublicKey:      api.String(c["ssh_public_key"]),
+		}
+	}
+	if v, ok := d.GetOk("palo_alto"); ok {
+		c := v.([]interface{})[0].(map[string]interface{})
+		vc := &api.MveVendorConfigPaloAlto{
+			AdminPasswordHash: api.String(c["admin_password_hash"]),
+			AdminSshPublicKey: api.String(c["admin_ssh_public_key"]),
+			ImageId:           imageId,
+			ProductSize:       size,
+			SshPublicKey:      api.String(c["ssh_public_key"]),
+		}
+		if s := c["license_data"].(string); s != "" {
+			vc.LicenseData = api.String(s)
+		}
+		return vc
+	}
+	c := d.Get("versa").([]interface{})[0].(map[string]interface{})
+	return &api.MveVendorConfigVersa{
+		ControllerAddress: api.String(c["controller_address"]),
+		DirectorAddress:   api.String(c["director_address"]),
+		ImageId:           imageId,
+		LocalAuth:         api.String(c["local_auth"]),
+		ProductSize:       size,
+		RemoteAuth:        api.String(c["remote_auth"]),
+		SerialNumber:      api.String(c["serial_number"]),
+	}
+}
+
+func waitUntilMveIsConfigured(ctx context.Context, client *api.Client, productUid string, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
+		Refresh: func() (interface{}, string, error) {
+			v, err := client.GetMve(ctx, productUid)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve MVE while waiting for setup to finish: %v", err)
+				return nil, "", err
+			}
+			if v == nil {
+				return nil, "", nil
+			}
+			return v, v.ProvisioningStatus, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for MVE (%s) to be configured", productUid)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
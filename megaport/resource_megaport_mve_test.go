@@ -0,0 +1,158 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func init() {
+	resource.AddTestSweepers("megaport_mve", &resource.Sweeper{
+		Name: "megaport_mve",
+		Dependencies: []string{
+			"megaport_aws_vxc",
+			"megaport_azure_vxc",
+			"megaport_gcp_vxc",
+			"megaport_oracle_vxc",
+			"megaport_private_vxc",
+		},
+		F: func(region string) error {
+			c, err := sharedClientForRegion(region)
+			if err != nil {
+				return fmt.Errorf("Error getting client: %s", err)
+			}
+			client := c.(*api.Client)
+			mves, err := client.ListMves(context.Background())
+			if err != nil {
+				return err
+			}
+			for _, m := range mves {
+				if m.ProductType == api.ProductTypeMve && strings.HasPrefix(m.ProductName, "terraform_acctest_") && !client.IsResourceDeleted(m.ProvisioningStatus) {
+					if err := client.DeleteMve(context.Background(), m.ProductUid); err != nil {
+						log.Printf("[ERROR] Could not destroy MVE %q (%s) during sweep: %s", m.ProductName, m.ProductUid, err)
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
+
+func TestAccMegaportMve_basic(t *testing.T) {
+	testAccCassette(t)
+	var (
+		mve, mveUpdated, mveNew api.Product
+		vxc                     api.ProductAssociatedVxc
+	)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	configValues := map[string]interface{}{
+		"uid":          rName,
+		"location":     "Equinix LD5",
+		"imageId":      testAccRandIntRange(1, 100),
+		"sshPublicKey": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGb1q3L4fCZmMYj1NdHfR9gWzqvVpNLhRZ2A9rRk0fJ0 terraform_acctest",
+	}
+	cfg, err := newTestAccConfig("megaport_mve_basic", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"invoiceReference": rName,
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_mve_basic", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgForceNew, err := newTestAccConfig("megaport_mve_full", configValues, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mve.foo", &mve),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_mve.foo", "location_id", "data.megaport_location.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "term", "1"),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "image_id", fmt.Sprint(configValues["imageId"])),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "size", api.MveSizeSmall),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "vendor", api.MveVendorCisco),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "vnic.#", "1"),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "invoice_reference", ""),
+				),
+			},
+			{
+				ResourceName:            "megaport_mve.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"cisco"},
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mve.foo", &mveUpdated),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "invoice_reference", rName),
+				),
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_mve.foo", &mveNew),
+					testAccCheckResourceExists("megaport_private_vxc.foo", &vxc),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "term", "12"),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "size", api.MveSizeMedium),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "vnic.#", "2"),
+					resource.TestCheckResourceAttr("megaport_mve.foo", "vnic.1.description", "outside"),
+					resource.TestCheckResourceAttrPair("megaport_private_vxc.foo", "a_end.0.product_uid", "megaport_mve.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_private_vxc.foo", "a_end.0.vnic_index", "1"),
+				),
+			},
+			{
+				ResourceName:            "megaport_mve.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"cisco"},
+			},
+		},
+	})
+
+	if mve.ProductUid != mveUpdated.ProductUid {
+		t.Errorf("TestAccMegaportMve_basic: expected the MVE to be updated but the resource ids differ")
+	}
+	if mve.ProductUid == mveNew.ProductUid {
+		t.Errorf("TestAccMegaportMve_basic: expected the MVE to be recreated but the resource ids are identical")
+	}
+	if vxc.AEnd.VnicIndex != 1 {
+		t.Errorf("TestAccMegaportMve_basic: expected the VXC to use vNIC 1 but got %d", vxc.AEnd.VnicIndex)
+	}
+}
+
+func TestResourceMegaportMveRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportMve(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"MVE","provisioningStatus":"LIVE","locationId":1,"contractTermMonths":1,"mveSize":"SMALL","vendor":"cisco","vnics":[{"description":"Data Plane","vlan":0},{"description":"Management","vlan":0}],"resources":{"virtual_machine":[{"id":1,"image":{"id":42,"vendor":"Cisco"}}]}}}`, map[string]string{
+		"name":               "foo",
+		"location_id":        "1",
+		"term":               "1",
+		"size":               "SMALL",
+		"vendor":             "cisco",
+		"image_id":           "42",
+		"vnic.#":             "2",
+		"vnic.0.description": "Data Plane",
+		"vnic.1.description": "Management",
+	})
+}
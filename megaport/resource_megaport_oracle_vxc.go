@@ -0,0 +1,287 @@
+package megaport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func resourceMegaportOracleVxc() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMegaportOracleVxcCreate,
+		ReadContext:   resourceMegaportOracleVxcRead,
+		UpdateContext: resourceMegaportOracleVxcUpdate,
+		DeleteContext: resourceMegaportOracleVxcDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffValidateOrder(resourceMegaportOracleVxc, vxcOrderFieldsWith(map[string]string{
+			"partnerConfigs.virtualCircuitId": "b_end.0.virtual_circuit_id",
+		}), func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+			return c.ValidateCloudVxc(ctx, expandOracleVxcCreateInput(d))
+		}),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"rate_limit": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"a_end": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcAEndElem(),
+			},
+			"b_end": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     resourceMegaportVxcOracleEndElem(),
+			},
+			"invoice_reference": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceMegaportVxcOracleEndElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"product_uid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old == "" && d.Get("b_end.0.connected_product_uid").(string) != ""
+				},
+			},
+			"connected_product_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"virtual_circuit_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateOracleVirtualCircuitId,
+			},
+		},
+	}
+}
+
+// flattenVxcEndOracle returns the B End of an Oracle VXC. The virtual circuit
+// is taken from the configuration while the VXC has no Oracle connection, e.g.
+// while it is being provisioned.
+func flattenVxcEndOracle(config map[string]interface{}, v *api.ProductAssociatedVxc) []interface{} {
+	b := map[string]interface{}{
+		"product_uid":           config["product_uid"],
+		"connected_product_uid": v.BEnd.ProductUid,
+		"virtual_circuit_id":    config["virtual_circuit_id"],
+	}
+	if cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeOracle).(*api.ProductAssociatedVxcResourcesCspConnectionOracle); ok {
+		b["virtual_circuit_id"] = cc.VirtualCircuitId
+	}
+	return []interface{}{b}
+}
+
+func resourceMegaportOracleVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	p, err := cfg.Client.GetVxc(ctx, d.Id())
+	if err != nil {
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] VXC (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] VXC (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
+		d.SetId("")
+		return nil
+	}
+	if err := d.Set("name", p.ProductName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("a_end", flattenVxcAEnd(d.Get("a_end").([]interface{}), p)); err != nil {
+		return diag.FromErr(err)
+	}
+	b := map[string]interface{}{"product_uid": ""}
+	if v := d.Get("b_end").([]interface{}); len(v) > 0 {
+		b = v[0].(map[string]interface{})
+	}
+	if err := d.Set("b_end", flattenVxcEndOracle(b, p)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invoice_reference", p.CostCentre); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceMegaportOracleVxcCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := expandOracleVxcCreateInput(d)
+	if input.VlanA != nil {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, *input.ProductUidA, *input.VlanA)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, *input.ProductUidA))
+		}
+	}
+	uid, err := cfg.Client.CreateCloudVxc(ctx, input)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*uid)
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportOracleVxcRead(ctx, d, m)
+}
+
+func expandOracleVxcCreateInput(d resourceGetter) *api.CloudVxcCreateInput {
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcCreateInput{
+		ProductUidA:    api.String(a["product_uid"]),
+		ProductUidB:    api.String(b["product_uid"]),
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  &api.PartnerConfigOracle{VirtualCircuitId: api.String(b["virtual_circuit_id"])},
+		PartnerConfigA: expandVxcAEndMcrConfig(a, false),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if v := a["vnic_index"].(int); v != 0 {
+		input.VnicIndexA = api.Uint64FromInt(v)
+	}
+	return input
+}
+
+func resourceMegaportOracleVxcUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
+	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
+	input := &api.CloudVxcUpdateInput{
+		Name:           api.String(d.Get("name")),
+		PartnerConfig:  &api.PartnerConfigOracle{VirtualCircuitId: api.String(b["virtual_circuit_id"])},
+		PartnerConfigA: expandVxcAEndMcrConfig(a, d.HasChange("a_end.0.mcr_config")),
+		ProductUid:     api.String(d.Id()),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
+	}
+	if v, ok := d.GetOk("invoice_reference"); ok {
+		input.InvoiceReference = api.String(v)
+	}
+	if v := a["vlan"].(int); v != 0 {
+		input.VlanA = api.Uint64FromInt(v)
+	}
+	if input.VlanA != nil && d.HasChange("a_end.0.vlan") {
+		ok, err := cfg.Client.GetPortVlanIdAvailable(ctx, a["product_uid"].(string), *input.VlanA)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !ok {
+			return diag.FromErr(fmt.Errorf("VLAN id %d is unavailable on product %s", *input.VlanA, a["product_uid"].(string)))
+		}
+	}
+	if err := cfg.Client.UpdateCloudVxc(ctx, input); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := waitUntilOracleVxcIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportOracleVxcRead(ctx, d, m)
+}
+
+func resourceMegaportOracleVxcDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteVxc(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if api.IsNotFound(err) {
+		log.Printf("[DEBUG] VXC (%s) not found, deleting from state anyway", d.Id())
+		return nil
+	}
+	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func waitUntilOracleVxcIsUpdated(ctx context.Context, client *api.Client, input *api.CloudVxcUpdateInput, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
+		Refresh: func() (interface{}, string, error) {
+			v, err := client.GetVxc(ctx, *input.ProductUid)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve VXC while waiting for update to finish: %v", err)
+				return nil, "", err
+			}
+			if v == nil {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.InvoiceReference, v.CostCentre) {
+				return nil, "", nil
+			}
+			if !compareNillableStrings(input.Name, v.ProductName) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.RateLimit, v.RateLimit) {
+				return nil, "", nil
+			}
+			if !compareNillableUints(input.VlanA, v.AEnd.Vlan) {
+				return nil, "", nil
+			}
+			pc := input.PartnerConfig.(*api.PartnerConfigOracle)
+			cc, ok := v.Resources.GetCspConnection(api.VxcConnectTypeOracle).(*api.ProductAssociatedVxcResourcesCspConnectionOracle)
+			if !ok || !compareNillableStrings(pc.VirtualCircuitId, cc.VirtualCircuitId) {
+				return nil, "", nil
+			}
+			return v, v.ProvisioningStatus, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for VXC (%s) to be updated", *input.ProductUid)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
@@ -0,0 +1,172 @@
+package megaport
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
+)
+
+func init() {
+	resource.AddTestSweepers("megaport_oracle_vxc", &resource.Sweeper{
+		Name: "megaport_oracle_vxc",
+		F:    testAccVxcSweeper(api.VxcTypeOracle),
+	})
+}
+
+func TestAccMegaportOracleVxc_basic(t *testing.T) {
+	testAccCassette(t)
+	var (
+		vxc, vxcUpdated, vxcNew api.ProductAssociatedVxc
+		port                    api.Product
+	)
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	rvc := "ocid1.virtualcircuit.oc1.uk-london-1." + acctest.RandStringFromCharSet(60, "abcdefghijklmnopqrstuvwxyz0123456789")
+	configValues := map[string]interface{}{
+		"uid":              rName,
+		"nameRegex":        "London",
+		"portType":         "primary",
+		"virtualCircuitId": rvc,
+		"rateLimit":        "data.megaport_partner_port.oracle.bandwidths[0]",
+		"vlan":             456,
+	}
+	cfg, err := newTestAccConfig("megaport_oracle_vxc_basic", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesUpdate := mergeMaps(configValues, map[string]interface{}{
+		"rateLimit": "data.megaport_partner_port.oracle.bandwidths[1]",
+		"vlan":      567,
+	})
+	cfgUpdate, err := newTestAccConfig("megaport_oracle_vxc_full", configValuesUpdate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configValuesForceNew := mergeMaps(configValuesUpdate, map[string]interface{}{
+		"portType": "secondary",
+	})
+	cfgForceNew, err := newTestAccConfig("megaport_oracle_vxc_full", configValuesForceNew, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_oracle_vxc.foo", &vxc),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "rate_limit", "data.megaport_partner_port.oracle", "bandwidths.0"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "invoice_reference", ""),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "a_end.0.vlan", "456"),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.oracle", "id"),
+					resource.TestCheckResourceAttrSet("megaport_oracle_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "b_end.0.virtual_circuit_id", rvc),
+				),
+			},
+			{
+				ResourceName:            "megaport_oracle_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid"},
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_oracle_vxc.foo", &vxcUpdated),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "rate_limit", "data.megaport_partner_port.oracle", "bandwidths.1"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "invoice_reference", rName),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "a_end.0.vlan", "567"),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.oracle", "id"),
+					resource.TestCheckResourceAttrSet("megaport_oracle_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "b_end.0.virtual_circuit_id", rvc),
+				),
+			},
+			{
+				ResourceName:            "megaport_oracle_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid"},
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_oracle_vxc.foo", &vxcNew),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "name", "terraform_acctest_"+rName),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "rate_limit", "data.megaport_partner_port.oracle", "bandwidths.1"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "invoice_reference", rName),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "a_end.0.product_uid", "megaport_port.foo", "id"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "a_end.0.vlan", "567"),
+					resource.TestCheckResourceAttrPair("megaport_oracle_vxc.foo", "b_end.0.product_uid", "data.megaport_partner_port.oracle", "id"),
+					resource.TestCheckResourceAttrSet("megaport_oracle_vxc.foo", "b_end.0.connected_product_uid"),
+					resource.TestCheckResourceAttr("megaport_oracle_vxc.foo", "b_end.0.virtual_circuit_id", rvc),
+				),
+			},
+			{
+				ResourceName:            "megaport_oracle_vxc.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"b_end.0.product_uid"},
+			},
+		},
+	})
+
+	if vxc.ProductUid != vxcUpdated.ProductUid {
+		t.Errorf("TestAccMegaportOracleVxc_basic: expected the VXC to be updated but the resource ids differ")
+	}
+	if vxc.ProductUid == vxcNew.ProductUid {
+		t.Errorf("TestAccMegaportOracleVxc_basic: expected the VXC to be recreated but the resource ids are identical")
+	}
+}
+
+func TestResourceMegaportOracleVxcRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportOracleVxc(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"VXC","provisioningStatus":"LIVE","rateLimit":100,"aEnd":{"ownerUid":"a","productUid":"b","vlan":100},"bEnd":{"ownerUid":"d","productUid":"c"},"resources":{"csp_connection":{"connectType":"ORACLE","virtualCircuitId":"foo"}}}}`, map[string]string{
+		"name":                          "foo",
+		"a_end.0.vlan":                  "100",
+		"b_end.0.connected_product_uid": "c",
+		"b_end.0.virtual_circuit_id":    "foo",
+	})
+}
+
+func TestFlattenVxcEndOracle(t *testing.T) {
+	config := map[string]interface{}{"product_uid": "b", "virtual_circuit_id": "foo"}
+	testCases := []struct {
+		vxc      string
+		expected map[string]interface{}
+	}{
+		{ // 0: without an Oracle connection, e.g. while provisioning, the configuration is kept
+			vxc:      `{"bEnd":{"productUid":"c"}}`,
+			expected: map[string]interface{}{"product_uid": "b", "connected_product_uid": "c", "virtual_circuit_id": "foo"},
+		},
+		{ // 1: the virtual circuit of the Oracle connection is read
+			vxc:      `{"bEnd":{"productUid":"c"},"resources":{"csp_connection":{"connectType":"ORACLE","virtualCircuitId":"bar"}}}`,
+			expected: map[string]interface{}{"product_uid": "b", "connected_product_uid": "c", "virtual_circuit_id": "bar"},
+		},
+	}
+	for i, tc := range testCases {
+		v := &api.ProductAssociatedVxc{}
+		if err := json.Unmarshal([]byte(tc.vxc), v); err != nil {
+			t.Fatalf("TestFlattenVxcEndOracle (#%d): %v", i, err)
+		}
+		if diff := cmp.Diff([]interface{}{tc.expected}, flattenVxcEndOracle(config, v)); diff != "" {
+			t.Errorf("TestFlattenVxcEndOracle (#%d): unexpected B-End:\n%s", i, diff)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package megaport
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -21,9 +23,42 @@ func resourceMegaportPort() *schema.Resource {
 		DeleteContext: resourceMegaportPortDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceMegaportProductImport,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		// Ports can be added to a LAG but not removed from it, and a single
+		// port cannot become a LAG
+		CustomizeDiff: customdiff.Sequence(
+			customdiff.All(
+				customdiff.ForceNewIfChange("lag_port_count", func(ctx context.Context, old, new, meta interface{}) bool {
+					return old.(int) == 0 || new.(int) < old.(int)
+				}),
+				customdiff.ComputedIf("lag_port_uids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+					return d.HasChange("lag_port_count")
+				}),
+				customizeDiffRestoreProduct,
+			),
+			customizeDiffValidateOrder(resourceMegaportPort, map[string]string{
+				"locationId":   "location_id",
+				"lagPortCount": "lag_port_count",
+				"portSpeed":    "speed",
+				"productName":  "name",
+				"term":         "term",
+			}, func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+				// Nothing is ordered when a product is restored
+				if d.Get("restore_product_uid") != "" {
+					return nil
+				}
+				return c.ValidatePort(ctx, expandPortCreateInput(d))
+			}),
+		),
+
 		Schema: map[string]*schema.Schema{
 			"location_id": {
 				Type:     schema.TypeInt,
@@ -51,15 +86,40 @@ func resourceMegaportPort() *schema.Resource {
 				Optional: true,
 			},
 			"marketplace_visibility": resourceAttributePrivatePublic(),
+			"lag_port_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 8),
+			},
+			"lag_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"lag_port_uids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"cancellation_mode":    resourceAttributeCancellationMode(),
+			"cancellation_pending": resourceAttributeCancellationPending(),
+			"restore_product_uid":  resourceAttributeRestoreProductUid(),
 		},
 	}
 }
 
 func resourceMegaportPortRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	p, err := cfg.Client.GetPort(d.Id())
+	p, err := cfg.Client.GetPort(ctx, d.Id())
 	if err != nil {
-		log.Printf("resourceMegaportPortRead: %v", err)
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] Port (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if isResourceDeleted(p.ProvisioningStatus) && !p.CancellationPending() {
+		log.Printf("[WARN] Port (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
 		d.SetId("")
 		return nil
 	}
@@ -86,32 +146,98 @@ func resourceMegaportPortRead(ctx context.Context, d *schema.ResourceData, m int
 			return diag.FromErr(err)
 		}
 	}
+	uids := []string{}
+	if p.LagId != 0 {
+		lag, err := cfg.Client.GetLagPorts(ctx, p.LagId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, v := range lag {
+			uids = append(uids, v.ProductUid)
+		}
+	}
+	if err := d.Set("lag_id", int(p.LagId)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("lag_port_count", len(uids)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("lag_port_uids", uids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("cancellation_pending", p.CancellationPending()); err != nil {
+		return diag.FromErr(err)
+	}
 	return nil
 }
 
 func resourceMegaportPortCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	uid, err := cfg.Client.CreatePort(&api.PortCreateInput{
-		LocationId:            api.Uint64FromInt(d.Get("location_id")),
-		MarketplaceVisibility: api.Bool(d.Get("marketplace_visibility") == "public"),
-		Name:                  api.String(d.Get("name")),
-		Speed:                 api.Uint64FromInt(d.Get("speed")),
-		Term:                  api.Uint64FromInt(d.Get("term")),
-		InvoiceReference:      api.String(d.Get("invoice_reference")),
-	})
+	defer cfg.invalidateProducts()
+	if v, ok := d.GetOk("restore_product_uid"); ok {
+		p, err := getProductPendingCancellation(ctx, cfg.Client.GetPort, v.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if p.LagId != 0 ||
+			p.LocationId != uint64(d.Get("location_id").(int)) ||
+			p.PortSpeed != uint64(d.Get("speed").(int)) ||
+			p.ContractTermMonths != uint64(d.Get("term").(int)) {
+			return diag.FromErr(fmt.Errorf("port %s cannot be restored, as it does not match the location_id, speed and term of the resource", p.ProductUid))
+		}
+		log.Printf("[INFO] Restoring port (%s), which is pending cancellation", p.ProductUid)
+		if err := cfg.Client.RestorePort(ctx, p.ProductUid); err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(p.ProductUid)
+		return resourceMegaportPortUpdate(ctx, d, m)
+	}
+	uid, err := cfg.Client.CreatePort(ctx, expandPortCreateInput(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.SetId(*uid)
-	if err := waitUntilPortIsConfigured(ctx, cfg.Client, *uid, 5*time.Minute); err != nil {
+	if err := waitUntilPortIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
 		return diag.FromErr(err)
 	}
+	if n := d.Get("lag_port_count").(int); n > 0 {
+		p, err := cfg.Client.GetPort(ctx, *uid)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := waitUntilLagIsConfigured(ctx, cfg.Client, p.LagId, n, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceMegaportPortRead(ctx, d, m)
 }
 
+func expandPortCreateInput(d resourceGetter) *api.PortCreateInput {
+	return &api.PortCreateInput{
+		LagPortCount:          api.Uint64FromInt(d.Get("lag_port_count")),
+		LocationId:            api.Uint64FromInt(d.Get("location_id")),
+		MarketplaceVisibility: api.Bool(d.Get("marketplace_visibility") == "public"),
+		Name:                  api.String(d.Get("name")),
+		Speed:                 api.Uint64FromInt(d.Get("speed")),
+		Term:                  api.Uint64FromInt(d.Get("term")),
+		InvoiceReference:      api.String(d.Get("invoice_reference")),
+	}
+}
+
 func resourceMegaportPortUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	if err := cfg.Client.UpdatePort(&api.PortUpdateInput{
+	defer cfg.invalidateProducts()
+	if d.HasChange("cancellation_pending") && !d.IsNewResource() {
+		if err := cfg.Client.RestorePort(ctx, d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	// Products pending cancellation cannot be updated, so only the attributes
+	// that are not sent to Megaport can change while they stay cancelled
+	if d.Get("cancellation_pending").(bool) && !d.HasChanges("invoice_reference", "name", "marketplace_visibility", "lag_port_count") {
+		return resourceMegaportPortRead(ctx, d, m)
+	}
+	if err := cfg.Client.UpdatePort(ctx, &api.PortUpdateInput{
 		InvoiceReference:      api.String(d.Get("invoice_reference")),
 		Name:                  api.String(d.Get("name")),
 		ProductUid:            api.String(d.Id()),
@@ -119,20 +245,94 @@ func resourceMegaportPortUpdate(ctx context.Context, d *schema.ResourceData, m i
 	}); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilPortIsConfigured(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilPortIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
+	if d.HasChange("lag_port_count") {
+		o, n := d.GetChange("lag_port_count")
+		lagId := uint64(d.Get("lag_id").(int))
+		if _, err := cfg.Client.CreatePort(ctx, &api.PortCreateInput{
+			LagId:                 api.Uint64(lagId),
+			LagPortCount:          api.Uint64FromInt(n.(int) - o.(int)),
+			LocationId:            api.Uint64FromInt(d.Get("location_id")),
+			MarketplaceVisibility: api.Bool(d.Get("marketplace_visibility") == "public"),
+			Name:                  api.String(d.Get("name")),
+			Speed:                 api.Uint64FromInt(d.Get("speed")),
+			Term:                  api.Uint64FromInt(d.Get("term")),
+			InvoiceReference:      api.String(d.Get("invoice_reference")),
+		}); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := waitUntilLagIsConfigured(ctx, cfg.Client, lagId, n.(int), d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceMegaportPortRead(ctx, d, m)
 }
 
 func resourceMegaportPortDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	err := cfg.Client.DeletePort(d.Id())
-	if err != nil && err != api.ErrNotFound {
+	defer cfg.invalidateProducts()
+	if d.Get("cancellation_mode") == cancellationModeEndOfTerm {
+		if d.Get("cancellation_pending").(bool) {
+			return nil
+		}
+		p, err := cfg.Client.GetPort(ctx, d.Id())
+		if err != nil {
+			if api.IsNotFound(err) {
+				return nil
+			}
+			return diag.FromErr(err)
+		}
+		if err := checkProductHasNoServices(p); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := cfg.Client.CancelPort(ctx, d.Id()); err != nil && !api.IsNotFound(err) {
+			return diag.FromErr(err)
+		}
+		// Cancelling the primary port of a LAG cancels its other ports too,
+		// which is checked so that none of them is left behind
+		for _, uid := range expandStrings(d.Get("lag_port_uids").([]interface{})) {
+			if uid == d.Id() {
+				continue
+			}
+			p, err := cfg.Client.GetPort(ctx, uid)
+			if err != nil {
+				if api.IsNotFound(err) {
+					continue
+				}
+				return diag.FromErr(err)
+			}
+			if isResourceDeleted(p.ProvisioningStatus) {
+				continue
+			}
+			log.Printf("[INFO] Cancelling port (%s) of LAG %d, which was not cancelled along with its primary port", uid, p.LagId)
+			if err := cfg.Client.CancelPort(ctx, uid); err != nil && !api.IsNotFound(err) {
+				return diag.FromErr(err)
+			}
+		}
+		log.Printf("[INFO] Port (%s) will be cancelled at the end of its term", d.Id())
+		return nil
+	}
+	err := cfg.Client.DeletePort(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
-	if err == api.ErrNotFound {
+	if api.IsNotFound(err) {
 		log.Printf("resourceMegaportPortDelete: resource not found, deleting anyway")
+		return nil
+	}
+	// Deleting a LAG cancels all of its ports
+	uids := expandStrings(d.Get("lag_port_uids").([]interface{}))
+	if len(uids) == 0 {
+		uids = []string{d.Id()}
+	}
+	// The ports are waited on in turn, within the same timeout
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutDelete))
+	for _, uid := range uids {
+		if err := waitUntilProductIsDeleted(ctx, cfg.Client.GetPort, uid, time.Until(deadline)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 	return nil
 }
@@ -141,7 +341,7 @@ func waitUntilPortIsConfigured(ctx context.Context, client *api.Client, productU
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetPort(productUid)
+			v, err := client.GetPort(ctx, productUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve Port while waiting for setup to finish: %v", err)
 				return nil, "", err
@@ -159,3 +359,36 @@ func waitUntilPortIsConfigured(ctx context.Context, client *api.Client, productU
 	_, err := scc.WaitForStateContext(ctx)
 	return err
 }
+
+// waitUntilLagIsConfigured waits until a LAG has count ports, all of which are
+// configured.
+func waitUntilLagIsConfigured(ctx context.Context, client *api.Client, lagId uint64, count int, timeout time.Duration) error {
+	scc := &resource.StateChangeConf{
+		Pending: []string{api.ProductStatusDeployable},
+		Target:  []string{api.ProductStatusConfigured},
+		Refresh: func() (interface{}, string, error) {
+			lag, err := client.GetLagPorts(ctx, lagId)
+			if err != nil {
+				log.Printf("[ERROR] Could not retrieve the ports of LAG %d while waiting for setup to finish: %v", lagId, err)
+				return nil, "", err
+			}
+			if len(lag) < count {
+				return lag, api.ProductStatusDeployable, nil
+			}
+			// The product list already carries the status of every port, so
+			// they are not retrieved one by one
+			for _, p := range lag {
+				if p.ProvisioningStatus != api.ProductStatusConfigured && p.ProvisioningStatus != api.ProductStatusLive {
+					return lag, api.ProductStatusDeployable, nil
+				}
+			}
+			return lag, api.ProductStatusConfigured, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 10 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	log.Printf("[INFO] Waiting for the %d ports of LAG %d to be configured", count, lagId)
+	_, err := scc.WaitForStateContext(ctx)
+	return err
+}
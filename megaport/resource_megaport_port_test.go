@@ -1,13 +1,21 @@
 package megaport
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/utilitywarehouse/terraform-provider-megaport/megaport/api"
 )
@@ -17,7 +25,10 @@ func init() {
 		Name: "megaport_port",
 		Dependencies: []string{
 			"megaport_aws_vxc",
+			"megaport_azure_vxc",
 			"megaport_gcp_vxc",
+			"megaport_ix",
+			"megaport_oracle_vxc",
 			"megaport_private_vxc",
 		},
 		F: func(region string) error {
@@ -26,13 +37,13 @@ func init() {
 				return fmt.Errorf("Error getting client: %s", err)
 			}
 			client := c.(*api.Client)
-			ports, err := client.ListPorts()
+			ports, err := client.ListPorts(context.Background())
 			if err != nil {
 				return err
 			}
 			for _, p := range ports {
 				if strings.HasPrefix(p.ProductName, "terraform_acctest_") && !client.IsResourceDeleted(p.ProvisioningStatus) {
-					if err := client.DeletePort(p.ProductUid); err != nil {
+					if err := client.DeletePort(context.Background(), p.ProductUid); err != nil {
 						log.Printf("[ERROR] Could not destroy port %q (%s) during sweep: %s", p.ProductName, p.ProductUid, err)
 					}
 				}
@@ -43,6 +54,7 @@ func init() {
 }
 
 func TestAccMegaportPort_basic(t *testing.T) {
+	testAccCassette(t)
 	var port, portUpdated, portNew api.Product
 	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
 	configValues := map[string]interface{}{
@@ -131,3 +143,328 @@ func TestAccMegaportPort_basic(t *testing.T) {
 		t.Errorf("TestAccMegaportPort_basic: expected the port to be recreated but the resource ids are identical")
 	}
 }
+
+func TestAccMegaportPort_lag(t *testing.T) {
+	testAccCassette(t)
+	var port, portUpdated, portNew api.Product
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	configValues := map[string]interface{}{
+		"uid":          rName,
+		"location":     "Telehouse North$",
+		"lagPortCount": 2,
+	}
+	cfg, err := newTestAccConfig("megaport_port_lag", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgUpdate, err := newTestAccConfig("megaport_port_lag", mergeMaps(configValues, map[string]interface{}{"lagPortCount": 3}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgForceNew, err := newTestAccConfig("megaport_port_lag", mergeMaps(configValues, map[string]interface{}{"lagPortCount": 1}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					resource.TestCheckResourceAttr("megaport_port.foo", "speed", "10000"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_count", "2"),
+					resource.TestCheckResourceAttrSet("megaport_port.foo", "lag_id"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_uids.#", "2"),
+					resource.TestCheckResourceAttrPair("megaport_port.foo", "lag_port_uids.0", "megaport_port.foo", "id"),
+				),
+			},
+			{
+				ResourceName:      "megaport_port.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				PreConfig: func() { cfgUpdate.log() },
+				Config:    cfgUpdate.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &portUpdated),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_count", "3"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_uids.#", "3"),
+					resource.TestCheckResourceAttrPair("megaport_port.foo", "lag_port_uids.0", "megaport_port.foo", "id"),
+				),
+			},
+			{
+				PreConfig: func() { cfgForceNew.log() },
+				Config:    cfgForceNew.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &portNew),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_count", "1"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "lag_port_uids.#", "1"),
+				),
+			},
+		},
+	})
+
+	if port.ProductUid != portUpdated.ProductUid || port.LagId != portUpdated.LagId {
+		t.Errorf("TestAccMegaportPort_lag: expected the LAG to be updated but the resource ids differ")
+	}
+	if port.ProductUid == portNew.ProductUid {
+		t.Errorf("TestAccMegaportPort_lag: expected the LAG to be recreated but the resource ids are identical")
+	}
+}
+
+func TestAccMegaportPort_cancellation(t *testing.T) {
+	testAccCassette(t)
+	var port, portRestored, portCancelled, portUncancelled api.Product
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	configValues := map[string]interface{}{
+		"uid":              rName,
+		"location":         "Telehouse North$",
+		"cancellationMode": "end_of_term",
+	}
+	cfg, err := newTestAccConfig("megaport_port_cancellation", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgDestroy, err := newTestAccConfig("megaport_port_cancellation", mergeMaps(configValues, map[string]interface{}{"cancellationMode": ""}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgRestore, err := newTestAccConfig("megaport_port_cancellation", mergeMaps(configValues, map[string]interface{}{"restore": true}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgNow, err := newTestAccConfig("megaport_port_cancellation", mergeMaps(configValues, map[string]interface{}{"cancellationMode": "now"}), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The port is restored by its id, which is only known once it has been
+	// created, so it is passed to the configuration as a variable
+	defer os.Unsetenv("TF_VAR_restore_product_uid") // nolint: errcheck
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_mode", "end_of_term"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_pending", "false"),
+				),
+			},
+			{
+				PreConfig: func() { cfgDestroy.log() },
+				Config:    cfgDestroy.Config,
+				Check: func(s *terraform.State) error {
+					return testAccCheckProductCancellationPending(port.ProductUid, true)
+				},
+			},
+			{
+				PreConfig: func() {
+					cfgRestore.log()
+					if err := os.Setenv("TF_VAR_restore_product_uid", port.ProductUid); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: cfgRestore.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &portRestored),
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_pending", "false"),
+				),
+			},
+			{
+				// Ports cancelled outside of Terraform stay cancelled
+				PreConfig: func() {
+					cfg.log()
+					if err := testAccProvider.Meta().(*Config).Client.CancelPort(context.Background(), portRestored.ProductUid); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &portCancelled),
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_pending", "true"),
+					resource.TestCheckResourceAttr("megaport_port.foo", "restore_product_uid", ""),
+				),
+			},
+			{
+				// unless they are restored explicitly
+				PreConfig: func() { cfgRestore.log() },
+				Config:    cfgRestore.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &portUncancelled),
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_pending", "false"),
+				),
+			},
+			{
+				PreConfig: func() { cfgNow.log() },
+				Config:    cfgNow.Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("megaport_port.foo", "cancellation_mode", "now"),
+				),
+			},
+		},
+	})
+
+	if port.ProductUid != portRestored.ProductUid || port.ProductUid != portUncancelled.ProductUid {
+		t.Errorf("TestAccMegaportPort_cancellation: expected the port to be restored but the resource ids differ")
+	}
+	if portRestored.ProvisioningStatus == api.ProductStatusCancelled || portUncancelled.ProvisioningStatus == api.ProductStatusCancelled {
+		t.Errorf("TestAccMegaportPort_cancellation: expected the port to be restored but it is still cancelled")
+	}
+	if !portCancelled.CancellationPending() {
+		t.Errorf("TestAccMegaportPort_cancellation: expected the port cancelled outside of Terraform to stay cancelled")
+	}
+}
+
+func TestResourceMegaportPortRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportPort(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"MEGAPORT","provisioningStatus":"LIVE","locationId":1,"portSpeed":1000,"contractTermMonths":1}}`, map[string]string{
+		"name":                   "foo",
+		"location_id":            "1",
+		"speed":                  "1000",
+		"term":                   "1",
+		"marketplace_visibility": "private",
+		"lag_port_count":         "0",
+		"cancellation_pending":   "false",
+	})
+}
+
+func TestAccMegaportPort_cancellationWithVxc(t *testing.T) {
+	testAccCassette(t)
+	var port, portBar api.Product
+	var vxcUid *string
+	rName := "t" + acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	configValues := map[string]interface{}{
+		"uid":      rName,
+		"location": "Telehouse North$",
+		"foo":      true,
+	}
+	cfg, err := newTestAccConfig("megaport_port_cancellation_vxc", configValues, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgDestroy, err := newTestAccConfig("megaport_port_cancellation_vxc", mergeMaps(configValues, map[string]interface{}{"foo": false}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { cfg.log() },
+				Config:    cfg.Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceExists("megaport_port.foo", &port),
+					testAccCheckResourceExists("megaport_port.bar", &portBar),
+				),
+			},
+			{
+				// A VXC that is not managed by terraform keeps the port
+				// from being cancelled
+				PreConfig: func() {
+					cfgDestroy.log()
+					uid, err := testAccProvider.Meta().(*Config).Client.CreatePrivateVxc(context.Background(), &api.PrivateVxcCreateInput{
+						Name:        api.String("terraform_acctest_unmanaged_" + rName),
+						ProductUidA: api.String(port.ProductUid),
+						ProductUidB: api.String(portBar.ProductUid),
+						RateLimit:   api.Uint64FromInt(100),
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					vxcUid = uid
+				},
+				Config:      cfgDestroy.Config,
+				ExpectError: regexp.MustCompile("cannot be cancelled at the end of its term while VXC"),
+			},
+			{
+				PreConfig: func() {
+					cfgDestroy.log()
+					client := testAccProvider.Meta().(*Config).Client
+					if err := client.DeleteVxc(context.Background(), *vxcUid); err != nil {
+						t.Fatal(err)
+					}
+					if err := waitUntilVxcIsDeleted(context.Background(), client, *vxcUid, 5*time.Minute); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: cfgDestroy.Config,
+				Check: func(s *terraform.State) error {
+					return testAccCheckProductCancellationPending(port.ProductUid, true)
+				},
+			},
+		},
+	})
+
+	// The port is deleted rather than left pending cancellation
+	if port.ProductUid != "" {
+		if err := testAccProvider.Meta().(*Config).Client.DeletePort(context.Background(), port.ProductUid); err != nil {
+			t.Errorf("TestAccMegaportPort_cancellationWithVxc: %v", err)
+		}
+	}
+}
+
+func TestResourceMegaportPortDelete_lagEndOfTerm(t *testing.T) {
+	terminateDate := time.Now().Add(24*time.Hour).UnixNano() / int64(time.Millisecond)
+	status := map[string]string{
+		"a": api.ProductStatusLive,
+		"b": api.ProductStatusLive,
+		"c": api.ProductStatusLive,
+		"d": api.ProductStatusDecommissioned,
+	}
+	cancelled := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uid := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/product/"), "/")[0]
+		if _, ok := status[uid]; !ok {
+			t.Errorf("TestResourceMegaportPortDelete_lagEndOfTerm: unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/product/"+uid:
+			fmt.Fprintf(w, `{"data":{"productUid":%q,"provisioningStatus":%q,"terminateDate":%d,"lagId":1}}`, uid, status[uid], terminateDate)
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/product/"+uid+"/action/CANCEL":
+			cancelled = append(cancelled, uid)
+			status[uid] = api.ProductStatusCancelled
+			// Only some of the other ports of the LAG are cancelled along
+			// with its primary port
+			if uid == "a" {
+				status["b"] = api.ProductStatusCancelled
+			}
+			fmt.Fprint(w, `{"message":"Action [CANCEL Service] has been done."}`)
+		default:
+			t.Errorf("TestResourceMegaportPortDelete_lagEndOfTerm: unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	cfg := &Config{Client: api.NewClient(s.URL)}
+	cfg.Client.MaxRetries = 0
+	r := resourceMegaportPort()
+	d := r.TestResourceData()
+	d.SetId("a")
+	if err := d.Set("cancellation_mode", cancellationModeEndOfTerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("lag_port_uids", []string{"a", "b", "c", "d"}); err != nil {
+		t.Fatal(err)
+	}
+	if diags := r.DeleteContext(context.Background(), d, cfg); diags.HasError() {
+		t.Fatalf("TestResourceMegaportPortDelete_lagEndOfTerm: unexpected diagnostics: %#v", diags)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, cancelled); diff != "" {
+		t.Errorf("TestResourceMegaportPortDelete_lagEndOfTerm: unexpected cancelled ports (-want +got):\n%s", diff)
+	}
+}
@@ -23,6 +23,16 @@ func resourceMegaportPrivateVxc() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffValidateOrder(resourceMegaportPrivateVxc, vxcOrderFields, func(ctx context.Context, c *api.Client, d *schema.ResourceDiff) error {
+			return c.ValidatePrivateVxc(ctx, expandPrivateVxcCreateInput(d))
+		}),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -36,7 +46,7 @@ func resourceMegaportPrivateVxc() *schema.Resource {
 				Type:     schema.TypeList,
 				Required: true,
 				MaxItems: 1,
-				Elem:     resourceMegaportVxcEndElem(),
+				Elem:     resourceMegaportVxcAEndElem(),
 			},
 			"b_end": {
 				Type:     schema.TypeList,
@@ -54,13 +64,17 @@ func resourceMegaportPrivateVxc() *schema.Resource {
 
 func resourceMegaportPrivateVxcRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	p, err := cfg.Client.GetVxc(d.Id())
+	p, err := cfg.Client.GetVxc(ctx, d.Id())
 	if err != nil {
-		log.Printf("resourceMegaportPrivateVxcRead: %v", err)
-		d.SetId("")
-		return nil
+		if api.IsNotFound(err) {
+			log.Printf("[WARN] VXC (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
-	if p.ProvisioningStatus == api.ProductStatusDecommissioned {
+	if isResourceDeleted(p.ProvisioningStatus) {
+		log.Printf("[WARN] VXC (%s) is %s, removing from state", d.Id(), p.ProvisioningStatus)
 		d.SetId("")
 		return nil
 	}
@@ -70,7 +84,7 @@ func resourceMegaportPrivateVxcRead(ctx context.Context, d *schema.ResourceData,
 	if err := d.Set("rate_limit", int(p.RateLimit)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("a_end", flattenVxcEnd(p.AEnd)); err != nil {
+	if err := d.Set("a_end", flattenVxcAEnd(d.Get("a_end").([]interface{}), p)); err != nil {
 		return diag.FromErr(err)
 	}
 	if err := d.Set("b_end", flattenVxcEnd(p.BEnd)); err != nil {
@@ -84,13 +98,28 @@ func resourceMegaportPrivateVxcRead(ctx context.Context, d *schema.ResourceData,
 
 func resourceMegaportPrivateVxcCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
+	input := expandPrivateVxcCreateInput(d)
+	uid, err := cfg.Client.CreatePrivateVxc(ctx, input)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*uid)
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceMegaportPrivateVxcRead(ctx, d, m)
+}
+
+func expandPrivateVxcCreateInput(d resourceGetter) *api.PrivateVxcCreateInput {
 	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
 	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
 	input := &api.PrivateVxcCreateInput{
-		ProductUidA: api.String(a["product_uid"]),
-		ProductUidB: api.String(b["product_uid"]),
-		Name:        api.String(d.Get("name")),
-		RateLimit:   api.Uint64FromInt(d.Get("rate_limit")),
+		ProductUidA:    api.String(a["product_uid"]),
+		ProductUidB:    api.String(b["product_uid"]),
+		Name:           api.String(d.Get("name")),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, false),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
 	}
 	if v, ok := d.GetOk("invoice_reference"); ok {
 		input.InvoiceReference = api.String(v)
@@ -98,28 +127,25 @@ func resourceMegaportPrivateVxcCreate(ctx context.Context, d *schema.ResourceDat
 	if v := a["vlan"].(int); v != 0 {
 		input.VlanA = api.Uint64FromInt(v)
 	}
+	if v := a["vnic_index"].(int); v != 0 {
+		input.VnicIndexA = api.Uint64FromInt(v)
+	}
 	if v := b["vlan"].(int); v != 0 {
 		input.VlanB = api.Uint64FromInt(v)
 	}
-	uid, err := cfg.Client.CreatePrivateVxc(input)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	d.SetId(*uid)
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, *uid, 5*time.Minute); err != nil {
-		return diag.FromErr(err)
-	}
-	return resourceMegaportPrivateVxcRead(ctx, d, m)
+	return input
 }
 
 func resourceMegaportPrivateVxcUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
+	defer cfg.invalidateProducts()
 	a := d.Get("a_end").([]interface{})[0].(map[string]interface{})
 	b := d.Get("b_end").([]interface{})[0].(map[string]interface{})
 	input := &api.PrivateVxcUpdateInput{
-		Name:       api.String(d.Get("name")),
-		ProductUid: api.String(d.Id()),
-		RateLimit:  api.Uint64FromInt(d.Get("rate_limit")),
+		Name:           api.String(d.Get("name")),
+		PartnerConfigA: expandVxcAEndMcrConfig(a, d.HasChange("a_end.0.mcr_config")),
+		ProductUid:     api.String(d.Id()),
+		RateLimit:      api.Uint64FromInt(d.Get("rate_limit")),
 	}
 	if v, ok := d.GetOk("invoice_reference"); ok {
 		input.InvoiceReference = api.String(v)
@@ -130,13 +156,13 @@ func resourceMegaportPrivateVxcUpdate(ctx context.Context, d *schema.ResourceDat
 	if v := b["vlan"].(int); v != 0 {
 		input.VlanB = api.Uint64FromInt(v)
 	}
-	if err := cfg.Client.UpdatePrivateVxc(input); err != nil {
+	if err := cfg.Client.UpdatePrivateVxc(ctx, input); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsConfigured(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := waitUntilPrivateVxcIsUpdated(ctx, cfg.Client, input, 5*time.Minute); err != nil {
+	if err := waitUntilPrivateVxcIsUpdated(ctx, cfg.Client, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return diag.FromErr(err)
 	}
 	return resourceMegaportPrivateVxcRead(ctx, d, m)
@@ -144,15 +170,16 @@ func resourceMegaportPrivateVxcUpdate(ctx context.Context, d *schema.ResourceDat
 
 func resourceMegaportPrivateVxcDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	cfg := m.(*Config)
-	err := cfg.Client.DeleteVxc(d.Id())
-	if err != nil && err != api.ErrNotFound {
+	defer cfg.invalidateProducts()
+	err := cfg.Client.DeleteVxc(ctx, d.Id())
+	if err != nil && !api.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
-	if err == api.ErrNotFound {
+	if api.IsNotFound(err) {
 		log.Printf("[DEBUG] VXC (%s) not found, deleting from state anyway", d.Id())
 		return nil
 	}
-	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), 5*time.Minute); err != nil {
+	if err := waitUntilVxcIsDeleted(ctx, cfg.Client, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
@@ -162,7 +189,7 @@ func waitUntilPrivateVxcIsUpdated(ctx context.Context, client *api.Client, input
 	scc := &resource.StateChangeConf{
 		Target: []string{api.ProductStatusConfigured, api.ProductStatusLive},
 		Refresh: func() (interface{}, string, error) {
-			v, err := client.GetVxc(*input.ProductUid)
+			v, err := client.GetVxc(ctx, *input.ProductUid)
 			if err != nil {
 				log.Printf("[ERROR] Could not retrieve VXC while waiting for update to finish: %v", err)
 				return nil, "", err
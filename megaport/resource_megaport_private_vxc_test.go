@@ -18,6 +18,7 @@ func init() {
 }
 
 func TestAccMegaportPrivateVxc_basic(t *testing.T) {
+	testAccCassette(t)
 	var (
 		vxc, vxcUpdated, vxcNew api.ProductAssociatedVxc
 		portA, portB            api.Product
@@ -130,3 +131,14 @@ func TestAccMegaportPrivateVxc_basic(t *testing.T) {
 		t.Errorf("TestAccMegaportPrivateVxc_basic: expected the VXC to be recreated but the resource ids are identical")
 	}
 }
+
+func TestResourceMegaportPrivateVxcRead(t *testing.T) {
+	testResourceRead(t, resourceMegaportPrivateVxc(), `{"data":{"productUid":"`+testResourceReadUid+`","productName":"foo","productType":"VXC","provisioningStatus":"LIVE","rateLimit":100,"aEnd":{"ownerUid":"a","productUid":"b","vlan":100},"bEnd":{"ownerUid":"a","productUid":"c","vlan":200}}}`, map[string]string{
+		"name":                "foo",
+		"rate_limit":          "100",
+		"a_end.0.product_uid": "b",
+		"a_end.0.vlan":        "100",
+		"b_end.0.product_uid": "c",
+		"b_end.0.vlan":        "200",
+	})
+}
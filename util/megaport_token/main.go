@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -59,16 +60,17 @@ func getToken(endpoint, username, password, totpSecret string, reset bool) (stri
 		}
 		otp = v
 	}
+	ctx := context.Background()
 	c := api.NewClient(endpoint)
 	if reset {
-		if err := c.Login(username, password, otp); err != nil {
+		if err := c.Login(ctx, username, password, otp); err != nil {
 			return "", err
 		}
-		if err := c.Logout(); err != nil {
+		if err := c.Logout(ctx); err != nil {
 			return "", err
 		}
 	}
-	if err := c.Login(username, password, otp); err != nil {
+	if err := c.Login(ctx, username, password, otp); err != nil {
 		return "", err
 	}
 	return c.Token, nil